@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"github.com/joho/godotenv"
@@ -13,6 +14,7 @@ import (
 	"github.com/saltacompra/monitor/internal/config"
 	"github.com/saltacompra/monitor/internal/scheduler"
 	"github.com/saltacompra/monitor/internal/sse"
+	"github.com/saltacompra/monitor/internal/store"
 )
 
 func main() {
@@ -31,18 +33,25 @@ func main() {
 	log.Println("[INIT] Inicializando componentes...")
 
 	// 1. Cache de sistemas
-	systemCache := cache.NewSystemCache()
+	systemCache := cache.NewSystemCache(cfg.Cache.HistorySize)
 	log.Println("[INIT] Cache inicializado")
 
 	// 2. Broadcaster SSE
 	broadcaster := sse.NewBroadcaster()
 	log.Println("[INIT] Broadcaster SSE inicializado")
 
-	// 3. Handler (con cache y broadcaster)
-	handler := api.NewHandler(cfg, systemCache, broadcaster)
+	// 3. Estado persistido (baselines, snapshots)
+	state, err := store.NewJSONStore(cfg.Storage.StateFile)
+	if err != nil {
+		log.Fatal("ERROR CRÍTICO: No se pudo abrir el archivo de estado - ", err)
+	}
+	log.Printf("[INIT] Estado persistido en %s", cfg.Storage.StateFile)
+
+	// 4. Handler (con cache, broadcaster y estado)
+	handler := api.NewHandler(cfg, systemCache, broadcaster, state)
 	log.Println("[INIT] Handler inicializado")
 
-	// 4. Background Worker (con función de checks)
+	// 5. Background Worker (con función de checks)
 	worker := scheduler.NewSmartWorker(cfg, systemCache, broadcaster, handler.CheckAllSystems)
 	worker.Start()
 	log.Printf("[INIT] Background worker iniciado (intervalo: %d min, idle timeout: %d min)",
@@ -95,14 +104,31 @@ func main() {
 		handler.RefreshAllSystems(w, r)
 	}))
 
-	http.HandleFunc("/api/systems/", corsMiddleware(func(w http.ResponseWriter, r *http.Request) {
+	systemsHandler := corsMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/history") {
+			worker.MarkActivity()
+			handler.GetSystemHistory(w, r)
+			return
+		}
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
 		worker.MarkActivity()
 		handler.RefreshSystem(w, r)
-	}))
+	})
+	http.HandleFunc("/api/systems/", func(w http.ResponseWriter, r *http.Request) {
+		// Aprobación de baselines: sin CORS (no se invoca desde navegadores de otros orígenes) y con token
+		if strings.HasSuffix(r.URL.Path, "/baseline") {
+			if r.Method != http.MethodPost {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			handler.AcceptBaseline(w, r)
+			return
+		}
+		systemsHandler(w, r)
+	})
 
 	http.HandleFunc("/api/health", corsMiddleware(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -121,6 +147,8 @@ func main() {
 	log.Printf("[SERVER]   GET  /api/events - Stream SSE de updates")
 	log.Printf("[SERVER]   POST /api/refresh - Refresh de todos los sistemas")
 	log.Printf("[SERVER]   POST /api/systems/:id/refresh - Refresh de sistema individual")
+	log.Printf("[SERVER]   GET  /api/systems/:id/history - Historial de checks de un sistema")
+	log.Printf("[SERVER]   POST /api/systems/:id/checks/:checkId/baseline - Aprobar nuevo baseline (requiere ADMIN_TOKEN)")
 
 	go func() {
 		if err := http.ListenAndServe(addr, nil); err != nil {
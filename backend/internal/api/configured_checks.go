@@ -0,0 +1,359 @@
+package api
+
+import (
+	"time"
+
+	"github.com/saltacompra/monitor/internal/config"
+	"github.com/saltacompra/monitor/internal/models"
+	"github.com/saltacompra/monitor/internal/monitors"
+)
+
+// runConfiguredChecks ejecuta los checks definidos en CHECKS_CONFIG_FILE para un sistema
+func (h *Handler) runConfiguredChecks(systemID string) []models.Check {
+	checks := []models.Check{}
+
+	// Escenarios sintéticos (login, navegación)
+	for _, scenario := range h.config.Checks.Scenarios {
+		if scenario.SystemID != systemID {
+			continue
+		}
+		checks = append(checks, monitors.CheckScenario(h.buildScenarioConfig(scenario)))
+	}
+
+	// Integridad de contenido (detección de defacement)
+	for _, integrity := range h.config.Checks.ContentIntegrity {
+		if integrity.SystemID != systemID {
+			continue
+		}
+		checks = append(checks, monitors.CheckContentIntegrity(h.buildContentIntegrityConfig(integrity), h.state))
+	}
+
+	// Contratos de API REST
+	for _, contract := range h.config.Checks.APIContracts {
+		if contract.SystemID != systemID {
+			continue
+		}
+		checks = append(checks, monitors.CheckAPIContract(h.buildAPIContractConfig(contract)))
+	}
+
+	// Consultas configuradas sobre SQL Server
+	for _, query := range h.config.Checks.MSSQLQueries {
+		if query.SystemID != systemID {
+			continue
+		}
+		checks = append(checks, monitors.CheckSQLServerQuery(h.buildMSSQLQueryConfig(query)))
+	}
+
+	// Consultas configuradas sobre PostgreSQL
+	for _, query := range h.config.Checks.PostgresQueries {
+		if query.SystemID != systemID {
+			continue
+		}
+		checks = append(checks, monitors.CheckPostgreSQLQuery(h.buildPostgresQueryConfig(query)))
+	}
+
+	// Hojas de Google Sheets alimentadas por Apps Script
+	for _, sheet := range h.config.Checks.SheetFreshness {
+		if sheet.SystemID != systemID {
+			continue
+		}
+		checks = append(checks, monitors.CheckSheetFreshness(h.buildSheetFreshnessConfig(sheet)))
+	}
+
+	return checks
+}
+
+// buildScenarioConfig convierte la configuración de un escenario al formato del monitor
+func (h *Handler) buildScenarioConfig(scenario config.ScenarioConfig) monitors.ScenarioCheckConfig {
+	baseURL := scenario.BaseURL
+	if baseURL == "" {
+		baseURL = h.systemBaseURL(scenario.SystemID)
+	}
+
+	timeout := scenario.TimeoutSeconds
+	if timeout == 0 {
+		timeout = h.config.Monitors.HTTPTimeoutSeconds
+	}
+
+	steps := make([]monitors.ScenarioStep, 0, len(scenario.Steps))
+	for _, step := range scenario.Steps {
+		extracts := make([]monitors.ScenarioExtract, 0, len(step.Extract))
+		for _, extract := range step.Extract {
+			extracts = append(extracts, monitors.ScenarioExtract{
+				Name:    extract.Name,
+				Source:  extract.Source,
+				Key:     extract.Key,
+				Pattern: extract.Pattern,
+			})
+		}
+
+		steps = append(steps, monitors.ScenarioStep{
+			Name:              step.Name,
+			Method:            step.Method,
+			URL:               step.URL,
+			Headers:           step.Headers,
+			Form:              step.Form,
+			Body:              step.Body,
+			ExpectStatus:      step.ExpectStatus,
+			ExpectContains:    step.ExpectContains,
+			ExpectNotContains: step.ExpectNotContains,
+			WarningMs:         step.WarningMs,
+			Extract:           extracts,
+		})
+	}
+
+	return monitors.ScenarioCheckConfig{
+		CheckID:             scenario.CheckID,
+		CheckName:           scenario.CheckName,
+		BaseURL:             baseURL,
+		TimeoutSeconds:      timeout,
+		SkipSSLVerification: scenario.SkipSSLVerification,
+		Variables:           scenario.Variables,
+		Steps:               steps,
+	}
+}
+
+// buildContentIntegrityConfig convierte la configuración de integridad de contenido al formato del monitor
+func (h *Handler) buildContentIntegrityConfig(integrity config.ContentIntegrityConfig) monitors.ContentIntegrityCheckConfig {
+	pageURL := integrity.URL
+	if pageURL == "" {
+		pageURL = h.systemBaseURL(integrity.SystemID)
+	}
+
+	timeout := integrity.TimeoutSeconds
+	if timeout == 0 {
+		timeout = h.config.Monitors.HTTPTimeoutSeconds
+	}
+
+	return monitors.ContentIntegrityCheckConfig{
+		URL:                 pageURL,
+		CheckID:             integrity.CheckID,
+		CheckName:           integrity.CheckName,
+		BaselineKey:         baselineKey(integrity.SystemID, integrity.CheckID),
+		IgnoreSelectors:     integrity.IgnoreSelectors,
+		IgnorePatterns:      integrity.IgnorePatterns,
+		ChangeStatus:        integrity.ChangeStatus,
+		SkipSSLVerification: integrity.SkipSSLVerification,
+		TimeoutSeconds:      timeout,
+	}
+}
+
+// buildAPIContractConfig convierte la configuración de contrato de API al formato del monitor
+func (h *Handler) buildAPIContractConfig(contract config.APIContractConfig) monitors.APIContractCheckConfig {
+	baseURL := contract.BaseURL
+	if baseURL == "" {
+		baseURL = h.systemBaseURL(contract.SystemID)
+	}
+
+	timeout := contract.TimeoutSeconds
+	if timeout == 0 {
+		timeout = h.config.Monitors.HTTPTimeoutSeconds
+	}
+
+	endpoints := make([]monitors.APIContractEndpoint, 0, len(contract.Endpoints))
+	for _, endpoint := range contract.Endpoints {
+		endpoints = append(endpoints, monitors.APIContractEndpoint{
+			Name:          endpoint.Name,
+			Method:        endpoint.Method,
+			Path:          endpoint.Path,
+			OperationPath: endpoint.OperationPath,
+			Headers:       endpoint.Headers,
+			Body:          endpoint.Body,
+			ExpectStatus:  endpoint.ExpectStatus,
+			SchemaRef:     endpoint.SchemaRef,
+		})
+	}
+
+	return monitors.APIContractCheckConfig{
+		CheckID:             contract.CheckID,
+		CheckName:           contract.CheckName,
+		BaseURL:             baseURL,
+		SchemaFile:          contract.SchemaFile,
+		ViolationStatus:     contract.ViolationStatus,
+		MaxViolations:       contract.MaxViolations,
+		SkipSSLVerification: contract.SkipSSLVerification,
+		TimeoutSeconds:      timeout,
+		Endpoints:           endpoints,
+	}
+}
+
+// buildMSSQLQueryConfig convierte la configuración de consulta SQL Server al formato del monitor
+func (h *Handler) buildMSSQLQueryConfig(query config.MSSQLQueryConfig) monitors.SQLServerQueryCheckConfig {
+	connection := h.sqlServerConnection(query.Server)
+	if query.Database != "" {
+		connection.Database = query.Database
+	}
+
+	return monitors.SQLServerQueryCheckConfig{
+		Connection:     connection,
+		CheckID:        query.CheckID,
+		CheckName:      query.CheckName,
+		Query:          query.Query,
+		TimeoutSeconds: query.TimeoutSeconds,
+		MaxRows:        query.MaxRows,
+		Thresholds:     buildQueryThresholds(query.Thresholds),
+	}
+}
+
+// buildPostgresQueryConfig convierte la configuración de consulta PostgreSQL al formato del monitor
+func (h *Handler) buildPostgresQueryConfig(query config.PostgresQueryConfig) monitors.PostgreSQLQueryCheckConfig {
+	connection := monitors.PostgreSQLConnection{
+		Host:     h.config.PostgreSQLAppSPC.Host,
+		Port:     h.config.PostgreSQLAppSPC.Port,
+		User:     h.config.PostgreSQLAppSPC.User,
+		Password: h.config.PostgreSQLAppSPC.Password,
+		Database: h.config.PostgreSQLAppSPC.Database,
+	}
+	if query.Database != "" {
+		connection.Database = query.Database
+	}
+
+	vpnCheck := true
+	if query.VPNCheck != nil {
+		vpnCheck = *query.VPNCheck
+	}
+
+	return monitors.PostgreSQLQueryCheckConfig{
+		Connection:     connection,
+		CheckID:        query.CheckID,
+		CheckName:      query.CheckName,
+		Query:          query.Query,
+		Params:         query.Params,
+		TimeoutSeconds: query.TimeoutSeconds,
+		MaxRows:        query.MaxRows,
+		VPNCheck:       vpnCheck,
+		VPNCheckHost:   h.config.VPNCheck.Host,
+		VPNTimeoutMs:   h.config.VPNCheck.TimeoutMs,
+		Thresholds:     buildQueryThresholds(query.Thresholds),
+	}
+}
+
+// buildSheetFreshnessConfig convierte la configuración de una hoja al formato del monitor
+// Usa la autenticación de Google Sheets configurada por variables de entorno
+func (h *Handler) buildSheetFreshnessConfig(sheet config.SheetFreshnessConfig) monitors.SheetFreshnessCheckConfig {
+	filenameColumn := -1
+	if sheet.FilenameColumn != nil {
+		filenameColumn = *sheet.FilenameColumn
+	}
+	headerRows := 1
+	if sheet.HeaderRows != nil {
+		headerRows = *sheet.HeaderRows
+	}
+	tailRows := h.config.GoogleSheets.TailRows
+	if sheet.TailRows != nil {
+		tailRows = *sheet.TailRows
+	}
+
+	return monitors.SheetFreshnessCheckConfig{
+		SpreadsheetID:      sheet.SpreadsheetID,
+		SheetName:          sheet.SheetName,
+		AuthMethod:         h.config.GoogleSheets.AuthMethod,
+		CredentialsFile:    h.config.GoogleSheets.CredentialsFile,
+		APIKey:             h.config.GoogleSheets.APIKey,
+		Endpoint:           h.config.GoogleSheets.Endpoint,
+		CheckID:            sheet.CheckID,
+		CheckName:          sheet.CheckName,
+		TimestampColumn:    sheet.TimestampColumn,
+		FilenameColumn:     filenameColumn,
+		HeaderRows:         headerRows,
+		TimestampLayouts:   sheet.TimestampLayouts,
+		TimeZone:           sheet.TimeZone,
+		FilenamePattern:    sheet.FilenamePattern,
+		FilenameDateLayout: sheet.FilenameDateLayout,
+		ExpectedFilename:   sheet.ExpectedFilename,
+		LatestRow:          sheet.LatestRow,
+		WarningDays:        sheet.WarningDays,
+		ErrorDays:          sheet.ErrorDays,
+		History:            buildSheetHistory(sheet.History),
+		TailRows:           tailRows,
+		TimeoutSeconds:     sheet.TimeoutSeconds,
+	}
+}
+
+// buildSheetHistory convierte la configuración del análisis de historial al formato del monitor
+func buildSheetHistory(history config.SheetHistoryConfig) monitors.SheetHistoryConfig {
+	businessDays := make([]time.Weekday, 0, len(history.BusinessDays))
+	for _, day := range history.BusinessDays {
+		businessDays = append(businessDays, time.Weekday(((day%7)+7)%7))
+	}
+
+	return monitors.SheetHistoryConfig{
+		Days:                       history.Days,
+		BusinessDays:               businessDays,
+		Holidays:                   history.Holidays,
+		TimeShiftMinutes:           history.TimeShiftMinutes,
+		CompletenessWarningPercent: history.CompletenessWarningPercent,
+		CompletenessErrorPercent:   history.CompletenessErrorPercent,
+	}
+}
+
+// mailCategories retorna los tipos de correo configurados para un sistema
+func (h *Handler) mailCategories(systemID string) []monitors.MailCategory {
+	var categories []monitors.MailCategory
+	for _, category := range h.config.Checks.MailCategories {
+		if category.SystemID != "" && category.SystemID != systemID {
+			continue
+		}
+		categories = append(categories, monitors.MailCategory{
+			Name:                  category.Name,
+			SubjectLike:           category.SubjectLike,
+			RecipientLike:         category.RecipientLike,
+			WarningFailedPercent:  category.WarningFailedPercent,
+			ErrorFailedPercent:    category.ErrorFailedPercent,
+			MaxMinutesWithoutSent: category.MaxMinutesWithoutSent,
+		})
+	}
+	return categories
+}
+
+// sqlServerConnection retorna los datos de conexión del servidor SQL Server indicado ("prod" o "preprod")
+func (h *Handler) sqlServerConnection(server string) monitors.SQLServerConnection {
+	db := h.config.DatabaseProd
+	if server == "preprod" {
+		db = h.config.DatabasePreProd
+	}
+
+	return monitors.SQLServerConnection{
+		Host:     db.Host,
+		Port:     db.Port,
+		User:     db.User,
+		Password: db.Password,
+		Database: db.Database,
+	}
+}
+
+// buildQueryThresholds convierte los umbrales de consulta al formato del monitor
+func buildQueryThresholds(thresholds config.QueryThresholdsConfig) monitors.QueryThresholds {
+	return monitors.QueryThresholds{
+		Column:         thresholds.Column,
+		WarningMin:     thresholds.WarningMin,
+		WarningMax:     thresholds.WarningMax,
+		ErrorMin:       thresholds.ErrorMin,
+		ErrorMax:       thresholds.ErrorMax,
+		Expected:       thresholds.Expected,
+		MinRows:        thresholds.MinRows,
+		WarningMaxRows: thresholds.WarningMaxRows,
+		ErrorMaxRows:   thresholds.ErrorMaxRows,
+		WarningRule:    thresholds.WarningRule,
+		ErrorRule:      thresholds.ErrorRule,
+	}
+}
+
+// baselineKey identifica el baseline de un check en el StateStore (integridad de contenido, registro RDAP)
+func baselineKey(systemID string, checkID string) string {
+	return systemID + "/" + checkID
+}
+
+// systemBaseURL retorna la URL pública de un sistema web (vacío si no aplica)
+func (h *Handler) systemBaseURL(systemID string) string {
+	switch systemID {
+	case "saltacompra-prod":
+		return h.config.SaltaCompra.ProdURL
+	case "saltacompra-preprod":
+		return h.config.SaltaCompra.PreProdURL
+	case "app-saltacompra":
+		return h.config.AppSaltaCompra.URL
+	default:
+		return ""
+	}
+}
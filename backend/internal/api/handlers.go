@@ -1,6 +1,7 @@
 package api
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -14,21 +15,27 @@ import (
 	"github.com/saltacompra/monitor/internal/models"
 	"github.com/saltacompra/monitor/internal/monitors"
 	"github.com/saltacompra/monitor/internal/sse"
+	"github.com/saltacompra/monitor/internal/store"
 )
 
 // Handler maneja las peticiones HTTP
 type Handler struct {
-	config      config.Config
-	cache       *cache.SystemCache
-	broadcaster *sse.Broadcaster
+	config       config.Config
+	cache        *cache.SystemCache
+	broadcaster  *sse.Broadcaster
+	state        *store.JSONStore
+	lastCrawl    models.Check // Último resultado del crawler (se reutiliza dentro del intervalo)
+	crawlRunning bool         // Hay un recorrido en curso en segundo plano
+	crawlMu      sync.Mutex
 }
 
 // NewHandler crea un nuevo handler
-func NewHandler(cfg config.Config, cache *cache.SystemCache, broadcaster *sse.Broadcaster) *Handler {
+func NewHandler(cfg config.Config, cache *cache.SystemCache, broadcaster *sse.Broadcaster, state *store.JSONStore) *Handler {
 	return &Handler{
 		config:      cfg,
 		cache:       cache,
 		broadcaster: broadcaster,
+		state:       state,
 	}
 }
 
@@ -98,6 +105,121 @@ func (h *Handler) RefreshAllSystems(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// GetSystemHistory devuelve el historial de resultados de los checks de un sistema
+func (h *Handler) GetSystemHistory(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/systems/"), "/")
+	if len(parts) == 0 || parts[0] == "" {
+		http.Error(w, "ID de sistema no especificado", http.StatusBadRequest)
+		return
+	}
+	systemID := parts[0]
+
+	response := map[string]interface{}{
+		"system_id": systemID,
+		"checks":    h.cache.GetHistory(systemID),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// authorizeAdmin verifica el token de administración (Authorization: Bearer <ADMIN_TOKEN>)
+// Si ADMIN_TOKEN no está configurado los endpoints administrativos quedan deshabilitados
+func (h *Handler) authorizeAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if h.config.Server.AdminToken == "" {
+		http.Error(w, "Endpoint deshabilitado: ADMIN_TOKEN no configurado", http.StatusForbidden)
+		return false
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.config.Server.AdminToken)) != 1 {
+		http.Error(w, "Token de administración inválido", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// AcceptBaseline aprueba lo último observado como baseline de un check
+// (POST /api/systems/:id/checks/:checkId/baseline): el contenido en los checks de integridad
+// de contenido y los datos de registro en los checks RDAP de dominio
+// Requiere el token de administración: aprobar un baseline silencia la alerta de cambios
+func (h *Handler) AcceptBaseline(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/systems/"), "/")
+	if len(parts) < 4 || parts[0] == "" || parts[1] != "checks" || parts[2] == "" {
+		http.Error(w, "Ruta esperada: /api/systems/:id/checks/:checkId/baseline", http.StatusBadRequest)
+		return
+	}
+	systemID, checkID := parts[0], parts[2]
+
+	// El tipo del check (según el último resultado) determina qué baseline se aprueba
+	checkType, found := h.cachedCheckType(systemID, checkID)
+	if !found {
+		http.Error(w, "No hay resultados del check "+checkID+" en el sistema "+systemID, http.StatusNotFound)
+		return
+	}
+
+	switch checkType {
+	case "rdap":
+		registration, err := monitors.AcceptRDAPRegistration(h.state, baselineKey(systemID, checkID))
+		if err != nil {
+			http.Error(w, "No se pudo aprobar el baseline: "+err.Error(), http.StatusNotFound)
+			return
+		}
+
+		log.Printf("[API] Registro RDAP aprobado: %s/%s (registrar %q, nameservers %v)",
+			systemID, checkID, registration.Registrar, registration.Nameservers)
+
+		writeBaselineResponse(w, map[string]interface{}{
+			"message":      "Baseline aprobado",
+			"system_id":    systemID,
+			"check_id":     checkID,
+			"registration": registration,
+			"captured_at":  registration.CapturedAt,
+		})
+	case "content-integrity":
+		snapshot, err := monitors.AcceptContentBaseline(h.state, baselineKey(systemID, checkID))
+		if err != nil {
+			http.Error(w, "No se pudo aprobar el baseline: "+err.Error(), http.StatusNotFound)
+			return
+		}
+
+		log.Printf("[API] Baseline aprobado: %s/%s (hash %s)", systemID, checkID, snapshot.Hash)
+
+		writeBaselineResponse(w, map[string]interface{}{
+			"message":     "Baseline aprobado",
+			"system_id":   systemID,
+			"check_id":    checkID,
+			"hash":        snapshot.Hash,
+			"captured_at": snapshot.CapturedAt,
+		})
+	default:
+		http.Error(w, fmt.Sprintf("El check %s (%s) no tiene baseline", checkID, checkType), http.StatusBadRequest)
+	}
+}
+
+// writeBaselineResponse responde con el resultado de la aprobación de un baseline
+func writeBaselineResponse(w http.ResponseWriter, response map[string]interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// cachedCheckType retorna el tipo de un check según el último resultado del sistema en caché
+func (h *Handler) cachedCheckType(systemID string, checkID string) (string, bool) {
+	system, exists := h.cache.Get(systemID)
+	if !exists {
+		return "", false
+	}
+	for _, check := range system.Checks {
+		if check.ID == checkID {
+			return check.Type, true
+		}
+	}
+	return "", false
+}
+
 // RefreshSystem dispara la ejecución de check de un sistema específico (async)
 func (h *Handler) RefreshSystem(w http.ResponseWriter, r *http.Request) {
 	// Extraer ID del sistema de la URL
@@ -290,10 +412,28 @@ func (h *Handler) checkSaltaCompraProd() models.System {
 		SSLWarningDays:   h.config.Monitors.SSLWarningDays,
 		TimeoutWarningMs: h.config.Monitors.HTTPTimeoutWarningMs,
 		TimeoutErrorMs:   h.config.Monitors.HTTPTimeoutErrorMs,
+		TTFBWarningMs:    h.config.Monitors.HTTPTTFBWarningMs,
+		TTFBErrorMs:      h.config.Monitors.HTTPTTFBErrorMs,
 		TimeoutSeconds:   h.config.Monitors.HTTPTimeoutSeconds,
 	})
 	system.Checks = append(system.Checks, httpCheck)
 
+	// Check de cabeceras de seguridad y HTTPS
+	securityCheck := monitors.CheckWebSecurity(monitors.WebSecurityCheckConfig{
+		URL:            h.config.SaltaCompra.ProdURL,
+		CheckID:        "web-security",
+		CheckName:      "Seguridad web (cabeceras y HTTPS)",
+		TimeoutSeconds: h.config.Monitors.HTTPTimeoutSeconds,
+		WarningScore:   h.config.Monitors.WebSecurityWarningScore,
+		ErrorScore:     h.config.Monitors.WebSecurityErrorScore,
+	})
+	system.Checks = append(system.Checks, securityCheck)
+
+	// Check de enlaces rotos (crawler con rate limit)
+	if h.config.Crawl.Enabled {
+		system.Checks = append(system.Checks, h.runCrawlCheck())
+	}
+
 	// Check servicio de mails
 	mailConfig := monitors.MailCheckConfig{
 		Host:                      h.config.DatabaseProd.Host,
@@ -304,10 +444,30 @@ func (h *Handler) checkSaltaCompraProd() models.System {
 		MaxMinutesWithoutSent:     h.config.Monitors.MailMaxMinutesWithoutSent,
 		DailyWarningFailedPercent: h.config.Monitors.MailDailyWarningFailedPercent,
 		DailyErrorFailedPercent:   h.config.Monitors.MailDailyErrorFailedPercent,
+		Categories:                h.mailCategories(system.ID),
+		WindowsMinutes:            h.config.Monitors.MailWindowsMinutes,
+		StuckWarningMinutes:       h.config.Monitors.MailStuckWarningMinutes,
+		StuckErrorMinutes:         h.config.Monitors.MailStuckErrorMinutes,
+		MinFailureSamples:         h.config.Monitors.MailMinFailureSamples,
 	}
 	mailCheck := monitors.CheckMailService(mailConfig, "mail-service", "Servicio de correos")
 	system.Checks = append(system.Checks, mailCheck)
 
+	// Check de jobs del SQL Server Agent
+	agentCheck := monitors.CheckSQLServerAgentJobs(h.buildAgentJobsConfig("prod"))
+	system.Checks = append(system.Checks, agentCheck)
+
+	// Check de antigüedad de backups
+	backupCheck := monitors.CheckSQLServerBackups(h.buildBackupConfig("prod"))
+	system.Checks = append(system.Checks, backupCheck)
+
+	// Check de salud de la instancia SQL Server
+	mssqlHealthCheck := monitors.CheckSQLServerHealth(h.buildMSSQLHealthConfig("prod"))
+	system.Checks = append(system.Checks, mssqlHealthCheck)
+
+	// Checks adicionales definidos en CHECKS_CONFIG_FILE
+	system.Checks = append(system.Checks, h.runConfiguredChecks(system.ID)...)
+
 	// Determinar estado general del sistema
 	system.Status = determineSystemStatus(system.Checks)
 	if len(system.Checks) > 0 {
@@ -338,10 +498,23 @@ func (h *Handler) checkSaltaCompraPreProd() models.System {
 		SSLWarningDays:   h.config.Monitors.SSLWarningDays,
 		TimeoutWarningMs: h.config.Monitors.HTTPTimeoutWarningMs,
 		TimeoutErrorMs:   h.config.Monitors.HTTPTimeoutErrorMs,
+		TTFBWarningMs:    h.config.Monitors.HTTPTTFBWarningMs,
+		TTFBErrorMs:      h.config.Monitors.HTTPTTFBErrorMs,
 		TimeoutSeconds:   h.config.Monitors.HTTPTimeoutSeconds,
 	})
 	system.Checks = append(system.Checks, httpCheck)
 
+	// Check de cabeceras de seguridad y HTTPS
+	securityCheck := monitors.CheckWebSecurity(monitors.WebSecurityCheckConfig{
+		URL:            h.config.SaltaCompra.PreProdURL,
+		CheckID:        "web-security",
+		CheckName:      "Seguridad web (cabeceras y HTTPS)",
+		TimeoutSeconds: h.config.Monitors.HTTPTimeoutSeconds,
+		WarningScore:   h.config.Monitors.WebSecurityWarningScore,
+		ErrorScore:     h.config.Monitors.WebSecurityErrorScore,
+	})
+	system.Checks = append(system.Checks, securityCheck)
+
 	// Check servicio de mails
 	mailConfig := monitors.MailCheckConfig{
 		Host:                      h.config.DatabasePreProd.Host,
@@ -352,10 +525,41 @@ func (h *Handler) checkSaltaCompraPreProd() models.System {
 		MaxMinutesWithoutSent:     h.config.Monitors.MailMaxMinutesWithoutSent,
 		DailyWarningFailedPercent: h.config.Monitors.MailDailyWarningFailedPercent,
 		DailyErrorFailedPercent:   h.config.Monitors.MailDailyErrorFailedPercent,
+		Categories:                h.mailCategories(system.ID),
+		WindowsMinutes:            h.config.Monitors.MailWindowsMinutes,
+		StuckWarningMinutes:       h.config.Monitors.MailStuckWarningMinutes,
+		StuckErrorMinutes:         h.config.Monitors.MailStuckErrorMinutes,
+		MinFailureSamples:         h.config.Monitors.MailMinFailureSamples,
 	}
 	mailCheck := monitors.CheckMailService(mailConfig, "mail-service", "Servicio de correos")
 	system.Checks = append(system.Checks, mailCheck)
 
+	// Check de destinatarios permitidos: preproducción no debe escribir a proveedores reales
+	guardCheck := monitors.CheckMailRecipientGuard(monitors.MailRecipientGuardConfig{
+		Connection:       h.sqlServerConnection("preprod"),
+		CheckID:          "mail-recipient-guard",
+		CheckName:        "Destinatarios de correos de preproducción",
+		AllowedDomains:   h.config.PreProdMailGuard.AllowedDomains,
+		AllowedAddresses: h.config.PreProdMailGuard.AllowedAddresses,
+		LookbackHours:    h.config.PreProdMailGuard.LookbackHours,
+	})
+	system.Checks = append(system.Checks, guardCheck)
+
+	// Check de jobs del SQL Server Agent
+	agentCheck := monitors.CheckSQLServerAgentJobs(h.buildAgentJobsConfig("preprod"))
+	system.Checks = append(system.Checks, agentCheck)
+
+	// Check de antigüedad de backups
+	backupCheck := monitors.CheckSQLServerBackups(h.buildBackupConfig("preprod"))
+	system.Checks = append(system.Checks, backupCheck)
+
+	// Check de salud de la instancia SQL Server
+	mssqlHealthCheck := monitors.CheckSQLServerHealth(h.buildMSSQLHealthConfig("preprod"))
+	system.Checks = append(system.Checks, mssqlHealthCheck)
+
+	// Checks adicionales definidos en CHECKS_CONFIG_FILE
+	system.Checks = append(system.Checks, h.runConfiguredChecks(system.ID)...)
+
 	// Determinar estado general
 	system.Status = determineSystemStatus(system.Checks)
 	if len(system.Checks) > 0 {
@@ -376,16 +580,38 @@ func (h *Handler) checkInfrastructure() models.System {
 		Checks:      []models.Check{},
 	}
 
-	// Check RDAP para expiración de dominio
-	rdapCheck := monitors.CheckRDAPDomain(monitors.RDAPCheckConfig{
-		Domain:      h.config.Infrastructure.Domain,
-		RDAPBaseURL: h.config.Infrastructure.RDAPBaseURL,
-		CheckID:     "domain-expiry",
-		CheckName:   "Expiración de dominio",
-		WarningDays: h.config.Monitors.DomainWarningDays,
-		ErrorDays:   h.config.Monitors.DomainErrorDays,
-	})
-	system.Checks = append(system.Checks, rdapCheck)
+	// Checks RDAP para expiración de dominio (uno por dominio)
+	infrastructure := h.config.Infrastructure
+	for _, domain := range infrastructure.Domains {
+		checkID, checkName := "domain-expiry", "Expiración de dominio"
+		if len(infrastructure.Domains) > 1 {
+			checkID = "domain-expiry-" + strings.ReplaceAll(domain, ".", "-")
+			checkName = fmt.Sprintf("%s %s", checkName, domain)
+		}
+		rdapCheck := monitors.CheckRDAPDomain(monitors.RDAPCheckConfig{
+			Domain:      domain,
+			RDAPBaseURL: infrastructure.RDAPBaseURL,
+			Bootstrap: monitors.RDAPBootstrapConfig{
+				File:         infrastructure.RDAPBootstrapFile,
+				URL:          infrastructure.RDAPBootstrapURL,
+				RefreshHours: infrastructure.RDAPBootstrapRefreshHours,
+				Overrides:    infrastructure.RDAPBaseURLs,
+			},
+			BaselineKey: baselineKey(system.ID, checkID),
+			WHOIS: monitors.WHOISConfig{
+				Enabled: infrastructure.WHOISFallback,
+				Server:  infrastructure.WHOISServer,
+			},
+			CheckID:     checkID,
+			CheckName:   checkName,
+			WarningDays: h.config.Monitors.DomainWarningDays,
+			ErrorDays:   h.config.Monitors.DomainErrorDays,
+		}, h.state)
+		system.Checks = append(system.Checks, rdapCheck)
+	}
+
+	// Checks adicionales definidos en CHECKS_CONFIG_FILE
+	system.Checks = append(system.Checks, h.runConfiguredChecks(system.ID)...)
 
 	// Determinar estado general
 	system.Status = determineSystemStatus(system.Checks)
@@ -409,20 +635,76 @@ func (h *Handler) checkGoogleSheetsKairos() models.System {
 
 	// Check de actualización diaria
 	kairosCheck := monitors.CheckGoogleSheetsKairos(monitors.GoogleSheetsCheckConfig{
-		SpreadsheetID:   h.config.GoogleSheets.SpreadsheetID,
-		SheetName:       h.config.GoogleSheets.SheetName,
-		AuthMethod:      h.config.GoogleSheets.AuthMethod,
-		CredentialsFile: h.config.GoogleSheets.CredentialsFile,
-		APIKey:          h.config.GoogleSheets.APIKey,
-		TimestampColumn: h.config.GoogleSheets.TimestampColumn,
-		FilenameColumn:  h.config.GoogleSheets.FilenameColumn,
-		WarningDays:     h.config.GoogleSheets.WarningDays,
-		ErrorDays:       h.config.GoogleSheets.ErrorDays,
-		CheckID:         "kairos-daily-update",
-		CheckName:       "Actualización diaria Kairos",
+		SpreadsheetID:    h.config.GoogleSheets.SpreadsheetID,
+		SheetName:        h.config.GoogleSheets.SheetName,
+		AuthMethod:       h.config.GoogleSheets.AuthMethod,
+		CredentialsFile:  h.config.GoogleSheets.CredentialsFile,
+		APIKey:           h.config.GoogleSheets.APIKey,
+		Endpoint:         h.config.GoogleSheets.Endpoint,
+		TailRows:         h.config.GoogleSheets.TailRows,
+		TimestampColumn:  h.config.GoogleSheets.TimestampColumn,
+		FilenameColumn:   h.config.GoogleSheets.FilenameColumn,
+		WarningDays:      h.config.GoogleSheets.WarningDays,
+		ErrorDays:        h.config.GoogleSheets.ErrorDays,
+		TimestampLayouts: h.config.GoogleSheets.TimestampLayouts,
+		TimeZone:         h.config.GoogleSheets.TimeZone,
+		LatestRow:        h.config.GoogleSheets.LatestRow,
+		History: buildSheetHistory(config.SheetHistoryConfig{
+			Days:                       h.config.GoogleSheets.HistoryDays,
+			BusinessDays:               h.config.GoogleSheets.BusinessDays,
+			Holidays:                   h.config.GoogleSheets.Holidays,
+			TimeShiftMinutes:           h.config.GoogleSheets.TimeShiftMinutes,
+			CompletenessWarningPercent: h.config.GoogleSheets.CompletenessWarningPercent,
+			CompletenessErrorPercent:   h.config.GoogleSheets.CompletenessErrorPercent,
+		}),
+		CheckID:   "kairos-daily-update",
+		CheckName: "Actualización diaria Kairos",
 	})
 	system.Checks = append(system.Checks, kairosCheck)
 
+	// Check de existencia y tamaño del archivo generado en Google Drive
+	// Si la hoja informa un nombre de archivo no reconocido no hay archivo que buscar:
+	// el problema ya lo reporta el check de la hoja
+	if h.config.GoogleDrive.FolderID != "" {
+		driveConfig := h.buildKairosDriveConfig(kairosCheck)
+		if recognized, ok := kairosCheck.Metadata["filename_recognized"].(bool); ok && !recognized {
+			system.Checks = append(system.Checks, models.Check{
+				ID:        driveConfig.CheckID,
+				Type:      "google-drive",
+				Name:      driveConfig.CheckName,
+				Status:    "unknown",
+				Message:   "Sin verificar: la hoja de Kairos informa un nombre de archivo no reconocido",
+				LastCheck: time.Now(),
+				Metadata:  map[string]interface{}{"last_filename": kairosCheck.Metadata["last_filename"]},
+			})
+		} else {
+			system.Checks = append(system.Checks, monitors.CheckGoogleDriveFile(driveConfig))
+		}
+	}
+
+	// Checks de ejecuciones del Apps Script que genera las actualizaciones
+	for i, scriptID := range h.config.AppsScript.ScriptIDs {
+		checkID, checkName := "kairos-apps-script", "Ejecuciones Apps Script Kairos"
+		if len(h.config.AppsScript.ScriptIDs) > 1 {
+			checkID = fmt.Sprintf("%s-%d", checkID, i+1)
+			checkName = fmt.Sprintf("%s (%d)", checkName, i+1)
+		}
+		system.Checks = append(system.Checks, monitors.CheckAppsScriptExecutions(monitors.AppsScriptCheckConfig{
+			Auth: monitors.GoogleAuthConfig{
+				CredentialsFile: h.config.AppsScript.CredentialsFile,
+				Endpoint:        h.config.AppsScript.Endpoint,
+			},
+			CheckID:              checkID,
+			CheckName:            checkName,
+			ScriptID:             scriptID,
+			LookbackHours:        h.config.AppsScript.LookbackHours,
+			MaxHoursSinceSuccess: h.config.AppsScript.MaxHoursSinceSuccess,
+		}))
+	}
+
+	// Checks adicionales definidos en CHECKS_CONFIG_FILE
+	system.Checks = append(system.Checks, h.runConfiguredChecks(system.ID)...)
+
 	// Determinar estado general
 	system.Status = determineSystemStatus(system.Checks)
 	if len(system.Checks) > 0 {
@@ -445,16 +727,18 @@ func (h *Handler) checkAppSaltaCompra() models.System {
 
 	// Check HTTP (público)
 	httpCheck := monitors.CheckHTTP(monitors.HTTPCheckConfig{
-		URL:                  h.config.AppSaltaCompra.URL,
-		CheckID:              "http-check",
-		CheckName:            "Sitio web accesible",
-		ExpectedContent:      []string{h.config.AppSaltaCompra.ExpectedContent},
-		ValidateSSL:          true,
-		SkipSSLVerification:  h.config.AppSaltaCompra.SkipSSLVerification,
-		SSLWarningDays:       h.config.Monitors.SSLWarningDays,
-		TimeoutWarningMs:     h.config.Monitors.HTTPTimeoutWarningMs,
-		TimeoutErrorMs:       h.config.Monitors.HTTPTimeoutErrorMs,
-		TimeoutSeconds:       h.config.Monitors.HTTPTimeoutSeconds,
+		URL:                 h.config.AppSaltaCompra.URL,
+		CheckID:             "http-check",
+		CheckName:           "Sitio web accesible",
+		ExpectedContent:     []string{h.config.AppSaltaCompra.ExpectedContent},
+		ValidateSSL:         true,
+		SkipSSLVerification: h.config.AppSaltaCompra.SkipSSLVerification,
+		SSLWarningDays:      h.config.Monitors.SSLWarningDays,
+		TimeoutWarningMs:    h.config.Monitors.HTTPTimeoutWarningMs,
+		TimeoutErrorMs:      h.config.Monitors.HTTPTimeoutErrorMs,
+		TTFBWarningMs:       h.config.Monitors.HTTPTTFBWarningMs,
+		TTFBErrorMs:         h.config.Monitors.HTTPTTFBErrorMs,
+		TimeoutSeconds:      h.config.Monitors.HTTPTimeoutSeconds,
 	})
 	system.Checks = append(system.Checks, httpCheck)
 
@@ -472,6 +756,13 @@ func (h *Handler) checkAppSaltaCompra() models.System {
 	})
 	system.Checks = append(system.Checks, pgCheck)
 
+	// Check de salud del servidor PostgreSQL (conexiones, locks, replicación, vacuum)
+	pgHealthCheck := monitors.CheckPostgreSQLHealth(h.buildPostgreSQLHealthConfig(), h.state)
+	system.Checks = append(system.Checks, pgHealthCheck)
+
+	// Checks adicionales definidos en CHECKS_CONFIG_FILE
+	system.Checks = append(system.Checks, h.runConfiguredChecks(system.ID)...)
+
 	// Determinar estado general
 	system.Status = determineSystemStatus(system.Checks)
 	if len(system.Checks) > 0 {
@@ -481,6 +772,187 @@ func (h *Handler) checkAppSaltaCompra() models.System {
 	return system
 }
 
+// buildPostgreSQLHealthConfig arma la configuración del check de salud de PostgreSQL de App.SaltaCompra
+func (h *Handler) buildPostgreSQLHealthConfig() monitors.PostgreSQLHealthCheckConfig {
+	health := h.config.PostgreSQLHealth
+	threshold := func(warning int, errorValue int) monitors.MetricThreshold {
+		return monitors.MetricThreshold{Warning: float64(warning), Error: float64(errorValue)}
+	}
+
+	return monitors.PostgreSQLHealthCheckConfig{
+		Connection: monitors.PostgreSQLConnection{
+			Host:     h.config.PostgreSQLAppSPC.Host,
+			Port:     h.config.PostgreSQLAppSPC.Port,
+			User:     h.config.PostgreSQLAppSPC.User,
+			Password: h.config.PostgreSQLAppSPC.Password,
+			Database: h.config.PostgreSQLAppSPC.Database,
+		},
+		CheckID:                  "postgresql-health",
+		CheckName:                "Salud del servidor PostgreSQL",
+		VPNCheckHost:             h.config.VPNCheck.Host,
+		VPNTimeoutMs:             h.config.VPNCheck.TimeoutMs,
+		SizeSnapshotKey:          "postgres-size/app-saltacompra",
+		LongQuerySeconds:         health.LongQuerySeconds,
+		IdleTxSeconds:            health.IdleTxSeconds,
+		StaleVacuumHours:         health.StaleVacuumHours,
+		StaleVacuumMinDeadTuples: health.StaleVacuumMinDeadTuples,
+		Thresholds: monitors.PostgreSQLHealthThresholds{
+			ConnectionsPercent: threshold(health.ConnectionsWarningPercent, health.ConnectionsErrorPercent),
+			LongRunningQueries: threshold(health.LongQueriesWarning, health.LongQueriesError),
+			IdleInTransaction:  threshold(health.IdleTxWarning, health.IdleTxError),
+			BlockedSessions:    threshold(health.BlockedWarning, health.BlockedError),
+			ReplicationLagSecs: threshold(health.ReplicationLagWarningSecs, health.ReplicationLagErrorSecs),
+			GrowthMBPerDay:     threshold(health.GrowthWarningMBPerDay, health.GrowthErrorMBPerDay),
+			XIDWraparoundPct:   threshold(health.XIDWarningPercent, health.XIDErrorPercent),
+			StaleVacuumTables:  threshold(health.StaleVacuumWarning, health.StaleVacuumError),
+		},
+	}
+}
+
+// buildAgentJobsConfig arma la configuración del check de jobs del SQL Server Agent ("prod" o "preprod")
+func (h *Handler) buildAgentJobsConfig(server string) monitors.SQLServerAgentCheckConfig {
+	return monitors.SQLServerAgentCheckConfig{
+		Connection:            h.sqlServerConnection(server),
+		CheckID:               "agent-jobs",
+		CheckName:             "Jobs del SQL Server Agent",
+		LongRunningFactor:     h.config.AgentJobs.LongRunningFactor,
+		MinLongRunningMinutes: h.config.AgentJobs.MinLongRunningMinutes,
+		ScheduleGraceMinutes:  h.config.AgentJobs.ScheduleGraceMinutes,
+	}
+}
+
+// buildBackupConfig arma la configuración del check de antigüedad de backups ("prod" o "preprod")
+func (h *Handler) buildBackupConfig(server string) monitors.SQLServerBackupCheckConfig {
+	backups := h.config.Backups
+	return monitors.SQLServerBackupCheckConfig{
+		Connection:       h.sqlServerConnection(server),
+		CheckID:          "backups",
+		CheckName:        "Backups de bases de datos",
+		FullAgeHours:     monitors.MetricThreshold{Warning: float64(backups.FullWarningHours), Error: float64(backups.FullErrorHours)},
+		DiffAgeHours:     monitors.MetricThreshold{Warning: float64(backups.DiffWarningHours), Error: float64(backups.DiffErrorHours)},
+		LogAgeMinutes:    monitors.MetricThreshold{Warning: float64(backups.LogWarningMinutes), Error: float64(backups.LogErrorMinutes)},
+		ExcludeDatabases: backups.ExcludeDatabases,
+	}
+}
+
+// buildMSSQLHealthConfig arma la configuración del check de salud de SQL Server ("prod" o "preprod")
+func (h *Handler) buildMSSQLHealthConfig(server string) monitors.SQLServerHealthCheckConfig {
+	health := h.config.MSSQLHealth
+	threshold := func(warning int, errorValue int) monitors.MetricThreshold {
+		return monitors.MetricThreshold{Warning: float64(warning), Error: float64(errorValue)}
+	}
+	below := func(warning int, errorValue int) monitors.MetricThreshold {
+		return monitors.MetricThreshold{Warning: float64(warning), Error: float64(errorValue), Below: true}
+	}
+
+	return monitors.SQLServerHealthCheckConfig{
+		Connection:            h.sqlServerConnection(server),
+		CheckID:               "mssql-health",
+		CheckName:             "Salud de la instancia SQL Server",
+		DeadlockWindowMinutes: health.DeadlockWindowMinutes,
+		Thresholds: monitors.SQLServerHealthThresholds{
+			BlockedSessions:     threshold(health.BlockedWarning, health.BlockedError),
+			LongestBlockSeconds: threshold(health.BlockWaitWarningSeconds, health.BlockWaitErrorSeconds),
+			Deadlocks:           threshold(health.DeadlocksWarning, health.DeadlocksError),
+			LogUsedPercent:      threshold(health.LogUsedWarningPercent, health.LogUsedErrorPercent),
+			VolumeFreePercent:   below(health.VolumeFreeWarningPercent, health.VolumeFreeErrorPercent),
+			FileMaxSizePercent:  threshold(health.FileMaxSizeWarningPercent, health.FileMaxSizeErrorPercent),
+			DatabasesNotOnline:  threshold(health.NotOnlineWarning, health.NotOnlineError),
+			CPUPercent:          threshold(health.CPUWarningPercent, health.CPUErrorPercent),
+			PageLifeExpectancy:  below(health.PageLifeWarningSeconds, health.PageLifeErrorSeconds),
+			MemoryGrantsPending: threshold(health.MemoryGrantsPendingWarning, health.MemoryGrantsPendingError),
+		},
+	}
+}
+
+// buildKairosDriveConfig arma la configuración del check de Drive a partir del archivo
+// que la hoja de Kairos informa como generado (o el del día si la hoja no pudo leerse)
+func (h *Handler) buildKairosDriveConfig(kairosCheck models.Check) monitors.GoogleDriveFileCheckConfig {
+	location := time.Local
+	if h.config.GoogleSheets.TimeZone != "" {
+		if loaded, err := time.LoadLocation(h.config.GoogleSheets.TimeZone); err == nil {
+			location = loaded
+		}
+	}
+
+	fileDate := time.Now().In(location)
+	if value, ok := kairosCheck.Metadata["filename_date"].(string); ok {
+		if parsed, err := time.ParseInLocation("2006-01-02", value, location); err == nil {
+			fileDate = parsed
+		}
+	}
+	filename, _ := kairosCheck.Metadata["last_filename"].(string)
+	if filename == "" {
+		filename = fmt.Sprintf("bd_%s.zip", fileDate.Format("20060102"))
+	}
+
+	drive := h.config.GoogleDrive
+	return monitors.GoogleDriveFileCheckConfig{
+		Auth: monitors.GoogleAuthConfig{
+			AuthMethod:      h.config.GoogleSheets.AuthMethod,
+			CredentialsFile: h.config.GoogleSheets.CredentialsFile,
+			APIKey:          h.config.GoogleSheets.APIKey,
+			Endpoint:        drive.Endpoint,
+		},
+		CheckID:                "kairos-drive-file",
+		CheckName:              "Archivo Kairos en Google Drive",
+		FolderID:               drive.FolderID,
+		Filename:               filename,
+		FileDate:               fileDate,
+		RecentPrefix:           "bd_",
+		RecentFiles:            drive.RecentFiles,
+		MinSizeBytes:           drive.MinSizeBytes,
+		MinSizeRatioPercent:    drive.MinSizeRatioPercent,
+		MaxSizeRatioPercent:    drive.MaxSizeRatioPercent,
+		MaxModifiedOffsetHours: drive.MaxModifiedOffsetHours,
+	}
+}
+
+// runCrawlCheck retorna el último resultado del crawler sobre SaltaCompra Producción
+// Si pasó CRAWL_INTERVAL_MINUTES desde el último recorrido inicia uno nuevo en segundo plano:
+// el recorrido puede tardar minutos (rate limit) y no debe demorar el refresh del sistema
+func (h *Handler) runCrawlCheck() models.Check {
+	h.crawlMu.Lock()
+	defer h.crawlMu.Unlock()
+
+	interval := time.Duration(h.config.Crawl.IntervalMinutes) * time.Minute
+	if !h.crawlRunning && (h.lastCrawl.LastCheck.IsZero() || time.Since(h.lastCrawl.LastCheck) >= interval) {
+		h.crawlRunning = true
+		go h.crawlInBackground()
+	}
+
+	if h.lastCrawl.LastCheck.IsZero() {
+		return models.Check{
+			ID:        "broken-links",
+			Type:      "crawl",
+			Name:      "Enlaces rotos",
+			Status:    "unknown",
+			Message:   "Primer recorrido del sitio en curso",
+			LastCheck: time.Now(),
+		}
+	}
+	return h.lastCrawl
+}
+
+// crawlInBackground recorre el sitio y guarda el resultado para los próximos refresh
+func (h *Handler) crawlInBackground() {
+	result := monitors.CheckCrawl(monitors.CrawlCheckConfig{
+		StartURL:       h.config.SaltaCompra.ProdURL,
+		CheckID:        "broken-links",
+		CheckName:      "Enlaces rotos",
+		MaxDepth:       h.config.Crawl.MaxDepth,
+		MaxPages:       h.config.Crawl.MaxPages,
+		DelayMs:        h.config.Crawl.DelayMs,
+		SlowPageMs:     h.config.Crawl.SlowPageMs,
+		TimeoutSeconds: h.config.Monitors.HTTPTimeoutSeconds,
+	})
+
+	h.crawlMu.Lock()
+	defer h.crawlMu.Unlock()
+	h.lastCrawl = result
+	h.crawlRunning = false
+}
+
 // determineSystemStatus determina el estado general basado en los checks
 func determineSystemStatus(checks []models.Check) string {
 	if len(checks) == 0 {
@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/saltacompra/monitor/internal/models"
+)
+
+// CheckSample representa el resultado de un check en un momento dado
+type CheckSample struct {
+	Timestamp    time.Time              `json:"timestamp"`
+	Status       string                 `json:"status"`
+	ResponseTime int64                  `json:"response_time_ms"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// recordHistory agrega una muestra por cada check del sistema
+// Debe llamarse con el lock de escritura tomado
+func (c *SystemCache) recordHistory(system models.System) {
+	if c.historySize <= 0 {
+		return
+	}
+
+	checks, exists := c.history[system.ID]
+	if !exists {
+		checks = make(map[string][]CheckSample)
+		c.history[system.ID] = checks
+	}
+
+	for _, check := range system.Checks {
+		samples := append(checks[check.ID], CheckSample{
+			Timestamp:    check.LastCheck,
+			Status:       check.Status,
+			ResponseTime: check.ResponseTime,
+			Metadata:     historyMetadata(check.Metadata),
+		})
+
+		// Descartar las muestras más antiguas
+		if len(samples) > c.historySize {
+			samples = samples[len(samples)-c.historySize:]
+		}
+		checks[check.ID] = samples
+	}
+}
+
+// GetHistory obtiene las muestras históricas de cada check de un sistema
+// Retorna un mapa vacío si el sistema no tiene historial
+func (c *SystemCache) GetHistory(id string) map[string][]CheckSample {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	result := make(map[string][]CheckSample)
+	for checkID, samples := range c.history[id] {
+		result[checkID] = append([]CheckSample(nil), samples...)
+	}
+
+	return result
+}
+
+// historyMetadata conserva solo los valores escalares de la metadata (y mapas de escalares,
+// como el desglose de tiempos) para no acumular listas grandes en memoria
+func historyMetadata(metadata map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	for key, value := range metadata {
+		switch v := value.(type) {
+		case string, bool, int, int64, float64:
+			result[key] = v
+		case map[string]interface{}:
+			nested := make(map[string]interface{})
+			for nestedKey, nestedValue := range v {
+				switch nestedValue.(type) {
+				case string, bool, int, int64, float64:
+					nested[nestedKey] = nestedValue
+				}
+			}
+			if len(nested) > 0 {
+				result[key] = nested
+			}
+		}
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
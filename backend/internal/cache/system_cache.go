@@ -14,15 +14,21 @@ type CachedSystem struct {
 }
 
 // SystemCache es un cache thread-safe para almacenar el estado de los sistemas
+// Además conserva un historial acotado de resultados por check
 type SystemCache struct {
-	mu      sync.RWMutex
-	systems map[string]CachedSystem
+	mu          sync.RWMutex
+	systems     map[string]CachedSystem
+	history     map[string]map[string][]CheckSample // sistema -> check -> muestras
+	historySize int
 }
 
 // NewSystemCache crea una nueva instancia del cache
-func NewSystemCache() *SystemCache {
+// historySize indica cuántas muestras por check se conservan (0 = sin historial)
+func NewSystemCache(historySize int) *SystemCache {
 	return &SystemCache{
-		systems: make(map[string]CachedSystem),
+		systems:     make(map[string]CachedSystem),
+		history:     make(map[string]map[string][]CheckSample),
+		historySize: historySize,
 	}
 }
 
@@ -40,7 +46,7 @@ func (c *SystemCache) Get(id string) (models.System, bool) {
 	return cached.Data, true
 }
 
-// Set guarda o actualiza un sistema en el cache
+// Set guarda o actualiza un sistema en el cache y registra sus checks en el historial
 func (c *SystemCache) Set(id string, system models.System) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -49,6 +55,7 @@ func (c *SystemCache) Set(id string, system models.System) {
 		Data:      system,
 		UpdatedAt: time.Now(),
 	}
+	c.recordHistory(system)
 }
 
 // GetAll obtiene todos los sistemas del cache
@@ -85,6 +92,7 @@ func (c *SystemCache) Clear() {
 	defer c.mu.Unlock()
 
 	c.systems = make(map[string]CachedSystem)
+	c.history = make(map[string]map[string][]CheckSample)
 }
 
 // GetTimestamp obtiene el timestamp de última actualización de un sistema
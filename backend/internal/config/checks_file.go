@@ -0,0 +1,268 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ChecksFileConfig contiene los checks estructurados definidos en el archivo JSON
+// indicado por CHECKS_CONFIG_FILE (opcional)
+type ChecksFileConfig struct {
+	Scenarios        []ScenarioConfig         `json:"scenarios"`
+	ContentIntegrity []ContentIntegrityConfig `json:"content_integrity"`
+	APIContracts     []APIContractConfig      `json:"api_contracts"`
+	MSSQLQueries     []MSSQLQueryConfig       `json:"mssql_queries"`
+	PostgresQueries  []PostgresQueryConfig    `json:"postgres_queries"`
+	MailCategories   []MailCategoryConfig     `json:"mail_categories"`
+	SheetFreshness   []SheetFreshnessConfig   `json:"sheet_freshness"`
+}
+
+// ScenarioConfig define un check sintético de varios pasos (ej: login + navegación)
+type ScenarioConfig struct {
+	SystemID            string               `json:"system_id"` // Sistema al que se agrega el check
+	CheckID             string               `json:"check_id"`
+	CheckName           string               `json:"check_name"`
+	BaseURL             string               `json:"base_url"` // Opcional, por defecto la URL del sistema
+	TimeoutSeconds      int                  `json:"timeout_seconds"`
+	SkipSSLVerification bool                 `json:"skip_ssl_verification"`
+	Variables           map[string]string    `json:"variables"` // Admiten ${ENV_VAR} para credenciales
+	Steps               []ScenarioStepConfig `json:"steps"`
+}
+
+// ScenarioStepConfig define un paso HTTP del escenario
+type ScenarioStepConfig struct {
+	Name              string                  `json:"name"`
+	Method            string                  `json:"method"`
+	URL               string                  `json:"url"` // Absoluta o relativa a base_url
+	Headers           map[string]string       `json:"headers"`
+	Form              map[string]string       `json:"form"`
+	Body              string                  `json:"body"`
+	ExpectStatus      []int                   `json:"expect_status"`
+	ExpectContains    []string                `json:"expect_contains"`
+	ExpectNotContains []string                `json:"expect_not_contains"`
+	WarningMs         int64                   `json:"warning_ms"`
+	Extract           []ScenarioExtractConfig `json:"extract"`
+}
+
+// ScenarioExtractConfig define una variable a capturar de la respuesta de un paso
+type ScenarioExtractConfig struct {
+	Name    string `json:"name"`
+	Source  string `json:"source"`  // "body" (regex), "input" (campo de formulario), "header" o "cookie"
+	Key     string `json:"key"`     // Nombre del input, header o cookie
+	Pattern string `json:"pattern"` // Regex con un grupo de captura (source "body")
+}
+
+// ContentIntegrityConfig define un check de integridad de contenido (detección de defacement)
+type ContentIntegrityConfig struct {
+	SystemID            string   `json:"system_id"`
+	CheckID             string   `json:"check_id"`
+	CheckName           string   `json:"check_name"`
+	URL                 string   `json:"url"`              // Opcional, por defecto la URL del sistema
+	IgnoreSelectors     []string `json:"ignore_selectors"` // Elementos dinámicos a descartar
+	IgnorePatterns      []string `json:"ignore_patterns"`  // Regex a eliminar del contenido normalizado
+	ChangeStatus        string   `json:"change_status"`    // "warning" o "error" (default)
+	SkipSSLVerification bool     `json:"skip_ssl_verification"`
+	TimeoutSeconds      int      `json:"timeout_seconds"`
+}
+
+// APIContractConfig define un check de contrato de API REST contra OpenAPI 3 o JSON Schema
+type APIContractConfig struct {
+	SystemID            string                      `json:"system_id"`
+	CheckID             string                      `json:"check_id"`
+	CheckName           string                      `json:"check_name"`
+	BaseURL             string                      `json:"base_url"`         // Opcional, por defecto la URL del sistema
+	SchemaFile          string                      `json:"schema_file"`      // Documento OpenAPI 3 o JSON Schema en formato JSON
+	ViolationStatus     string                      `json:"violation_status"` // "warning" (default) o "error"
+	MaxViolations       int                         `json:"max_violations"`
+	SkipSSLVerification bool                        `json:"skip_ssl_verification"`
+	TimeoutSeconds      int                         `json:"timeout_seconds"`
+	Endpoints           []APIContractEndpointConfig `json:"endpoints"`
+}
+
+// APIContractEndpointConfig define un endpoint a validar
+type APIContractEndpointConfig struct {
+	Name          string            `json:"name"`
+	Method        string            `json:"method"`
+	Path          string            `json:"path"`
+	OperationPath string            `json:"operation_path"` // Path con parámetros del documento OpenAPI
+	Headers       map[string]string `json:"headers"`        // Admiten ${ENV_VAR} para tokens
+	Body          string            `json:"body"`
+	ExpectStatus  int               `json:"expect_status"`
+	SchemaRef     string            `json:"schema_ref"` // JSON Pointer al schema, opcional con OpenAPI
+}
+
+// QueryThresholdsConfig umbrales para evaluar el resultado de una consulta (los campos ausentes no se evalúan)
+type QueryThresholdsConfig struct {
+	Column         string   `json:"column"` // Columna evaluada (default: primera)
+	WarningMin     *float64 `json:"warning_min"`
+	WarningMax     *float64 `json:"warning_max"`
+	ErrorMin       *float64 `json:"error_min"`
+	ErrorMax       *float64 `json:"error_max"`
+	Expected       *string  `json:"expected"`
+	MinRows        *int     `json:"min_rows"`
+	WarningMaxRows *int     `json:"warning_max_rows"`
+	ErrorMaxRows   *int     `json:"error_max_rows"`
+	WarningRule    string   `json:"warning_rule"` // Ej: "pendientes > 10 && antiguedad_horas >= 48"
+	ErrorRule      string   `json:"error_rule"`
+}
+
+// MSSQLQueryConfig define un check de consulta de solo lectura sobre SQL Server
+type MSSQLQueryConfig struct {
+	SystemID       string                `json:"system_id"`
+	CheckID        string                `json:"check_id"`
+	CheckName      string                `json:"check_name"`
+	Server         string                `json:"server"`   // "prod" o "preprod" (DB_PROD_* / DB_PREPROD_*)
+	Database       string                `json:"database"` // Opcional, por defecto la base del servidor
+	Query          string                `json:"query"`
+	TimeoutSeconds int                   `json:"timeout_seconds"`
+	MaxRows        int                   `json:"max_rows"` // Filas incluidas en metadata
+	Thresholds     QueryThresholdsConfig `json:"thresholds"`
+}
+
+// PostgresQueryConfig define un check de consulta de solo lectura sobre PostgreSQL (DB_APPSALTACOMPRA_*)
+type PostgresQueryConfig struct {
+	SystemID       string                `json:"system_id"`
+	CheckID        string                `json:"check_id"`
+	CheckName      string                `json:"check_name"`
+	Database       string                `json:"database"` // Opcional, por defecto DB_APPSALTACOMPRA_NAME
+	Query          string                `json:"query"`    // Parámetros posicionales $1, $2...
+	Params         []interface{}         `json:"params"`
+	TimeoutSeconds int                   `json:"timeout_seconds"`
+	MaxRows        int                   `json:"max_rows"`  // Filas incluidas en metadata
+	VPNCheck       *bool                 `json:"vpn_check"` // Verificar VPN antes de conectar (default true)
+	Thresholds     QueryThresholdsConfig `json:"thresholds"`
+}
+
+// MailCategoryConfig define un tipo de correo con umbrales propios en el check de mails
+type MailCategoryConfig struct {
+	SystemID              string `json:"system_id"` // Opcional, vacío aplica a producción y preproducción
+	Name                  string `json:"name"`
+	SubjectLike           string `json:"subject_like"`   // Patrón LIKE de SQL Server sobre el asunto
+	RecipientLike         string `json:"recipient_like"` // Patrón LIKE de SQL Server sobre los destinatarios
+	WarningFailedPercent  int    `json:"warning_failed_percent"`
+	ErrorFailedPercent    int    `json:"error_failed_percent"`
+	MaxMinutesWithoutSent int    `json:"max_minutes_without_sent"`
+}
+
+// SheetFreshnessConfig define un check de actualización diaria de una hoja de Google Sheets
+// La autenticación se toma de GSHEETS_AUTH_METHOD / GSHEETS_CREDENTIALS_FILE / GSHEETS_API_KEY
+type SheetFreshnessConfig struct {
+	SystemID           string             `json:"system_id"`
+	CheckID            string             `json:"check_id"`
+	CheckName          string             `json:"check_name"`
+	SpreadsheetID      string             `json:"spreadsheet_id"`
+	SheetName          string             `json:"sheet_name"`
+	TimestampColumn    int                `json:"timestamp_column"`     // Índice desde 0
+	FilenameColumn     *int               `json:"filename_column"`      // Opcional, sin columna de archivo si no se indica
+	HeaderRows         *int               `json:"header_rows"`          // Default 1
+	TimestampLayouts   []string           `json:"timestamp_layouts"`    // Layouts de Go (ej: "02/01/2006 15:04:05")
+	TimeZone           string             `json:"time_zone"`            // IANA (ej: "America/Argentina/Salta")
+	FilenamePattern    string             `json:"filename_pattern"`     // Regex con grupo (?P<date>...)
+	FilenameDateLayout string             `json:"filename_date_layout"` // Default 20060102
+	ExpectedFilename   string             `json:"expected_filename"`    // Con {date} como marcador
+	LatestRow          string             `json:"latest_row"`           // "last" (default) o "max_timestamp"
+	WarningDays        int                `json:"warning_days"`
+	ErrorDays          int                `json:"error_days"`
+	History            SheetHistoryConfig `json:"history"`   // Opcional
+	TailRows           *int               `json:"tail_rows"` // Filas leídas desde el final (default GSHEETS_TAIL_ROWS, 0 = hoja completa)
+	TimeoutSeconds     int                `json:"timeout_seconds"`
+}
+
+// SheetHistoryConfig define el análisis del historial de cargas de una hoja
+type SheetHistoryConfig struct {
+	Days                       int      `json:"days"`          // 0 deshabilita el análisis
+	BusinessDays               []int    `json:"business_days"` // 0 = domingo ... 6 = sábado (vacío = todos)
+	Holidays                   []string `json:"holidays"`      // YYYY-MM-DD
+	TimeShiftMinutes           int      `json:"time_shift_minutes"`
+	CompletenessWarningPercent int      `json:"completeness_warning_percent"`
+	CompletenessErrorPercent   int      `json:"completeness_error_percent"`
+}
+
+// loadChecksFile carga el archivo JSON de checks estructurados
+// Si path está vacío retorna una configuración vacía
+func loadChecksFile(path string) (ChecksFileConfig, error) {
+	var checks ChecksFileConfig
+	if path == "" {
+		return checks, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return checks, fmt.Errorf("no se pudo leer %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, &checks); err != nil {
+		return checks, fmt.Errorf("JSON inválido en %s: %w", path, err)
+	}
+
+	if err := validateMailCategories(checks.MailCategories); err != nil {
+		return checks, fmt.Errorf("%s: %w", path, err)
+	}
+
+	// Expandir variables de entorno en las variables de escenarios (credenciales)
+	for i := range checks.Scenarios {
+		for name, value := range checks.Scenarios[i].Variables {
+			checks.Scenarios[i].Variables[name] = os.ExpandEnv(value)
+		}
+	}
+
+	return checks, nil
+}
+
+// validateMailCategories verifica los tipos de correo al cargar la configuración: un patrón LIKE
+// mal formado no da error en SQL Server, simplemente no coincide con ningún correo
+func validateMailCategories(categories []MailCategoryConfig) error {
+	var problems []string
+	for i, category := range categories {
+		label := fmt.Sprintf("mail_categories[%d]", i)
+		if category.Name == "" {
+			problems = append(problems, label+": name es requerido")
+		} else {
+			label = fmt.Sprintf("mail_categories[%d] (%s)", i, category.Name)
+		}
+		if category.SubjectLike == "" && category.RecipientLike == "" {
+			problems = append(problems, label+": requiere subject_like o recipient_like")
+		}
+		for field, pattern := range map[string]string{"subject_like": category.SubjectLike, "recipient_like": category.RecipientLike} {
+			if err := validateLikePattern(pattern); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %s inválido: %s", label, field, err))
+			}
+		}
+		for field, percent := range map[string]int{"warning_failed_percent": category.WarningFailedPercent, "error_failed_percent": category.ErrorFailedPercent} {
+			if percent < 0 || percent > 100 {
+				problems = append(problems, fmt.Sprintf("%s: %s debe estar entre 0 y 100", label, field))
+			}
+		}
+		if category.WarningFailedPercent > 0 && category.ErrorFailedPercent > 0 && category.WarningFailedPercent > category.ErrorFailedPercent {
+			problems = append(problems, label+": warning_failed_percent no puede superar error_failed_percent")
+		}
+		if category.MaxMinutesWithoutSent < 0 {
+			problems = append(problems, label+": max_minutes_without_sent no puede ser negativo")
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// validateLikePattern verifica que los corchetes de un patrón LIKE de SQL Server estén balanceados
+func validateLikePattern(pattern string) error {
+	open := false
+	for _, r := range pattern {
+		switch {
+		case r == '[' && !open:
+			open = true
+		case r == ']' && open:
+			open = false
+		}
+	}
+	if open {
+		return fmt.Errorf("corchete '[' sin cerrar en %q", pattern)
+	}
+	return nil
+}
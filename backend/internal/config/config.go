@@ -14,6 +14,8 @@ type Config struct {
 	DatabasePreProd    DatabaseConfig
 	PostgreSQLAppSPC   PostgreSQLConfig
 	GoogleSheets       GoogleSheetsConfig
+	GoogleDrive        GoogleDriveConfig
+	AppsScript         AppsScriptConfig
 	SaltaCompra        SaltaCompraConfig
 	AppSaltaCompra     AppSaltaCompraConfig
 	Infrastructure     InfrastructureConfig
@@ -21,11 +23,20 @@ type Config struct {
 	VPNCheck           VPNCheckConfig
 	Scheduler          SchedulerConfig
 	Cache              CacheConfig
+	Crawl              CrawlConfig
+	PostgreSQLHealth   PostgreSQLHealthConfig
+	AgentJobs          AgentJobsConfig
+	Backups            BackupConfig
+	MSSQLHealth        MSSQLHealthConfig
+	PreProdMailGuard   PreProdMailGuardConfig
+	Storage            StorageConfig
+	Checks             ChecksFileConfig
 }
 
 // ServerConfig configuración del servidor HTTP
 type ServerConfig struct {
-	Port string
+	Port       string
+	AdminToken string // Token requerido por los endpoints administrativos (vacío = deshabilitados)
 }
 
 // DatabaseConfig configuración de conexión a SQL Server
@@ -39,15 +50,48 @@ type DatabaseConfig struct {
 
 // GoogleSheetsConfig configuración para Google Sheets API
 type GoogleSheetsConfig struct {
-	SpreadsheetID   string
-	SheetName       string
-	AuthMethod      string // "service_account" o "api_key"
-	CredentialsFile string // Ruta al JSON de service account
-	APIKey          string // API key (alternativa)
-	TimestampColumn int    // Índice de columna TimeStamp (0)
-	FilenameColumn  int    // Índice de columna Nombre Archivo (2)
-	WarningDays     int    // Días de antigüedad para warning
-	ErrorDays       int    // Días de antigüedad para error
+	SpreadsheetID              string
+	SheetName                  string
+	AuthMethod                 string   // "service_account" o "api_key"
+	CredentialsFile            string   // Ruta al JSON de service account
+	APIKey                     string   // API key (alternativa)
+	Endpoint                   string   // URL base alternativa de las APIs de Google (ej: servidor local de pruebas)
+	TailRows                   int      // Filas leídas desde el final de la hoja (0 = hoja completa)
+	TimestampColumn            int      // Índice de columna TimeStamp (0)
+	FilenameColumn             int      // Índice de columna Nombre Archivo (2)
+	WarningDays                int      // Días de antigüedad para warning
+	ErrorDays                  int      // Días de antigüedad para error
+	TimestampLayouts           []string // Formatos aceptados en TimeStamp (layouts de Go)
+	TimeZone                   string   // Zona horaria de la hoja (IANA)
+	LatestRow                  string   // Regla de fila más reciente: "last" o "max_timestamp"
+	HistoryDays                int      // Días de historial analizados (0 = deshabilitado)
+	BusinessDays               []int    // Días de la semana con carga esperada (0 = domingo ... 6 = sábado)
+	Holidays                   []string // Feriados sin carga esperada (YYYY-MM-DD)
+	TimeShiftMinutes           int      // Desvío del horario habitual de carga para warning (0 = deshabilitado)
+	CompletenessWarningPercent int      // % de días con carga por debajo del cual hay warning
+	CompletenessErrorPercent   int      // % de días con carga por debajo del cual hay error
+}
+
+// GoogleDriveConfig configuración del check de archivos de Kairos en Google Drive
+// Usa las credenciales de Google Sheets (GSHEETS_*) con su propio endpoint
+type GoogleDriveConfig struct {
+	FolderID               string // Carpeta donde Kairos deja los bd_YYYYMMDD.zip (vacío = check deshabilitado)
+	Endpoint               string // URL base alternativa de Drive API (ej: servidor local de pruebas)
+	RecentFiles            int    // Archivos recientes usados como referencia de tamaño
+	MinSizeBytes           int64  // Tamaño mínimo absoluto para error
+	MinSizeRatioPercent    int    // % mínimo respecto de la mediana reciente para warning
+	MaxSizeRatioPercent    int    // % máximo respecto de la mediana reciente para warning
+	MaxModifiedOffsetHours int    // Horas máximas entre la fecha del archivo y su modificación
+}
+
+// AppsScriptConfig configuración del check de ejecuciones del Apps Script de Kairos
+// La API de Apps Script no acepta service accounts: se usan credenciales de usuario OAuth
+type AppsScriptConfig struct {
+	ScriptIDs            []string // IDs de los proyectos de Apps Script (vacío = check deshabilitado)
+	CredentialsFile      string   // JSON authorized_user (requerido si hay ScriptIDs)
+	Endpoint             string   // URL base alternativa de Apps Script API (ej: servidor local de pruebas)
+	LookbackHours        int      // Horas de ejecuciones consultadas
+	MaxHoursSinceSuccess int      // Horas máximas sin una ejecución exitosa para error (0 = deshabilitado)
 }
 
 // SaltaCompraConfig configuración para monitoreo de SaltaCompra
@@ -60,8 +104,14 @@ type SaltaCompraConfig struct {
 
 // InfrastructureConfig configuración para infraestructura compartida
 type InfrastructureConfig struct {
-	Domain      string
-	RDAPBaseURL string
+	Domains                   []string          // Dominios monitoreados vía RDAP
+	RDAPBaseURL               string            // URL de consulta para todos los dominios (se le agrega el dominio, prevalece sobre el resto)
+	RDAPBaseURLs              map[string]string // URL base RDAP por TLD o sufijo (prevalece sobre el bootstrap de IANA)
+	RDAPBootstrapFile         string            // Copia local del dns.json de IANA (reemplaza a la incluida)
+	RDAPBootstrapURL          string            // URL del registro bootstrap para refrescar
+	RDAPBootstrapRefreshHours int               // Horas entre refrescos del registro (0 = solo copia local/incluida)
+	WHOISFallback             bool              // Consultar WHOIS (puerto 43) si RDAP no responde
+	WHOISServer               string            // Servidor WHOIS para todos los dominios (vacío = según TLD)
 }
 
 // PostgreSQLConfig configuración de conexión a PostgreSQL
@@ -89,14 +139,22 @@ type VPNCheckConfig struct {
 // MonitorsConfig configuración de umbrales para monitores
 type MonitorsConfig struct {
 	MailMaxMinutesWithoutSent    int   // Minutos máximos sin correo 'sent' antes de warning
-	MailDailyWarningFailedPercent int   // % de fallidos del día para warning
-	MailDailyErrorFailedPercent   int   // % de fallidos del día para error
+	MailDailyWarningFailedPercent int   // % de fallidos para warning (en cualquiera de las ventanas)
+	MailDailyErrorFailedPercent   int   // % de fallidos para error (en cualquiera de las ventanas)
+	MailWindowsMinutes           []int // Ventanas móviles de análisis de correos en minutos
+	MailStuckWarningMinutes      int   // Antigüedad del correo pendiente más viejo para warning
+	MailStuckErrorMinutes        int   // Antigüedad del correo pendiente más viejo para error
+	MailMinFailureSamples        int   // Correos mínimos de un grupo o ventana para evaluar su % de fallidos
 	HTTPTimeoutWarningMs         int64 // Umbral de ms para warning en checks HTTP
 	HTTPTimeoutErrorMs           int64 // Umbral de ms para error en checks HTTP
+	HTTPTTFBWarningMs            int64 // Umbral de ms hasta el primer byte para warning (0 = deshabilitado)
+	HTTPTTFBErrorMs              int64 // Umbral de ms hasta el primer byte para error (0 = deshabilitado)
 	SSLWarningDays               int   // Días antes de expiración SSL para warning
 	HTTPTimeoutSeconds           int   // Timeout general para peticiones HTTP
 	DomainWarningDays            int   // Días antes de expiración de dominio para warning
 	DomainErrorDays              int   // Días antes de expiración de dominio para error
+	WebSecurityWarningScore      int   // Puntaje de seguridad web (0-100) por debajo del cual hay warning
+	WebSecurityErrorScore        int   // Puntaje de seguridad web (0-100) por debajo del cual hay error
 }
 
 // SchedulerConfig configuración para el background worker
@@ -108,6 +166,98 @@ type SchedulerConfig struct {
 // CacheConfig configuración para el cache de sistemas
 type CacheConfig struct {
 	MaxAgeMinutes int // Edad máxima en minutos antes de considerar datos desactualizados
+	HistorySize   int // Cantidad de muestras históricas por check a conservar
+}
+
+// CrawlConfig configuración del crawler de enlaces rotos de SaltaCompra
+type CrawlConfig struct {
+	Enabled         bool  // Habilita el check de enlaces rotos
+	MaxDepth        int   // Profundidad máxima desde SALTACOMPRA_PROD_URL
+	MaxPages        int   // Presupuesto máximo de URLs por ejecución
+	DelayMs         int   // Pausa entre peticiones (rate limit)
+	SlowPageMs      int64 // Umbral de ms para páginas lentas
+	IntervalMinutes int   // Minutos mínimos entre recorridos (se reutiliza el último resultado)
+}
+
+// PostgreSQLHealthConfig umbrales del check de salud de PostgreSQL de App.SaltaCompra
+// Los umbrales se comparan con >= y 0 deshabilita el umbral
+type PostgreSQLHealthConfig struct {
+	ConnectionsWarningPercent int // % de max_connections en uso para warning
+	ConnectionsErrorPercent   int // % de max_connections en uso para error
+	LongQuerySeconds          int // Segundos a partir de los cuales una consulta activa es larga
+	LongQueriesWarning        int // Cantidad de consultas largas para warning
+	LongQueriesError          int // Cantidad de consultas largas para error
+	IdleTxSeconds             int // Segundos a partir de los cuales se reporta una sesión idle in transaction
+	IdleTxWarning             int // Cantidad de sesiones idle in transaction para warning
+	IdleTxError               int // Cantidad de sesiones idle in transaction para error
+	BlockedWarning            int // Cantidad de sesiones bloqueadas para warning
+	BlockedError              int // Cantidad de sesiones bloqueadas para error
+	ReplicationLagWarningSecs int // Segundos de lag de replicación para warning
+	ReplicationLagErrorSecs   int // Segundos de lag de replicación para error
+	GrowthWarningMBPerDay     int // Crecimiento diario en MB para warning
+	GrowthErrorMBPerDay       int // Crecimiento diario en MB para error
+	XIDWarningPercent         int // % del límite de wraparound de transacciones para warning
+	XIDErrorPercent           int // % del límite de wraparound de transacciones para error
+	StaleVacuumHours          int // Horas sin vacuum para considerar una tabla desatendida
+	StaleVacuumMinDeadTuples  int // Tuplas muertas mínimas para considerar una tabla
+	StaleVacuumWarning        int // Cantidad de tablas desatendidas para warning
+	StaleVacuumError          int // Cantidad de tablas desatendidas para error
+}
+
+// AgentJobsConfig umbrales del check de jobs del SQL Server Agent (prod y preprod)
+type AgentJobsConfig struct {
+	LongRunningFactor     float64 // Un job es lento si supera factor × su duración promedio (admite decimales: 1.5)
+	MinLongRunningMinutes int     // Duración mínima para reportar un job como lento
+	ScheduleGraceMinutes  int     // Minutos de tolerancia tras la ejecución programada antes de reportarla omitida
+}
+
+// BackupConfig umbrales del check de antigüedad de backups de SQL Server (0 deshabilita el umbral)
+type BackupConfig struct {
+	FullWarningHours  int      // Horas desde el último backup completo para warning
+	FullErrorHours    int      // Horas desde el último backup completo para error
+	DiffWarningHours  int      // Horas desde el último backup diferencial (o completo) para warning
+	DiffErrorHours    int      // Horas desde el último backup diferencial (o completo) para error
+	LogWarningMinutes int      // Minutos desde el último backup de log para warning (recovery FULL)
+	LogErrorMinutes   int      // Minutos desde el último backup de log para error (recovery FULL)
+	ExcludeDatabases  []string // Bases a ignorar (default: model; tempdb siempre se ignora)
+}
+
+// MSSQLHealthConfig umbrales del check de salud de las instancias SQL Server (0 deshabilita el umbral)
+type MSSQLHealthConfig struct {
+	BlockedWarning             int // Sesiones bloqueadas para warning
+	BlockedError               int // Sesiones bloqueadas para error
+	BlockWaitWarningSeconds    int // Segundos de la espera por bloqueo más larga para warning
+	BlockWaitErrorSeconds      int // Segundos de la espera por bloqueo más larga para error
+	DeadlockWindowMinutes      int // Ventana en minutos para contar deadlocks
+	DeadlocksWarning           int // Deadlocks en la ventana para warning
+	DeadlocksError             int // Deadlocks en la ventana para error
+	LogUsedWarningPercent      int // % de log usado para warning
+	LogUsedErrorPercent        int // % de log usado para error
+	VolumeFreeWarningPercent   int // % libre de volumen con archivos de datos/log para warning (menor o igual)
+	VolumeFreeErrorPercent     int // % libre de volumen con archivos de datos/log para error (menor o igual)
+	FileMaxSizeWarningPercent  int // % usado de un archivo respecto de su max_size para warning
+	FileMaxSizeErrorPercent    int // % usado de un archivo respecto de su max_size para error
+	NotOnlineWarning           int // Bases no ONLINE (RECOVERING, RESTORING, OFFLINE) para warning
+	NotOnlineError             int // Bases no ONLINE (RECOVERING, RESTORING, OFFLINE) para error
+	CPUWarningPercent          int // % de CPU usada por SQL Server para warning
+	CPUErrorPercent            int // % de CPU usada por SQL Server para error
+	PageLifeWarningSeconds     int // Page life expectancy para warning (menor o igual)
+	PageLifeErrorSeconds       int // Page life expectancy para error (menor o igual)
+	MemoryGrantsPendingWarning int // Memory grants pendientes para warning
+	MemoryGrantsPendingError   int // Memory grants pendientes para error
+}
+
+// PreProdMailGuardConfig destinatarios permitidos para los correos de preproducción
+// Si ambas listas están vacías, cualquier destinatario se reporta
+type PreProdMailGuardConfig struct {
+	AllowedDomains   []string // Dominios permitidos (incluye subdominios)
+	AllowedAddresses []string // Direcciones permitidas
+	LookbackHours    int      // Horas hacia atrás en que se revisan los correos
+}
+
+// StorageConfig configuración del estado persistido entre reinicios
+type StorageConfig struct {
+	StateFile string // Archivo JSON con baselines y snapshots de checks
 }
 
 // LoadConfig carga la configuración desde variables de entorno
@@ -124,7 +274,6 @@ func LoadConfig() (Config, error) {
 		"SALTACOMPRA_PROD_URL", "SALTACOMPRA_PROD_EXPECTED_CONTENT",
 		"SALTACOMPRA_PREPROD_URL", "SALTACOMPRA_PREPROD_EXPECTED_CONTENT",
 		"APPSALTACOMPRA_URL", "APPSALTACOMPRA_EXPECTED_CONTENT", "APPSALTACOMPRA_SKIP_SSL_VERIFICATION",
-		"INFRASTRUCTURE_DOMAIN", "RDAP_BASE_URL",
 		"VPN_CHECK_HOST", "VPN_CHECK_TIMEOUT_MS",
 		"GSHEETS_SPREADSHEET_ID", "GSHEETS_SHEET_NAME", "GSHEETS_AUTH_METHOD",
 		"GSHEETS_CREDENTIALS_FILE", "GSHEETS_TIMESTAMP_COLUMN", "GSHEETS_FILENAME_COLUMN",
@@ -141,14 +290,41 @@ func LoadConfig() (Config, error) {
 		}
 	}
 
+	// INFRASTRUCTURE_DOMAINS (lista) o INFRASTRUCTURE_DOMAIN (un solo dominio)
+	domains := getEnvAsList("INFRASTRUCTURE_DOMAINS")
+	if len(domains) == 0 {
+		domains = getEnvAsList("INFRASTRUCTURE_DOMAIN")
+	}
+	if len(domains) == 0 {
+		errors = append(errors, "Variable requerida no encontrada: INFRASTRUCTURE_DOMAINS")
+	}
+
+	// La API de Apps Script no acepta la service account de Sheets: requiere credenciales de usuario propias
+	if len(getEnvAsList("APPS_SCRIPT_IDS")) > 0 && os.Getenv("APPS_SCRIPT_CREDENTIALS_FILE") == "" {
+		errors = append(errors, "Variable requerida no encontrada: APPS_SCRIPT_CREDENTIALS_FILE (requerida con APPS_SCRIPT_IDS)")
+	}
+
+	// RDAP_BASE_URLS (opcional): un par mal formado se informa con el resto de los errores
+	rdapBaseURLs, err := getEnvAsMap("RDAP_BASE_URLS")
+	if err != nil {
+		errors = append(errors, err.Error())
+	}
+
 	if len(errors) > 0 {
 		return Config{}, fmt.Errorf("Errores de configuración:\n- %s", strings.Join(errors, "\n- "))
 	}
 
+	// Cargar checks estructurados desde archivo JSON (opcional)
+	checksFile, err := loadChecksFile(os.Getenv("CHECKS_CONFIG_FILE"))
+	if err != nil {
+		return Config{}, fmt.Errorf("Errores de configuración:\n- %s", err)
+	}
+
 	// Cargar configuración
 	config := Config{
 		Server: ServerConfig{
-			Port: mustGetEnv("SERVER_PORT"),
+			Port:       mustGetEnv("SERVER_PORT"),
+			AdminToken: os.Getenv("ADMIN_TOKEN"), // Opcional
 		},
 		DatabaseProd: DatabaseConfig{
 			Host:     mustGetEnv("DB_PROD_HOST"),
@@ -165,15 +341,42 @@ func LoadConfig() (Config, error) {
 			Database: mustGetEnv("DB_PREPROD_NAME"),
 		},
 		GoogleSheets: GoogleSheetsConfig{
-			SpreadsheetID:   mustGetEnv("GSHEETS_SPREADSHEET_ID"),
-			SheetName:       mustGetEnv("GSHEETS_SHEET_NAME"),
-			AuthMethod:      mustGetEnv("GSHEETS_AUTH_METHOD"),
-			CredentialsFile: mustGetEnv("GSHEETS_CREDENTIALS_FILE"),
-			APIKey:          os.Getenv("GSHEETS_API_KEY"), // Opcional
-			TimestampColumn: mustGetEnvAsInt("GSHEETS_TIMESTAMP_COLUMN"),
-			FilenameColumn:  mustGetEnvAsInt("GSHEETS_FILENAME_COLUMN"),
-			WarningDays:     mustGetEnvAsInt("GSHEETS_WARNING_DAYS"),
-			ErrorDays:       mustGetEnvAsInt("GSHEETS_ERROR_DAYS"),
+			SpreadsheetID:              mustGetEnv("GSHEETS_SPREADSHEET_ID"),
+			SheetName:                  mustGetEnv("GSHEETS_SHEET_NAME"),
+			AuthMethod:                 mustGetEnv("GSHEETS_AUTH_METHOD"),
+			CredentialsFile:            mustGetEnv("GSHEETS_CREDENTIALS_FILE"),
+			APIKey:                     os.Getenv("GSHEETS_API_KEY"),                   // Opcional
+			Endpoint:                   os.Getenv("GSHEETS_ENDPOINT"),                  // Opcional
+			TailRows:                   getEnvAsIntOrDefault("GSHEETS_TAIL_ROWS", 200), // Opcional
+			TimestampColumn:            mustGetEnvAsInt("GSHEETS_TIMESTAMP_COLUMN"),
+			FilenameColumn:             mustGetEnvAsInt("GSHEETS_FILENAME_COLUMN"),
+			WarningDays:                mustGetEnvAsInt("GSHEETS_WARNING_DAYS"),
+			ErrorDays:                  mustGetEnvAsInt("GSHEETS_ERROR_DAYS"),
+			TimestampLayouts:           getEnvAsList("GSHEETS_TIMESTAMP_LAYOUTS"),                               // Opcional
+			TimeZone:                   os.Getenv("GSHEETS_TIME_ZONE"),                                          // Opcional
+			LatestRow:                  getEnvOrDefault("GSHEETS_LATEST_ROW", "last"),                           // Opcional
+			HistoryDays:                getEnvAsIntOrDefault("GSHEETS_HISTORY_DAYS", 30),                        // Opcional
+			BusinessDays:               getEnvAsIntListOrDefault("GSHEETS_BUSINESS_DAYS", []int{1, 2, 3, 4, 5}), // Opcional
+			Holidays:                   getEnvAsList("GSHEETS_HOLIDAYS"),                                        // Opcional
+			TimeShiftMinutes:           getEnvAsIntOrDefault("GSHEETS_TIME_SHIFT_MINUTES", 120),                 // Opcional
+			CompletenessWarningPercent: getEnvAsIntOrDefault("GSHEETS_COMPLETENESS_WARNING_PERCENT", 95),        // Opcional
+			CompletenessErrorPercent:   getEnvAsIntOrDefault("GSHEETS_COMPLETENESS_ERROR_PERCENT", 80),          // Opcional
+		},
+		GoogleDrive: GoogleDriveConfig{ // Opcional
+			FolderID:               os.Getenv("GDRIVE_KAIROS_FOLDER_ID"),
+			Endpoint:               os.Getenv("GDRIVE_ENDPOINT"),
+			RecentFiles:            getEnvAsIntOrDefault("GDRIVE_RECENT_FILES", 10),
+			MinSizeBytes:           int64(getEnvAsIntOrDefault("GDRIVE_MIN_SIZE_BYTES", 1024)),
+			MinSizeRatioPercent:    getEnvAsIntOrDefault("GDRIVE_MIN_SIZE_RATIO_PERCENT", 50),
+			MaxSizeRatioPercent:    getEnvAsIntOrDefault("GDRIVE_MAX_SIZE_RATIO_PERCENT", 200),
+			MaxModifiedOffsetHours: getEnvAsIntOrDefault("GDRIVE_MAX_MODIFIED_OFFSET_HOURS", 36),
+		},
+		AppsScript: AppsScriptConfig{ // Opcional
+			ScriptIDs:            getEnvAsList("APPS_SCRIPT_IDS"),
+			CredentialsFile:      os.Getenv("APPS_SCRIPT_CREDENTIALS_FILE"),
+			Endpoint:             os.Getenv("APPS_SCRIPT_ENDPOINT"),
+			LookbackHours:        getEnvAsIntOrDefault("APPS_SCRIPT_LOOKBACK_HOURS", 24),
+			MaxHoursSinceSuccess: getEnvAsIntOrDefault("APPS_SCRIPT_MAX_HOURS_SINCE_SUCCESS", 26),
 		},
 		SaltaCompra: SaltaCompraConfig{
 			ProdURL:                mustGetEnv("SALTACOMPRA_PROD_URL"),
@@ -182,8 +385,14 @@ func LoadConfig() (Config, error) {
 			PreProdExpectedContent: mustGetEnv("SALTACOMPRA_PREPROD_EXPECTED_CONTENT"),
 		},
 		Infrastructure: InfrastructureConfig{
-			Domain:      mustGetEnv("INFRASTRUCTURE_DOMAIN"),
-			RDAPBaseURL: mustGetEnv("RDAP_BASE_URL"),
+			Domains:                   domains,
+			RDAPBaseURL:               os.Getenv("RDAP_BASE_URL"),                                                   // Opcional
+			RDAPBaseURLs:              rdapBaseURLs,                                                                 // Opcional
+			RDAPBootstrapFile:         os.Getenv("RDAP_BOOTSTRAP_FILE"),                                             // Opcional
+			RDAPBootstrapURL:          getEnvOrDefault("RDAP_BOOTSTRAP_URL", "https://data.iana.org/rdap/dns.json"), // Opcional
+			RDAPBootstrapRefreshHours: getEnvAsIntOrDefault("RDAP_BOOTSTRAP_REFRESH_HOURS", 24),                     // Opcional
+			WHOISFallback:             getEnvAsBoolOrDefault("WHOIS_FALLBACK_ENABLED", true),                        // Opcional
+			WHOISServer:               os.Getenv("WHOIS_SERVER"),                                                    // Opcional
 		},
 		PostgreSQLAppSPC: PostgreSQLConfig{
 			Host:     mustGetEnv("DB_APPSALTACOMPRA_HOST"),
@@ -205,12 +414,20 @@ func LoadConfig() (Config, error) {
 			MailMaxMinutesWithoutSent:    mustGetEnvAsInt("MAIL_MAX_MINUTES_WITHOUT_SENT"),
 			MailDailyWarningFailedPercent: mustGetEnvAsInt("MAIL_DAILY_WARNING_FAILED_PERCENT"),
 			MailDailyErrorFailedPercent:   mustGetEnvAsInt("MAIL_DAILY_ERROR_FAILED_PERCENT"),
+			MailWindowsMinutes:           getEnvAsIntListOrDefault("MAIL_WINDOWS_MINUTES", []int{15, 60, 1440}), // Opcional
+			MailStuckWarningMinutes:      getEnvAsIntOrDefault("MAIL_STUCK_WARNING_MINUTES", 30),                // Opcional
+			MailStuckErrorMinutes:        getEnvAsIntOrDefault("MAIL_STUCK_ERROR_MINUTES", 120),                 // Opcional
+			MailMinFailureSamples:        getEnvAsIntOrDefault("MAIL_MIN_FAILURE_SAMPLES", 10),                  // Opcional
 			HTTPTimeoutWarningMs:         int64(mustGetEnvAsInt("HTTP_TIMEOUT_WARNING_MS")),
 			HTTPTimeoutErrorMs:           int64(mustGetEnvAsInt("HTTP_TIMEOUT_ERROR_MS")),
+			HTTPTTFBWarningMs:            int64(getEnvAsIntOrDefault("HTTP_TTFB_WARNING_MS", 0)), // Opcional
+			HTTPTTFBErrorMs:              int64(getEnvAsIntOrDefault("HTTP_TTFB_ERROR_MS", 0)),   // Opcional
 			SSLWarningDays:               mustGetEnvAsInt("SSL_WARNING_DAYS"),
 			HTTPTimeoutSeconds:           mustGetEnvAsInt("HTTP_TIMEOUT_SECONDS"),
 			DomainWarningDays:            mustGetEnvAsInt("DOMAIN_WARNING_DAYS"),
 			DomainErrorDays:              mustGetEnvAsInt("DOMAIN_ERROR_DAYS"),
+			WebSecurityWarningScore:      getEnvAsIntOrDefault("WEB_SECURITY_WARNING_SCORE", 80), // Opcional
+			WebSecurityErrorScore:        getEnvAsIntOrDefault("WEB_SECURITY_ERROR_SCORE", 50),   // Opcional
 		},
 		Scheduler: SchedulerConfig{
 			IntervalMinutes:    mustGetEnvAsInt("BACKGROUND_CHECK_INTERVAL_MINUTES"),
@@ -218,7 +435,84 @@ func LoadConfig() (Config, error) {
 		},
 		Cache: CacheConfig{
 			MaxAgeMinutes: mustGetEnvAsInt("CACHE_MAX_AGE_MINUTES"),
+			HistorySize:   getEnvAsIntOrDefault("CACHE_HISTORY_SIZE", 288), // Opcional, 24h con intervalo de 5 min
+		},
+		Crawl: CrawlConfig{ // Opcional
+			Enabled:         getEnvAsBoolOrDefault("CRAWL_ENABLED", false),
+			MaxDepth:        getEnvAsIntOrDefault("CRAWL_MAX_DEPTH", 2),
+			MaxPages:        getEnvAsIntOrDefault("CRAWL_MAX_PAGES", 100),
+			DelayMs:         getEnvAsIntOrDefault("CRAWL_DELAY_MS", 500),
+			SlowPageMs:      int64(getEnvAsIntOrDefault("CRAWL_SLOW_PAGE_MS", 3000)),
+			IntervalMinutes: getEnvAsIntOrDefault("CRAWL_INTERVAL_MINUTES", 60),
+		},
+		PostgreSQLHealth: PostgreSQLHealthConfig{ // Opcional
+			ConnectionsWarningPercent: getEnvAsIntOrDefault("PG_HEALTH_CONNECTIONS_WARNING_PERCENT", 70),
+			ConnectionsErrorPercent:   getEnvAsIntOrDefault("PG_HEALTH_CONNECTIONS_ERROR_PERCENT", 90),
+			LongQuerySeconds:          getEnvAsIntOrDefault("PG_HEALTH_LONG_QUERY_SECONDS", 300),
+			LongQueriesWarning:        getEnvAsIntOrDefault("PG_HEALTH_LONG_QUERIES_WARNING", 1),
+			LongQueriesError:          getEnvAsIntOrDefault("PG_HEALTH_LONG_QUERIES_ERROR", 5),
+			IdleTxSeconds:             getEnvAsIntOrDefault("PG_HEALTH_IDLE_TX_SECONDS", 300),
+			IdleTxWarning:             getEnvAsIntOrDefault("PG_HEALTH_IDLE_TX_WARNING", 1),
+			IdleTxError:               getEnvAsIntOrDefault("PG_HEALTH_IDLE_TX_ERROR", 5),
+			BlockedWarning:            getEnvAsIntOrDefault("PG_HEALTH_BLOCKED_WARNING", 1),
+			BlockedError:              getEnvAsIntOrDefault("PG_HEALTH_BLOCKED_ERROR", 10),
+			ReplicationLagWarningSecs: getEnvAsIntOrDefault("PG_HEALTH_REPLICATION_LAG_WARNING_SECONDS", 60),
+			ReplicationLagErrorSecs:   getEnvAsIntOrDefault("PG_HEALTH_REPLICATION_LAG_ERROR_SECONDS", 300),
+			GrowthWarningMBPerDay:     getEnvAsIntOrDefault("PG_HEALTH_GROWTH_WARNING_MB_PER_DAY", 1024),
+			GrowthErrorMBPerDay:       getEnvAsIntOrDefault("PG_HEALTH_GROWTH_ERROR_MB_PER_DAY", 5120),
+			XIDWarningPercent:         getEnvAsIntOrDefault("PG_HEALTH_XID_WARNING_PERCENT", 40),
+			XIDErrorPercent:           getEnvAsIntOrDefault("PG_HEALTH_XID_ERROR_PERCENT", 70),
+			StaleVacuumHours:          getEnvAsIntOrDefault("PG_HEALTH_STALE_VACUUM_HOURS", 168),
+			StaleVacuumMinDeadTuples:  getEnvAsIntOrDefault("PG_HEALTH_STALE_VACUUM_MIN_DEAD_TUPLES", 10000),
+			StaleVacuumWarning:        getEnvAsIntOrDefault("PG_HEALTH_STALE_VACUUM_WARNING", 1),
+			StaleVacuumError:          getEnvAsIntOrDefault("PG_HEALTH_STALE_VACUUM_ERROR", 10),
+		},
+		AgentJobs: AgentJobsConfig{ // Opcional
+			LongRunningFactor:     getEnvAsFloatOrDefault("AGENT_LONG_RUNNING_FACTOR", 2),
+			MinLongRunningMinutes: getEnvAsIntOrDefault("AGENT_MIN_LONG_RUNNING_MINUTES", 10),
+			ScheduleGraceMinutes:  getEnvAsIntOrDefault("AGENT_SCHEDULE_GRACE_MINUTES", 60),
+		},
+		Backups: BackupConfig{ // Opcional
+			FullWarningHours:  getEnvAsIntOrDefault("BACKUP_FULL_WARNING_HOURS", 192),
+			FullErrorHours:    getEnvAsIntOrDefault("BACKUP_FULL_ERROR_HOURS", 336),
+			DiffWarningHours:  getEnvAsIntOrDefault("BACKUP_DIFF_WARNING_HOURS", 30),
+			DiffErrorHours:    getEnvAsIntOrDefault("BACKUP_DIFF_ERROR_HOURS", 54),
+			LogWarningMinutes: getEnvAsIntOrDefault("BACKUP_LOG_WARNING_MINUTES", 120),
+			LogErrorMinutes:   getEnvAsIntOrDefault("BACKUP_LOG_ERROR_MINUTES", 360),
+			ExcludeDatabases:  getEnvAsListOrDefault("BACKUP_EXCLUDE_DATABASES", []string{"model"}),
+		},
+		MSSQLHealth: MSSQLHealthConfig{ // Opcional
+			BlockedWarning:             getEnvAsIntOrDefault("MSSQL_HEALTH_BLOCKED_WARNING", 1),
+			BlockedError:               getEnvAsIntOrDefault("MSSQL_HEALTH_BLOCKED_ERROR", 10),
+			BlockWaitWarningSeconds:    getEnvAsIntOrDefault("MSSQL_HEALTH_BLOCK_WAIT_WARNING_SECONDS", 30),
+			BlockWaitErrorSeconds:      getEnvAsIntOrDefault("MSSQL_HEALTH_BLOCK_WAIT_ERROR_SECONDS", 300),
+			DeadlockWindowMinutes:      getEnvAsIntOrDefault("MSSQL_HEALTH_DEADLOCK_WINDOW_MINUTES", 60),
+			DeadlocksWarning:           getEnvAsIntOrDefault("MSSQL_HEALTH_DEADLOCKS_WARNING", 1),
+			DeadlocksError:             getEnvAsIntOrDefault("MSSQL_HEALTH_DEADLOCKS_ERROR", 5),
+			LogUsedWarningPercent:      getEnvAsIntOrDefault("MSSQL_HEALTH_LOG_USED_WARNING_PERCENT", 70),
+			LogUsedErrorPercent:        getEnvAsIntOrDefault("MSSQL_HEALTH_LOG_USED_ERROR_PERCENT", 90),
+			VolumeFreeWarningPercent:   getEnvAsIntOrDefault("MSSQL_HEALTH_VOLUME_FREE_WARNING_PERCENT", 20),
+			VolumeFreeErrorPercent:     getEnvAsIntOrDefault("MSSQL_HEALTH_VOLUME_FREE_ERROR_PERCENT", 10),
+			FileMaxSizeWarningPercent:  getEnvAsIntOrDefault("MSSQL_HEALTH_FILE_MAX_SIZE_WARNING_PERCENT", 80),
+			FileMaxSizeErrorPercent:    getEnvAsIntOrDefault("MSSQL_HEALTH_FILE_MAX_SIZE_ERROR_PERCENT", 95),
+			NotOnlineWarning:           getEnvAsIntOrDefault("MSSQL_HEALTH_NOT_ONLINE_WARNING", 1),
+			NotOnlineError:             getEnvAsIntOrDefault("MSSQL_HEALTH_NOT_ONLINE_ERROR", 0),
+			CPUWarningPercent:          getEnvAsIntOrDefault("MSSQL_HEALTH_CPU_WARNING_PERCENT", 80),
+			CPUErrorPercent:            getEnvAsIntOrDefault("MSSQL_HEALTH_CPU_ERROR_PERCENT", 95),
+			PageLifeWarningSeconds:     getEnvAsIntOrDefault("MSSQL_HEALTH_PAGE_LIFE_WARNING_SECONDS", 300),
+			PageLifeErrorSeconds:       getEnvAsIntOrDefault("MSSQL_HEALTH_PAGE_LIFE_ERROR_SECONDS", 60),
+			MemoryGrantsPendingWarning: getEnvAsIntOrDefault("MSSQL_HEALTH_MEMORY_GRANTS_PENDING_WARNING", 1),
+			MemoryGrantsPendingError:   getEnvAsIntOrDefault("MSSQL_HEALTH_MEMORY_GRANTS_PENDING_ERROR", 10),
 		},
+		PreProdMailGuard: PreProdMailGuardConfig{ // Opcional
+			AllowedDomains:   getEnvAsList("PREPROD_MAIL_ALLOWED_DOMAINS"),
+			AllowedAddresses: getEnvAsList("PREPROD_MAIL_ALLOWED_ADDRESSES"),
+			LookbackHours:    getEnvAsIntOrDefault("PREPROD_MAIL_LOOKBACK_HOURS", 24),
+		},
+		Storage: StorageConfig{
+			StateFile: getEnvOrDefault("STATE_FILE", "data/state.json"), // Opcional
+		},
+		Checks: checksFile,
 	}
 
 	return config, nil
@@ -254,3 +548,102 @@ func mustGetEnvAsBool(key string) bool {
 	}
 	return value
 }
+
+// getEnvOrDefault obtiene una variable de entorno opcional
+// Retorna defaultValue si la variable no está definida
+func getEnvOrDefault(key string, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvAsIntOrDefault obtiene una variable de entorno opcional como int
+// Retorna defaultValue si la variable no está definida
+// Panic si el valor no es un entero válido (esto indica un bug de configuración)
+func getEnvAsIntOrDefault(key string, defaultValue int) int {
+	if os.Getenv(key) == "" {
+		return defaultValue
+	}
+	return mustGetEnvAsInt(key)
+}
+
+// getEnvAsFloatOrDefault obtiene una variable de entorno opcional como float64
+// Retorna defaultValue si la variable no está definida
+// Panic si el valor no es un número válido (esto indica un bug de configuración)
+func getEnvAsFloatOrDefault(key string, defaultValue float64) float64 {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		panic(fmt.Sprintf("Variable %s contiene valor inválido: %s (debe ser un número)", key, valueStr))
+	}
+	return value
+}
+
+// getEnvAsBoolOrDefault obtiene una variable de entorno opcional como bool
+// Retorna defaultValue si la variable no está definida
+// Panic si el valor no es un booleano válido (esto indica un bug de configuración)
+func getEnvAsBoolOrDefault(key string, defaultValue bool) bool {
+	if os.Getenv(key) == "" {
+		return defaultValue
+	}
+	return mustGetEnvAsBool(key)
+}
+
+// getEnvAsList obtiene una variable de entorno opcional como lista separada por comas
+// Retorna nil si la variable no está definida
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEnvAsListOrDefault obtiene una variable de entorno opcional como lista separada por comas
+// Retorna defaultValue si la variable no está definida
+func getEnvAsListOrDefault(key string, defaultValue []string) []string {
+	if values := getEnvAsList(key); len(values) > 0 {
+		return values
+	}
+	return defaultValue
+}
+
+// getEnvAsMap obtiene una lista de pares clave=valor separados por coma (ej: "ar=https://rdap.nic.ar/")
+// Las claves se normalizan a minúsculas; retorna error si algún par no tiene clave o valor
+func getEnvAsMap(key string) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, pair := range getEnvAsList(key) {
+		name, value, found := strings.Cut(pair, "=")
+		name, value = strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(value)
+		if !found || name == "" || value == "" {
+			return nil, fmt.Errorf("Variable %s contiene valor inválido: %s (debe ser una lista de clave=valor)", key, pair)
+		}
+		result[name] = value
+	}
+	return result, nil
+}
+
+// getEnvAsIntListOrDefault obtiene una variable de entorno opcional como lista de enteros separada por comas
+// Retorna defaultValue si la variable no está definida
+// Panic si algún valor no es un entero válido (esto indica un bug de configuración)
+func getEnvAsIntListOrDefault(key string, defaultValue []int) []int {
+	values := getEnvAsList(key)
+	if len(values) == 0 {
+		return defaultValue
+	}
+	result := make([]int, 0, len(values))
+	for _, valueStr := range values {
+		value, err := strconv.Atoi(valueStr)
+		if err != nil {
+			panic(fmt.Sprintf("Variable %s contiene valor inválido: %s (debe ser una lista de enteros)", key, valueStr))
+		}
+		result = append(result, value)
+	}
+	return result
+}
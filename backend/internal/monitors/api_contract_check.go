@@ -0,0 +1,292 @@
+package monitors
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saltacompra/monitor/internal/models"
+)
+
+// APIContractCheckConfig contiene la configuración para validar contratos de endpoints REST
+type APIContractCheckConfig struct {
+	CheckID             string
+	CheckName           string
+	BaseURL             string
+	SchemaFile          string // Documento OpenAPI 3 o JSON Schema (formato JSON)
+	ViolationStatus     string // Estado ante violaciones de schema: "warning" (default) o "error"
+	MaxViolations       int    // Máximo de violaciones reportadas por endpoint
+	SkipSSLVerification bool
+	TimeoutSeconds      int
+	Endpoints           []APIContractEndpoint
+}
+
+// APIContractEndpoint define un endpoint a invocar y el schema de su respuesta
+type APIContractEndpoint struct {
+	Name          string
+	Method        string            // GET por defecto
+	Path          string            // Path concreto a invocar (ej: /api/procesos/123)
+	OperationPath string            // Path del documento OpenAPI (ej: /api/procesos/{id}), por defecto Path
+	Headers       map[string]string // Ej: Authorization
+	Body          string
+	ExpectStatus  int    // Código esperado (default 200)
+	SchemaRef     string // JSON Pointer al schema (ej: #/components/schemas/Proceso); si falta se deriva de OpenAPI
+}
+
+// CheckAPIContract invoca los endpoints configurados y valida sus respuestas JSON contra el schema
+func CheckAPIContract(config APIContractCheckConfig) models.Check {
+	check := models.Check{
+		ID:        config.CheckID,
+		Type:      "api-contract",
+		Name:      config.CheckName,
+		LastCheck: time.Now(),
+		Metadata:  make(map[string]interface{}),
+	}
+
+	document, err := loadSchemaDocument(config.SchemaFile)
+	if err != nil {
+		check.Status = "error"
+		check.Message = "Error al cargar el schema: " + err.Error()
+		return check
+	}
+	// Un documento que no es objeto (ej: el schema booleano true) se trata como JSON Schema
+	root, _ := document.(map[string]interface{})
+	_, isOpenAPI := root["openapi"]
+	check.Metadata["schema_kind"] = map[bool]string{true: "openapi", false: "json-schema"}[isOpenAPI]
+
+	timeout := config.TimeoutSeconds
+	if timeout == 0 {
+		timeout = 30 // Default 30 segundos
+	}
+	maxViolations := config.MaxViolations
+	if maxViolations == 0 {
+		maxViolations = 20
+	}
+	client := getHTTPClient(timeout, config.SkipSSLVerification)
+
+	var endpointResults []map[string]interface{}
+	var failedEndpoints, violatingEndpoints []string
+	totalViolations := 0
+	start := time.Now()
+
+	for _, endpoint := range config.Endpoints {
+		result, violations, err := checkContractEndpoint(client, config.BaseURL, document, isOpenAPI, endpoint, maxViolations)
+		if err != nil {
+			result["status"] = "error"
+			result["message"] = err.Error()
+			failedEndpoints = append(failedEndpoints, endpoint.Name)
+		} else if len(violations) > 0 {
+			result["status"] = "violation"
+			result["violations"] = violations
+			violatingEndpoints = append(violatingEndpoints, endpoint.Name)
+			totalViolations += len(violations)
+		} else {
+			result["status"] = "ok"
+		}
+		endpointResults = append(endpointResults, result)
+	}
+
+	check.ResponseTime = time.Since(start).Milliseconds()
+	check.Metadata["endpoints"] = endpointResults
+	check.Metadata["total_violations"] = totalViolations
+
+	violationStatus := "warning"
+	if config.ViolationStatus == "error" {
+		violationStatus = "error"
+	}
+
+	switch {
+	case len(failedEndpoints) > 0:
+		check.Status = "error"
+		check.Message = fmt.Sprintf("Endpoints con error: %s", strings.Join(failedEndpoints, ", "))
+	case len(violatingEndpoints) > 0:
+		check.Status = violationStatus
+		check.Message = fmt.Sprintf("%d violaciones de contrato en: %s", totalViolations, strings.Join(violatingEndpoints, ", "))
+	default:
+		check.Status = "ok"
+		check.Message = fmt.Sprintf("%d endpoints cumplen el contrato (%dms)", len(config.Endpoints), check.ResponseTime)
+	}
+
+	return check
+}
+
+// checkContractEndpoint invoca un endpoint y valida su respuesta
+// Retorna error si el endpoint no responde como se espera (conexión, código HTTP, JSON inválido)
+func checkContractEndpoint(client *http.Client, baseURL string, document interface{}, isOpenAPI bool, endpoint APIContractEndpoint, maxViolations int) (map[string]interface{}, []SchemaViolation, error) {
+	result := map[string]interface{}{"name": endpoint.Name, "path": endpoint.Path}
+
+	method := strings.ToUpper(endpoint.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	expectStatus := endpoint.ExpectStatus
+	if expectStatus == 0 {
+		expectStatus = http.StatusOK
+	}
+
+	endpointURL, err := resolveScenarioURL(baseURL, endpoint.Path)
+	if err != nil {
+		return result, nil, fmt.Errorf("URL inválida: %w", err)
+	}
+
+	req, err := http.NewRequest(method, endpointURL, strings.NewReader(endpoint.Body))
+	if err != nil {
+		return result, nil, fmt.Errorf("error al crear petición: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if endpoint.Body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range endpoint.Headers {
+		req.Header.Set(name, os.ExpandEnv(value))
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return result, nil, fmt.Errorf("no se pudo conectar: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := readResponseBody(resp)
+	result["response_time_ms"] = time.Since(start).Milliseconds()
+	result["http_status"] = resp.StatusCode
+	if err != nil {
+		return result, nil, fmt.Errorf("error al leer respuesta: %w", err)
+	}
+	if resp.StatusCode != expectStatus {
+		return result, nil, fmt.Errorf("HTTP %d, se esperaba %d", resp.StatusCode, expectStatus)
+	}
+
+	var payload interface{}
+	if err := json.Unmarshal([]byte(body), &payload); err != nil {
+		return result, nil, fmt.Errorf("la respuesta no es JSON válido: %w", err)
+	}
+
+	schema, err := findResponseSchema(document, isOpenAPI, endpoint, resp.StatusCode, method, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return result, nil, err
+	}
+
+	return result, validateJSONSchema(document, schema, payload, maxViolations), nil
+}
+
+// findResponseSchema obtiene el schema de respuesta: por SchemaRef o desde la operación OpenAPI
+func findResponseSchema(document interface{}, isOpenAPI bool, endpoint APIContractEndpoint, statusCode int, method string, contentType string) (interface{}, error) {
+	if endpoint.SchemaRef != "" {
+		return resolveJSONPointer(document, endpoint.SchemaRef)
+	}
+	if !isOpenAPI {
+		return document, nil // El archivo completo es el JSON Schema
+	}
+
+	operationPath := endpoint.OperationPath
+	if operationPath == "" {
+		operationPath = endpoint.Path
+	}
+
+	// paths./api/x.get.responses.200.content.application/json.schema
+	operation, err := resolveJSONPointer(document, "/paths/"+escapeJSONPointer(operationPath)+"/"+strings.ToLower(method))
+	operationMap, isObject := operation.(map[string]interface{})
+	if err != nil || !isObject {
+		return nil, fmt.Errorf("operación %s %s no documentada en OpenAPI", method, operationPath)
+	}
+
+	responses, _ := operationMap["responses"].(map[string]interface{})
+	code := strconv.Itoa(statusCode)
+	response, exists := responses[code]
+	if !exists {
+		response, exists = responses[code[:1]+"XX"]
+	}
+	if !exists {
+		response, exists = responses["default"]
+	}
+	if !exists {
+		return nil, fmt.Errorf("respuesta %s no documentada para %s %s", code, method, operationPath)
+	}
+
+	// Las respuestas también pueden ser $ref a components/responses
+	if responseMap, ok := response.(map[string]interface{}); ok {
+		if ref, ok := responseMap["$ref"].(string); ok {
+			if response, err = resolveJSONPointer(document, ref); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	content, _ := resolveJSONPointer(response, "/content")
+	contentMap, _ := content.(map[string]interface{})
+	schema, exists := jsonMediaTypeSchema(contentMap, contentType)
+	if !exists {
+		return nil, fmt.Errorf("la respuesta %s de %s %s no define schema JSON", code, method, operationPath)
+	}
+	return schema, nil
+}
+
+// jsonMediaTypeSchema elige el schema de "content" según el media type, ignorando parámetros
+// ("application/json; charset=utf-8"): primero el de la respuesta recibida, luego application/json
+// y por último cualquier tipo JSON (application/problem+json, application/vnd.x+json)
+func jsonMediaTypeSchema(content map[string]interface{}, responseContentType string) (interface{}, bool) {
+	received, _, _ := mime.ParseMediaType(responseContentType)
+
+	keys := make([]string, 0, len(content))
+	for key := range content {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	bestRank := 0
+	var best interface{}
+	for _, key := range keys {
+		mediaType, _, err := mime.ParseMediaType(key)
+		if err != nil || !isJSONMediaType(mediaType) {
+			continue
+		}
+		mediaObject, _ := content[key].(map[string]interface{})
+		schema, ok := mediaObject["schema"]
+		if !ok {
+			continue
+		}
+		rank := 1
+		switch mediaType {
+		case received:
+			rank = 3
+		case "application/json":
+			rank = 2
+		}
+		if rank > bestRank {
+			bestRank, best = rank, schema
+		}
+	}
+	return best, bestRank > 0
+}
+
+// isJSONMediaType indica si el media type (ya normalizado por mime.ParseMediaType) es JSON
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// loadSchemaDocument lee un documento OpenAPI/JSON Schema en formato JSON
+func loadSchemaDocument(path string) (interface{}, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("%s no es JSON válido (convertir YAML a JSON): %w", path, err)
+	}
+	// Un JSON Schema también puede ser un booleano (true acepta todo, false nada)
+	switch document.(type) {
+	case map[string]interface{}, bool:
+		return document, nil
+	}
+	return nil, fmt.Errorf("%s debe contener un objeto JSON o un schema booleano", path)
+}
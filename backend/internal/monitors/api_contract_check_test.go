@@ -0,0 +1,65 @@
+package monitors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newAPIContractTestConfig levanta un servidor local que responde {"id": 1} y escribe el schema indicado
+func newAPIContractTestConfig(t *testing.T, schema string) APIContractCheckConfig {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, http.StatusOK, map[string]interface{}{"id": 1})
+	}))
+	t.Cleanup(server.Close)
+
+	schemaFile := filepath.Join(t.TempDir(), "schema.json")
+	if err := os.WriteFile(schemaFile, []byte(schema), 0644); err != nil {
+		t.Fatalf("no se pudo escribir el schema: %v", err)
+	}
+
+	return APIContractCheckConfig{
+		CheckID:    "api-contract",
+		CheckName:  "Contrato API",
+		BaseURL:    server.URL,
+		SchemaFile: schemaFile,
+		Endpoints:  []APIContractEndpoint{{Name: "proceso", Path: "/api/procesos/1"}},
+	}
+}
+
+func TestCheckAPIContractBooleanSchema(t *testing.T) {
+	config := newAPIContractTestConfig(t, `true`)
+
+	check := CheckAPIContract(config)
+
+	// El schema true acepta cualquier valor y no es un documento OpenAPI
+	if check.Status != "ok" {
+		t.Errorf("estado esperado ok, obtenido %s (%s)", check.Status, check.Message)
+	}
+	if check.Metadata["schema_kind"] != "json-schema" {
+		t.Errorf("schema_kind esperado json-schema, obtenido %v", check.Metadata["schema_kind"])
+	}
+}
+
+func TestCheckAPIContractNullOperation(t *testing.T) {
+	config := newAPIContractTestConfig(t, `{"openapi": "3.0.3", "paths": {"/api/procesos/1": {"get": null}}}`)
+
+	check := CheckAPIContract(config)
+
+	if check.Status != "error" {
+		t.Fatalf("estado esperado error, obtenido %s (%s)", check.Status, check.Message)
+	}
+	endpoints, _ := check.Metadata["endpoints"].([]map[string]interface{})
+	if len(endpoints) != 1 {
+		t.Fatalf("se esperaba 1 endpoint en la metadata, hay %d", len(endpoints))
+	}
+	message, _ := endpoints[0]["message"].(string)
+	if !strings.Contains(message, "no documentada") {
+		t.Errorf("mensaje esperado de operación no documentada, obtenido %q", message)
+	}
+}
@@ -0,0 +1,288 @@
+package monitors
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/script/v1"
+
+	"github.com/saltacompra/monitor/internal/models"
+)
+
+// AppsScriptCheckConfig configuración para verificar las ejecuciones recientes de un proyecto de Apps Script
+type AppsScriptCheckConfig struct {
+	Auth                 GoogleAuthConfig // La API de Apps Script requiere credenciales de usuario OAuth (authorized_user)
+	CheckID              string
+	CheckName            string
+	ScriptID             string
+	LookbackHours        int // Horas hacia atrás consultadas (default 24)
+	MaxHoursSinceSuccess int // Horas máximas sin una ejecución exitosa para error (0 = no se evalúa)
+	TimeoutSeconds       int
+}
+
+// maxAppsScriptProcesses cantidad máxima de ejecuciones leídas por check
+const maxAppsScriptProcesses = 500
+
+// maxReportedScriptFailures cantidad máxima de ejecuciones fallidas reportadas
+const maxReportedScriptFailures = 10
+
+// scriptServices servicios de Apps Script reutilizados entre ejecuciones (token en caché)
+var scriptServices = struct {
+	sync.Mutex
+	byAuth map[GoogleAuthConfig]*script.Service
+}{byAuth: make(map[GoogleAuthConfig]*script.Service)}
+
+// scriptService retorna el servicio de Apps Script para las credenciales indicadas, creándolo una sola vez
+// Solo pide acceso de lectura a los procesos
+func scriptService(auth GoogleAuthConfig) (*script.Service, error) {
+	// La API no acepta API keys: con una el servicio se crearía pero cada consulta fallaría
+	if auth.AuthMethod == "api_key" {
+		return nil, fmt.Errorf("la Apps Script API no admite API key, se requiere un archivo de credenciales OAuth")
+	}
+
+	scriptServices.Lock()
+	defer scriptServices.Unlock()
+
+	if srv, exists := scriptServices.byAuth[auth]; exists {
+		return srv, nil
+	}
+
+	options, err := googleClientOptions(auth)
+	if err != nil {
+		return nil, err
+	}
+	options = append(options, option.WithScopes(script.ScriptProcessesScope))
+	srv, err := script.NewService(context.Background(), options...)
+	if err != nil {
+		return nil, err
+	}
+	scriptServices.byAuth[auth] = srv
+	return srv, nil
+}
+
+// listScriptProcesses lee las ejecuciones del script iniciadas desde la fecha indicada
+func listScriptProcesses(ctx context.Context, srv *script.Service, scriptID string, since time.Time) ([]*script.GoogleAppsScriptTypeProcess, error) {
+	var processes []*script.GoogleAppsScriptTypeProcess
+	pageToken := ""
+	for len(processes) < maxAppsScriptProcesses {
+		call := srv.Processes.List().
+			UserProcessFilterScriptId(scriptID).
+			UserProcessFilterStartTime(since.UTC().Format(time.RFC3339)).
+			PageSize(100).
+			Context(ctx)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+
+		var resp *script.ListUserProcessesResponse
+		err := callGoogleAPI(ctx, func() (err error) {
+			resp, err = call.Do()
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		processes = append(processes, resp.Processes...)
+		if resp.NextPageToken == "" {
+			break
+		}
+		pageToken = resp.NextPageToken
+	}
+	return processes, nil
+}
+
+// scriptExecution ejecución de Apps Script con la fecha de inicio ya interpretada
+type scriptExecution struct {
+	process *script.GoogleAppsScriptTypeProcess
+	start   time.Time
+}
+
+// CheckAppsScriptExecutions verifica las ejecuciones recientes de un proyecto de Apps Script:
+// cantidad de ejecuciones fallidas o con timeout, última ejecución exitosa y detalle de los fallos
+// La API no expone el texto de la excepción, solo función, tipo, estado, inicio y duración
+func CheckAppsScriptExecutions(config AppsScriptCheckConfig) models.Check {
+	check := models.Check{
+		ID:        config.CheckID,
+		Type:      "apps-script",
+		Name:      config.CheckName,
+		LastCheck: time.Now(),
+		Metadata:  make(map[string]interface{}),
+	}
+	check.Metadata["script_id"] = config.ScriptID
+
+	lookbackHours := config.LookbackHours
+	if lookbackHours <= 0 {
+		lookbackHours = 24
+	}
+	// La ventana debe cubrir el plazo sin éxitos, si no una ejecución exitosa quedaría fuera
+	if config.MaxHoursSinceSuccess > lookbackHours {
+		lookbackHours = config.MaxHoursSinceSuccess
+	}
+	check.Metadata["lookback_hours"] = lookbackHours
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(config.TimeoutSeconds))
+	defer cancel()
+
+	start := time.Now()
+	srv, err := scriptService(config.Auth)
+	if err != nil {
+		check.Status = "error"
+		check.Message = "Error al conectar con Apps Script API: " + err.Error()
+		check.Metadata["error_type"] = "connection_failed"
+		return check
+	}
+
+	now := time.Now()
+	processes, err := listScriptProcesses(ctx, srv, config.ScriptID, now.Add(-time.Duration(lookbackHours)*time.Hour))
+	check.ResponseTime = time.Since(start).Milliseconds()
+	if err != nil {
+		errorType := googleErrorType(err)
+		check.Status = "error"
+		check.Metadata["error_type"] = errorType
+		if errorType == "quota_exceeded" {
+			check.Message = "Cuota de Apps Script API excedida: " + err.Error()
+		} else {
+			check.Message = "Error al consultar ejecuciones de Apps Script: " + err.Error()
+		}
+		return check
+	}
+
+	// Ejecuciones más recientes primero
+	executions := make([]scriptExecution, 0, len(processes))
+	for _, process := range processes {
+		started, err := time.Parse(time.RFC3339Nano, process.StartTime)
+		if err != nil {
+			continue
+		}
+		executions = append(executions, scriptExecution{process: process, start: started})
+	}
+	sort.SliceStable(executions, func(i, j int) bool { return executions[i].start.After(executions[j].start) })
+
+	counts := make(map[string]int)
+	var failures []map[string]interface{}
+	var lastSuccess time.Time
+	lastStatusByFunction := make(map[string]string)
+	for _, execution := range executions {
+		process := execution.process
+		counts[process.ProcessStatus]++
+		if process.ProjectName != "" && check.Metadata["project_name"] == nil {
+			check.Metadata["project_name"] = process.ProjectName
+		}
+
+		switch process.ProcessStatus {
+		case "COMPLETED":
+			if lastSuccess.IsZero() {
+				lastSuccess = execution.start
+			}
+		case "FAILED", "TIMED_OUT":
+			if len(failures) < maxReportedScriptFailures {
+				failures = append(failures, map[string]interface{}{
+					"function":   process.FunctionName,
+					"type":       process.ProcessType,
+					"status":     process.ProcessStatus,
+					"start_time": execution.start.Format(time.RFC3339),
+					"duration":   process.Duration,
+					"message": fmt.Sprintf("%s %s el %s", scriptFunctionLabel(process),
+						scriptStatusLabel(process.ProcessStatus), execution.start.In(time.Local).Format("2006-01-02 15:04")),
+				})
+			}
+		default:
+			continue
+		}
+
+		// Último resultado terminado de cada función
+		if _, seen := lastStatusByFunction[process.FunctionName]; !seen {
+			lastStatusByFunction[process.FunctionName] = process.ProcessStatus
+		}
+	}
+
+	failed := counts["FAILED"]
+	timedOut := counts["TIMED_OUT"]
+	check.Metadata["executions"] = len(executions)
+	check.Metadata["completed"] = counts["COMPLETED"]
+	check.Metadata["failed"] = failed
+	check.Metadata["timed_out"] = timedOut
+	check.Metadata["running"] = counts["RUNNING"] + counts["PAUSED"] + counts["DELAYED"]
+	check.Metadata["canceled"] = counts["CANCELED"]
+	check.Metadata["failures"] = failures
+	if !lastSuccess.IsZero() {
+		check.Metadata["last_success"] = lastSuccess.Format(time.RFC3339)
+		check.Metadata["hours_since_success"] = roundMetric(now.Sub(lastSuccess).Hours())
+	}
+
+	status := "ok"
+	var problems []string
+	raise := func(newStatus string, problem string) {
+		problems = append(problems, problem)
+		if newStatus == "error" || status == "ok" {
+			status = newStatus
+		}
+	}
+
+	if counts["EXECUTION_DISABLED"] > 0 {
+		raise("error", "las ejecuciones están deshabilitadas por el administrador")
+	}
+
+	if len(executions) == 0 {
+		raise("warning", fmt.Sprintf("sin ejecuciones en las últimas %d h", lookbackHours))
+	}
+
+	// Funciones cuya última ejecución terminada falló
+	var failingFunctions []string
+	for function, lastStatus := range lastStatusByFunction {
+		if lastStatus == "FAILED" || lastStatus == "TIMED_OUT" {
+			failingFunctions = append(failingFunctions, function)
+		}
+	}
+	sort.Strings(failingFunctions)
+	check.Metadata["failing_functions"] = failingFunctions
+
+	if failed+timedOut > 0 {
+		problem := fmt.Sprintf("%d ejecución(es) fallidas y %d con timeout en las últimas %d h", failed, timedOut, lookbackHours)
+		if len(failingFunctions) > 0 {
+			raise("error", fmt.Sprintf("%s (última ejecución fallida: %s)", problem, strings.Join(failingFunctions, ", ")))
+		} else {
+			raise("warning", problem)
+		}
+	}
+
+	if config.MaxHoursSinceSuccess > 0 && len(executions) > 0 {
+		if lastSuccess.IsZero() {
+			raise("error", fmt.Sprintf("sin ejecuciones exitosas en las últimas %d h", lookbackHours))
+		} else if now.Sub(lastSuccess) > time.Duration(config.MaxHoursSinceSuccess)*time.Hour {
+			raise("error", fmt.Sprintf("última ejecución exitosa hace %.0f h", now.Sub(lastSuccess).Hours()))
+		}
+	}
+
+	check.Status = status
+	if len(problems) > 0 {
+		check.Message = "Apps Script: " + strings.Join(problems, "; ")
+		return check
+	}
+	check.Message = fmt.Sprintf("%d ejecución(es) en las últimas %d h sin fallos", len(executions), lookbackHours)
+	if !lastSuccess.IsZero() {
+		check.Message += ", última exitosa " + lastSuccess.In(time.Local).Format("2006-01-02 15:04")
+	}
+	return check
+}
+
+// scriptStatusLabel descripción del estado de una ejecución fallida
+func scriptStatusLabel(status string) string {
+	if status == "TIMED_OUT" {
+		return "excedió el tiempo límite"
+	}
+	return "falló"
+}
+
+// scriptFunctionLabel nombre de la función ejecutada con el origen de la ejecución si se conoce
+func scriptFunctionLabel(process *script.GoogleAppsScriptTypeProcess) string {
+	if process.ProcessType == "" {
+		return process.FunctionName
+	}
+	return fmt.Sprintf("%s (%s)", process.FunctionName, process.ProcessType)
+}
@@ -0,0 +1,188 @@
+package monitors
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newAppsScriptTestServer levanta un servidor local que reemplaza a la API de Apps Script
+// handler recibe el número de petición (desde 0) y escribe la respuesta
+func newAppsScriptTestServer(t *testing.T, handler func(w http.ResponseWriter, r *http.Request, request int)) (AppsScriptCheckConfig, *[]*http.Request) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if serveGoogleToken(t, w, r) {
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+			t.Errorf("Authorization inesperado: %q", got)
+		}
+		mu.Lock()
+		requests = append(requests, r)
+		request := len(requests) - 1
+		mu.Unlock()
+		handler(w, r, request)
+	}))
+	t.Cleanup(server.Close)
+
+	config := AppsScriptCheckConfig{
+		// Cada test usa su propio endpoint, por lo que no comparte el servicio en caché
+		Auth:                 GoogleAuthConfig{CredentialsFile: writeGoogleTestCredentials(t, server.URL), Endpoint: server.URL + "/"},
+		CheckID:              "kairos-apps-script",
+		CheckName:            "Ejecuciones Apps Script Kairos",
+		ScriptID:             "script-123",
+		LookbackHours:        24,
+		MaxHoursSinceSuccess: 26,
+	}
+	return config, &requests
+}
+
+// writeJSON responde con el valor serializado como JSON
+func writeJSON(t *testing.T, w http.ResponseWriter, status int, value interface{}) {
+	t.Helper()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		t.Errorf("no se pudo escribir la respuesta: %v", err)
+	}
+}
+
+// scriptProcess arma una ejecución con el formato de la API
+func scriptProcess(function string, status string, start time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"projectName":   "Kairos",
+		"functionName":  function,
+		"processType":   "TIME_DRIVEN",
+		"processStatus": status,
+		"startTime":     start.UTC().Format(time.RFC3339Nano),
+		"duration":      "12.5s",
+	}
+}
+
+func TestCheckAppsScriptExecutionsPaginates(t *testing.T) {
+	now := time.Now()
+	config, requests := newAppsScriptTestServer(t, func(w http.ResponseWriter, r *http.Request, request int) {
+		switch request {
+		case 0:
+			writeJSON(t, w, http.StatusOK, map[string]interface{}{
+				"processes": []interface{}{
+					scriptProcess("main", "COMPLETED", now.Add(-1*time.Hour)),
+					scriptProcess("sync", "FAILED", now.Add(-2*time.Hour)),
+				},
+				"nextPageToken": "page-2",
+			})
+		default:
+			writeJSON(t, w, http.StatusOK, map[string]interface{}{
+				"processes": []interface{}{
+					scriptProcess("sync", "COMPLETED", now.Add(-3*time.Hour)),
+				},
+			})
+		}
+	})
+
+	check := CheckAppsScriptExecutions(config)
+
+	if len(*requests) != 2 {
+		t.Fatalf("se esperaban 2 peticiones (una por página), hubo %d", len(*requests))
+	}
+	first, second := (*requests)[0], (*requests)[1]
+	if first.URL.Path != "/v1/processes" {
+		t.Errorf("path inesperado: %s", first.URL.Path)
+	}
+	if got := first.URL.Query().Get("userProcessFilter.scriptId"); got != "script-123" {
+		t.Errorf("filtro de script inesperado: %q", got)
+	}
+	if got := first.URL.Query().Get("pageToken"); got != "" {
+		t.Errorf("la primera página no debe enviar pageToken, envió %q", got)
+	}
+	if got := second.URL.Query().Get("pageToken"); got != "page-2" {
+		t.Errorf("la segunda página debe enviar pageToken=page-2, envió %q", got)
+	}
+
+	// La última ejecución de sync falló: es error aunque una anterior haya terminado bien
+	if check.Status != "error" {
+		t.Errorf("estado esperado error, obtenido %s (%s)", check.Status, check.Message)
+	}
+	if got := check.Metadata["executions"]; got != 3 {
+		t.Errorf("executions esperado 3, obtenido %v", got)
+	}
+	if got := check.Metadata["failed"]; got != 1 {
+		t.Errorf("failed esperado 1, obtenido %v", got)
+	}
+	if got, _ := check.Metadata["failing_functions"].([]string); len(got) != 1 || got[0] != "sync" {
+		t.Errorf("failing_functions esperado [sync], obtenido %v", check.Metadata["failing_functions"])
+	}
+	if check.Metadata["project_name"] != "Kairos" {
+		t.Errorf("project_name esperado Kairos, obtenido %v", check.Metadata["project_name"])
+	}
+}
+
+func TestCheckAppsScriptExecutionsOK(t *testing.T) {
+	now := time.Now()
+	config, _ := newAppsScriptTestServer(t, func(w http.ResponseWriter, r *http.Request, request int) {
+		writeJSON(t, w, http.StatusOK, map[string]interface{}{
+			"processes": []interface{}{
+				scriptProcess("main", "COMPLETED", now.Add(-1*time.Hour)),
+				scriptProcess("main", "FAILED", now.Add(-5*time.Hour)),
+			},
+		})
+	})
+
+	check := CheckAppsScriptExecutions(config)
+
+	// Un fallo ya superado por una ejecución exitosa de la misma función es warning
+	if check.Status != "warning" {
+		t.Errorf("estado esperado warning, obtenido %s (%s)", check.Status, check.Message)
+	}
+	if _, exists := check.Metadata["last_success"]; !exists {
+		t.Error("falta last_success en la metadata")
+	}
+}
+
+func TestCheckAppsScriptExecutionsQuotaExceeded(t *testing.T) {
+	config, requests := newAppsScriptTestServer(t, func(w http.ResponseWriter, r *http.Request, request int) {
+		writeJSON(t, w, http.StatusForbidden, map[string]interface{}{
+			"error": map[string]interface{}{
+				"code":    http.StatusForbidden,
+				"message": "Quota exceeded",
+				"errors":  []interface{}{map[string]interface{}{"reason": "rateLimitExceeded", "message": "Quota exceeded"}},
+			},
+		})
+	})
+
+	check := CheckAppsScriptExecutions(config)
+
+	if check.Status != "error" {
+		t.Errorf("estado esperado error, obtenido %s", check.Status)
+	}
+	if check.Metadata["error_type"] != "quota_exceeded" {
+		t.Errorf("error_type esperado quota_exceeded, obtenido %v", check.Metadata["error_type"])
+	}
+	// Un 403 no se reintenta
+	if len(*requests) != 1 {
+		t.Errorf("se esperaba 1 petición, hubo %d", len(*requests))
+	}
+}
+
+func TestCheckAppsScriptExecutionsRejectsAPIKey(t *testing.T) {
+	config, requests := newAppsScriptTestServer(t, func(w http.ResponseWriter, r *http.Request, request int) {
+		writeJSON(t, w, http.StatusOK, map[string]interface{}{})
+	})
+	config.Auth = GoogleAuthConfig{AuthMethod: "api_key", APIKey: "test", Endpoint: config.Auth.Endpoint}
+
+	check := CheckAppsScriptExecutions(config)
+
+	if check.Status != "error" || !strings.Contains(check.Message, "API key") {
+		t.Errorf("se esperaba el rechazo de la API key, obtenido %s (%s)", check.Status, check.Message)
+	}
+	if len(*requests) != 0 {
+		t.Errorf("no se esperaban peticiones a la API, hubo %d", len(*requests))
+	}
+}
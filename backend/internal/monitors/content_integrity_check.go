@@ -0,0 +1,349 @@
+package monitors
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+
+	"github.com/saltacompra/monitor/internal/models"
+)
+
+// StateStore persiste estado entre ejecuciones de checks (baselines, snapshots)
+type StateStore interface {
+	Get(key string, v interface{}) (bool, error)
+	Put(key string, v interface{}) error
+}
+
+// ContentIntegrityCheckConfig contiene la configuración para detectar cambios de contenido
+type ContentIntegrityCheckConfig struct {
+	URL                 string
+	CheckID             string
+	CheckName           string
+	BaselineKey         string   // Clave del baseline en el StateStore (sistema/check)
+	IgnoreSelectors     []string // Selectores de elementos dinámicos a descartar (tag, .clase, #id, [attr], tag.clase)
+	IgnorePatterns      []string // Regex a eliminar del contenido normalizado (fechas, contadores)
+	ChangeStatus        string   // Estado ante cambios no aprobados: "warning" o "error" (default)
+	SkipSSLVerification bool
+	TimeoutSeconds      int
+}
+
+// ContentSnapshot contenido normalizado de una página y su hash
+type ContentSnapshot struct {
+	Hash       string    `json:"hash"`
+	Content    string    `json:"content"`
+	CapturedAt time.Time `json:"captured_at"`
+}
+
+// maxDiffSampleLines cantidad máxima de líneas de ejemplo en el resumen de diferencias
+const maxDiffSampleLines = 10
+
+// CheckContentIntegrity compara el contenido normalizado de una página contra su baseline aprobado
+// Si no hay baseline, el contenido actual se registra como baseline inicial
+func CheckContentIntegrity(config ContentIntegrityCheckConfig, store StateStore) models.Check {
+	check := models.Check{
+		ID:        config.CheckID,
+		Type:      "content-integrity",
+		Name:      config.CheckName,
+		LastCheck: time.Now(),
+		Metadata:  make(map[string]interface{}),
+	}
+
+	timeout := config.TimeoutSeconds
+	if timeout == 0 {
+		timeout = 30 // Default 30 segundos
+	}
+	client := getHTTPClient(timeout, config.SkipSSLVerification)
+
+	start := time.Now()
+	resp, err := client.Get(config.URL)
+	if err != nil {
+		check.ResponseTime = time.Since(start).Milliseconds()
+		check.Status = "error"
+		check.Message = "No se pudo conectar: " + err.Error()
+		return check
+	}
+	defer resp.Body.Close()
+
+	body, err := readResponseBody(resp)
+	check.ResponseTime = time.Since(start).Milliseconds()
+	if err != nil {
+		check.Status = "error"
+		check.Message = "Error al leer contenido: " + err.Error()
+		return check
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		check.Status = "error"
+		check.Message = fmt.Sprintf("Código HTTP inesperado: %d", resp.StatusCode)
+		return check
+	}
+
+	normalized, err := normalizePageContent(body, config.IgnoreSelectors, config.IgnorePatterns)
+	if err != nil {
+		check.Status = "error"
+		check.Message = "Error al normalizar contenido: " + err.Error()
+		return check
+	}
+
+	current := ContentSnapshot{
+		Hash:       hashContent(normalized),
+		Content:    normalized,
+		CapturedAt: time.Now(),
+	}
+	check.Metadata["content_hash"] = current.Hash
+
+	// Guardar siempre lo observado para poder aprobarlo como nuevo baseline
+	if err := store.Put(observedContentKey(config.BaselineKey), current); err != nil {
+		check.Status = "error"
+		check.Message = "Error al guardar contenido observado: " + err.Error()
+		return check
+	}
+
+	var baseline ContentSnapshot
+	found, err := store.Get(approvedContentKey(config.BaselineKey), &baseline)
+	if err != nil {
+		check.Status = "error"
+		check.Message = "Error al leer baseline: " + err.Error()
+		return check
+	}
+
+	if !found {
+		if err := store.Put(approvedContentKey(config.BaselineKey), current); err != nil {
+			check.Status = "error"
+			check.Message = "Error al registrar baseline inicial: " + err.Error()
+			return check
+		}
+		check.Status = "ok"
+		check.Message = "Baseline inicial registrado (hash " + current.Hash[:12] + ")"
+		check.Metadata["baseline_hash"] = current.Hash
+		check.Metadata["baseline_approved_at"] = current.CapturedAt.Format(time.RFC3339)
+		return check
+	}
+
+	check.Metadata["baseline_hash"] = baseline.Hash
+	check.Metadata["baseline_approved_at"] = baseline.CapturedAt.Format(time.RFC3339)
+
+	if baseline.Hash == current.Hash {
+		check.Status = "ok"
+		check.Message = "Contenido sin cambios respecto al baseline aprobado"
+		return check
+	}
+
+	// Contenido distinto: resumir diferencias
+	added, removed := diffContentLines(baseline.Content, current.Content)
+	check.Metadata["content_changed"] = true
+	check.Metadata["lines_added"] = len(added)
+	check.Metadata["lines_removed"] = len(removed)
+	check.Metadata["sample_added"] = limitLines(added, maxDiffSampleLines)
+	check.Metadata["sample_removed"] = limitLines(removed, maxDiffSampleLines)
+
+	check.Status = "error"
+	if config.ChangeStatus == "warning" {
+		check.Status = "warning"
+	}
+	check.Message = fmt.Sprintf("El contenido cambió respecto al baseline aprobado (+%d/-%d líneas). Aprobar si fue un despliegue legítimo",
+		len(added), len(removed))
+
+	return check
+}
+
+// AcceptContentBaseline aprueba el último contenido observado como nuevo baseline
+func AcceptContentBaseline(store StateStore, baselineKey string) (ContentSnapshot, error) {
+	var observed ContentSnapshot
+	found, err := store.Get(observedContentKey(baselineKey), &observed)
+	if err != nil {
+		return observed, err
+	}
+	if !found {
+		return observed, fmt.Errorf("no hay contenido observado para %s", baselineKey)
+	}
+
+	return observed, store.Put(approvedContentKey(baselineKey), observed)
+}
+
+// approvedContentKey clave del baseline aprobado en el StateStore
+func approvedContentKey(baselineKey string) string {
+	return "content-baseline/" + baselineKey
+}
+
+// observedContentKey clave del último contenido observado en el StateStore
+func observedContentKey(baselineKey string) string {
+	return "content-observed/" + baselineKey
+}
+
+// normalizePageContent convierte el HTML en una representación estable línea por línea:
+// texto visible y atributos relevantes (src, href, action), sin comentarios ni elementos ignorados
+func normalizePageContent(body string, ignoreSelectors []string, ignorePatterns []string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+
+	selectors := make([]simpleSelector, 0, len(ignoreSelectors))
+	for _, s := range ignoreSelectors {
+		selectors = append(selectors, parseSimpleSelector(s))
+	}
+
+	patterns := make([]*regexp.Regexp, 0, len(ignorePatterns))
+	for _, p := range ignorePatterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return "", fmt.Errorf("regex inválida %q: %w", p, err)
+		}
+		patterns = append(patterns, re)
+	}
+
+	var lines []string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.CommentNode:
+			return
+		case html.ElementNode:
+			for _, sel := range selectors {
+				if sel.matches(n) {
+					return
+				}
+			}
+			for _, attr := range n.Attr {
+				if attr.Key == "src" || attr.Key == "href" || attr.Key == "action" {
+					lines = append(lines, fmt.Sprintf("<%s %s=%s>", n.Data, attr.Key, attr.Val))
+				}
+			}
+		case html.TextNode:
+			text := strings.Join(strings.Fields(n.Data), " ")
+			if text != "" {
+				lines = append(lines, text)
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+
+	normalized := strings.Join(lines, "\n")
+	for _, re := range patterns {
+		normalized = re.ReplaceAllString(normalized, "")
+	}
+	return normalized, nil
+}
+
+// simpleSelector selector CSS reducido: tag, .clase, #id, [attr], [attr=valor] y combinaciones tag.clase/tag#id
+type simpleSelector struct {
+	tag       string
+	id        string
+	class     string
+	attr      string
+	attrValue string
+}
+
+// selectorPattern descompone un selector simple en sus partes
+var selectorPattern = regexp.MustCompile(`^([a-zA-Z0-9-]*)(?:#([\w-]+))?(?:\.([\w-]+))?(?:\[([\w-]+)(?:=["']?([^"'\]]*)["']?)?\])?$`)
+
+// parseSimpleSelector interpreta un selector simple (los no reconocidos no coinciden con nada)
+func parseSimpleSelector(selector string) simpleSelector {
+	matches := selectorPattern.FindStringSubmatch(strings.TrimSpace(selector))
+	if matches == nil {
+		return simpleSelector{tag: "\x00"}
+	}
+	return simpleSelector{
+		tag:       strings.ToLower(matches[1]),
+		id:        matches[2],
+		class:     matches[3],
+		attr:      strings.ToLower(matches[4]),
+		attrValue: matches[5],
+	}
+}
+
+// matches indica si un elemento HTML coincide con el selector
+func (s simpleSelector) matches(n *html.Node) bool {
+	if s.tag != "" && s.tag != n.Data {
+		return false
+	}
+
+	attrs := make(map[string]string, len(n.Attr))
+	for _, attr := range n.Attr {
+		attrs[attr.Key] = attr.Val
+	}
+
+	if s.id != "" && attrs["id"] != s.id {
+		return false
+	}
+	if s.class != "" {
+		found := false
+		for _, class := range strings.Fields(attrs["class"]) {
+			if class == s.class {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if s.attr != "" {
+		value, exists := attrs[s.attr]
+		if !exists || (s.attrValue != "" && value != s.attrValue) {
+			return false
+		}
+	}
+
+	return s.tag != "" || s.id != "" || s.class != "" || s.attr != ""
+}
+
+// hashContent calcula el SHA-256 del contenido normalizado
+func hashContent(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// diffContentLines compara dos contenidos como multiconjuntos de líneas
+// Retorna las líneas agregadas y eliminadas en el orden en que aparecen
+func diffContentLines(before string, after string) (added []string, removed []string) {
+	beforeCount := make(map[string]int)
+	for _, line := range strings.Split(before, "\n") {
+		beforeCount[line]++
+	}
+	afterCount := make(map[string]int)
+	for _, line := range strings.Split(after, "\n") {
+		afterCount[line]++
+	}
+
+	for _, line := range strings.Split(after, "\n") {
+		if beforeCount[line] > 0 {
+			beforeCount[line]--
+			continue
+		}
+		added = append(added, line)
+	}
+	for _, line := range strings.Split(before, "\n") {
+		if afterCount[line] > 0 {
+			afterCount[line]--
+			continue
+		}
+		removed = append(removed, line)
+	}
+
+	return added, removed
+}
+
+// limitLines retorna como máximo n líneas (recortando líneas muy largas sin partir caracteres UTF-8)
+func limitLines(lines []string, n int) []string {
+	if len(lines) > n {
+		lines = lines[:n]
+	}
+	result := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if runes := []rune(line); len(runes) > 200 {
+			line = string(runes[:200]) + "…"
+		}
+		result = append(result, line)
+	}
+	return result
+}
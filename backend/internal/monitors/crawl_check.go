@@ -0,0 +1,344 @@
+package monitors
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+
+	"github.com/saltacompra/monitor/internal/models"
+)
+
+// CrawlCheckConfig contiene la configuración para el crawler de enlaces rotos
+type CrawlCheckConfig struct {
+	StartURL            string
+	CheckID             string
+	CheckName           string
+	MaxDepth            int   // Profundidad máxima de navegación desde StartURL
+	MaxPages            int   // Presupuesto máximo de URLs a solicitar (páginas + recursos)
+	DelayMs             int   // Pausa entre peticiones para no cargar el sitio productivo
+	SlowPageMs          int64 // Umbral de ms para reportar una página lenta
+	CheckExternal       bool  // Verificar también enlaces a otros dominios (sin seguirlos)
+	SkipSSLVerification bool
+	TimeoutSeconds      int
+}
+
+// crawlTarget URL pendiente de visitar
+type crawlTarget struct {
+	url      string
+	referrer string
+	depth    int
+	asset    bool // Recurso (img, script, css): se verifica pero no se parsea
+}
+
+// crawlResult resultado de la visita a una URL
+type crawlResult struct {
+	statusCode int
+	elapsedMs  int64
+	err        error
+}
+
+// maxReportedLinks cantidad máxima de URLs por categoría en metadata
+const maxReportedLinks = 50
+
+// CheckCrawl recorre enlaces del mismo origen desde StartURL reportando enlaces rotos,
+// recursos faltantes y páginas lentas
+// Respeta robots.txt del origen; los enlaces externos solo se verifican (una petición HEAD), no se recorren
+func CheckCrawl(config CrawlCheckConfig) models.Check {
+	check := models.Check{
+		ID:        config.CheckID,
+		Type:      "crawl",
+		Name:      config.CheckName,
+		LastCheck: time.Now(),
+		Metadata:  make(map[string]interface{}),
+	}
+
+	startURL, err := url.Parse(config.StartURL)
+	if err != nil || startURL.Host == "" {
+		check.Status = "error"
+		check.Message = "URL inicial inválida: " + config.StartURL
+		return check
+	}
+
+	timeout := config.TimeoutSeconds
+	if timeout == 0 {
+		timeout = 30 // Default 30 segundos
+	}
+	maxPages := config.MaxPages
+	if maxPages == 0 {
+		maxPages = 100
+	}
+	client := getHTTPClient(timeout, config.SkipSSLVerification)
+
+	// Se respeta el robots.txt del sitio: las URLs prohibidas no se visitan y Crawl-delay
+	// reemplaza a DelayMs si es mayor
+	robots, err := fetchRobotsRules(client, startURL)
+	if err != nil {
+		check.Status = "warning"
+		check.Message = "No se recorrió el sitio: no se pudo leer robots.txt (" + err.Error() + ")"
+		check.Metadata["robots_error"] = err.Error()
+		return check
+	}
+	delay := time.Duration(config.DelayMs) * time.Millisecond
+	if robots.crawlDelay > delay {
+		delay = robots.crawlDelay
+	}
+
+	queue := []crawlTarget{{url: normalizeCrawlURL(startURL), depth: 0}}
+	seen := map[string]bool{queue[0].url: true}
+	referrers := make(map[string][]string)
+
+	var brokenLinks, missingAssets, slowPages, unreachable []map[string]interface{}
+	pagesVisited, assetsChecked, robotsSkipped := 0, 0, 0
+	start := time.Now()
+
+	for len(queue) > 0 && pagesVisited+assetsChecked < maxPages {
+		target := queue[0]
+		queue = queue[1:]
+
+		sameOrigin := isSameOrigin(startURL, target.url)
+		if sameOrigin && !robots.allowed(target.url) {
+			robotsSkipped++
+			continue
+		}
+
+		if pagesVisited+assetsChecked > 0 && delay > 0 {
+			time.Sleep(delay)
+		}
+
+		parsePage := sameOrigin && !target.asset && target.depth < config.MaxDepth
+		result, body := fetchCrawlTarget(client, target.url, parsePage)
+
+		if target.asset {
+			assetsChecked++
+		} else {
+			pagesVisited++
+		}
+
+		entry := map[string]interface{}{
+			"url":      target.url,
+			"referrer": target.referrer,
+		}
+
+		switch {
+		case result.err != nil:
+			entry["error"] = result.err.Error()
+			unreachable = append(unreachable, entry)
+			continue
+		case result.statusCode >= 400 && target.asset:
+			entry["http_status"] = result.statusCode
+			missingAssets = append(missingAssets, entry)
+			continue
+		case result.statusCode >= 400:
+			entry["http_status"] = result.statusCode
+			brokenLinks = append(brokenLinks, entry)
+			continue
+		}
+
+		if !target.asset && sameOrigin && config.SlowPageMs > 0 && result.elapsedMs >= config.SlowPageMs {
+			entry["response_time_ms"] = result.elapsedMs
+			slowPages = append(slowPages, entry)
+		}
+
+		if body == "" {
+			continue
+		}
+
+		// Encolar enlaces y recursos encontrados en la página
+		base, _ := url.Parse(target.url)
+		for _, link := range extractCrawlLinks(body) {
+			ref, err := url.Parse(link.href)
+			if err != nil {
+				continue
+			}
+			resolved := base.ResolveReference(ref)
+			if resolved.Scheme != "http" && resolved.Scheme != "https" {
+				continue
+			}
+			linkURL := normalizeCrawlURL(resolved)
+
+			if len(referrers[linkURL]) < 5 {
+				referrers[linkURL] = append(referrers[linkURL], target.url)
+			}
+			if seen[linkURL] {
+				continue
+			}
+			if !isSameOrigin(startURL, linkURL) && !config.CheckExternal {
+				continue
+			}
+			seen[linkURL] = true
+			queue = append(queue, crawlTarget{
+				url:      linkURL,
+				referrer: target.url,
+				depth:    target.depth + 1,
+				asset:    link.asset,
+			})
+		}
+	}
+
+	check.ResponseTime = time.Since(start).Milliseconds()
+
+	// Agregar todas las páginas que referencian cada URL problemática
+	for _, list := range [][]map[string]interface{}{brokenLinks, missingAssets, unreachable} {
+		for _, entry := range list {
+			entry["referrers"] = referrers[entry["url"].(string)]
+		}
+	}
+
+	check.Metadata["pages_visited"] = pagesVisited
+	check.Metadata["assets_checked"] = assetsChecked
+	check.Metadata["robots_skipped"] = robotsSkipped
+	check.Metadata["robots_crawl_delay_ms"] = robots.crawlDelay.Milliseconds()
+	check.Metadata["urls_pending"] = len(queue)
+	check.Metadata["budget_exhausted"] = len(queue) > 0
+	check.Metadata["broken_links_count"] = len(brokenLinks)
+	check.Metadata["missing_assets_count"] = len(missingAssets)
+	check.Metadata["unreachable_count"] = len(unreachable)
+	check.Metadata["slow_pages_count"] = len(slowPages)
+	check.Metadata["broken_links"] = limitCrawlEntries(brokenLinks)
+	check.Metadata["missing_assets"] = limitCrawlEntries(missingAssets)
+	check.Metadata["unreachable_links"] = limitCrawlEntries(unreachable)
+	check.Metadata["slow_pages"] = limitCrawlEntries(slowPages)
+
+	problems := len(brokenLinks) + len(missingAssets) + len(unreachable)
+	switch {
+	case len(brokenLinks) > 0 || len(unreachable) > 0:
+		check.Status = "error"
+		check.Message = fmt.Sprintf("%d enlaces rotos, %d sin respuesta y %d recursos faltantes en %d páginas recorridas",
+			len(brokenLinks), len(unreachable), len(missingAssets), pagesVisited)
+	case problems > 0 || len(slowPages) > 0:
+		check.Status = "warning"
+		check.Message = fmt.Sprintf("%d recursos faltantes y %d páginas lentas en %d páginas recorridas",
+			len(missingAssets), len(slowPages), pagesVisited)
+	default:
+		check.Status = "ok"
+		check.Message = fmt.Sprintf("Sin enlaces rotos: %d páginas y %d recursos verificados (%dms)",
+			pagesVisited, assetsChecked, check.ResponseTime)
+	}
+
+	return check
+}
+
+// fetchCrawlTarget solicita una URL; si parseBody es true y la respuesta es HTML retorna el body
+func fetchCrawlTarget(client *http.Client, target string, parseBody bool) (crawlResult, string) {
+	method := http.MethodGet
+	if !parseBody {
+		method = http.MethodHead
+	}
+
+	start := time.Now()
+	resp, err := crawlRequest(client, method, target)
+	// Algunos servidores no implementan HEAD: reintentar con GET
+	if err == nil && method == http.MethodHead && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		resp.Body.Close()
+		resp, err = crawlRequest(client, http.MethodGet, target)
+	}
+	if err != nil {
+		return crawlResult{err: err, elapsedMs: time.Since(start).Milliseconds()}, ""
+	}
+	defer resp.Body.Close()
+
+	result := crawlResult{statusCode: resp.StatusCode}
+	body := ""
+	if parseBody && resp.StatusCode < 400 && strings.Contains(resp.Header.Get("Content-Type"), "text/html") {
+		body, _ = readResponseBody(resp)
+	}
+	result.elapsedMs = time.Since(start).Milliseconds()
+
+	return result, body
+}
+
+// crawlRequest realiza una petición identificándose como el monitor
+func crawlRequest(client *http.Client, method string, target string) (*http.Response, error) {
+	req, err := http.NewRequest(method, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "SaltaCompra-Monitor/1.0 (crawler de enlaces)")
+	return client.Do(req)
+}
+
+// crawlLink enlace encontrado en una página
+type crawlLink struct {
+	href  string
+	asset bool
+}
+
+// extractCrawlLinks obtiene enlaces (a[href]) y recursos (img, script, link, iframe) de un HTML
+func extractCrawlLinks(body string) []crawlLink {
+	doc, err := html.Parse(strings.NewReader(body))
+	if err != nil {
+		return nil
+	}
+
+	var links []crawlLink
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "a":
+				if href := nodeAttr(n, "href"); href != "" && !strings.HasPrefix(href, "#") {
+					links = append(links, crawlLink{href: href})
+				}
+			case "img", "script", "iframe":
+				if src := nodeAttr(n, "src"); src != "" {
+					links = append(links, crawlLink{href: src, asset: true})
+				}
+			case "link":
+				if href := nodeAttr(n, "href"); href != "" && nodeAttr(n, "rel") == "stylesheet" {
+					links = append(links, crawlLink{href: href, asset: true})
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+
+	return links
+}
+
+// nodeAttr obtiene un atributo de un nodo HTML
+func nodeAttr(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return strings.TrimSpace(attr.Val)
+		}
+	}
+	return ""
+}
+
+// normalizeCrawlURL elimina el fragmento y completa el path raíz para no visitar la misma página dos veces
+func normalizeCrawlURL(u *url.URL) string {
+	normalized := *u
+	normalized.Fragment = ""
+	if normalized.Path == "" {
+		normalized.Path = "/"
+	}
+	return normalized.String()
+}
+
+// isSameOrigin indica si la URL tiene el mismo esquema y host que el origen
+func isSameOrigin(origin *url.URL, target string) bool {
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+	return parsed.Scheme == origin.Scheme && strings.EqualFold(parsed.Host, origin.Host)
+}
+
+// limitCrawlEntries ordena por URL y limita la cantidad de entradas reportadas
+func limitCrawlEntries(entries []map[string]interface{}) []map[string]interface{} {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i]["url"].(string) < entries[j]["url"].(string)
+	})
+	if len(entries) > maxReportedLinks {
+		return entries[:maxReportedLinks]
+	}
+	return entries
+}
@@ -0,0 +1,158 @@
+package monitors
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// crawlUserAgentToken token con el que el crawler se identifica en robots.txt
+const crawlUserAgentToken = "saltacompra-monitor"
+
+// robotsRule regla Allow/Disallow de robots.txt
+type robotsRule struct {
+	pattern string
+	allow   bool
+}
+
+// robotsRules reglas de robots.txt aplicables al crawler (RFC 9309)
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// fetchRobotsRules lee el robots.txt del origen
+// Según RFC 9309 un 4xx equivale a "sin restricciones"; un 5xx o un error de red
+// obligan a asumir que todo está prohibido, por eso se retorna error y no se recorre el sitio
+func fetchRobotsRules(client *http.Client, origin *url.URL) (robotsRules, error) {
+	robotsURL := url.URL{Scheme: origin.Scheme, Host: origin.Host, Path: "/robots.txt"}
+	resp, err := crawlRequest(client, http.MethodGet, robotsURL.String())
+	if err != nil {
+		return robotsRules{}, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return robotsRules{}, fmt.Errorf("robots.txt respondió HTTP %d", resp.StatusCode)
+	case resp.StatusCode >= 400:
+		return robotsRules{}, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 500*1024))
+	if err != nil {
+		return robotsRules{}, err
+	}
+	return parseRobotsRules(string(body)), nil
+}
+
+// parseRobotsRules extrae las reglas del grupo del crawler, o del grupo "*" si no tiene uno propio
+func parseRobotsRules(body string) robotsRules {
+	var specific, generic robotsRules
+	var hasSpecific bool
+	var current []*robotsRules
+	inAgents := false
+
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		if key == "user-agent" {
+			// Líneas user-agent consecutivas comparten el mismo grupo
+			if !inAgents {
+				current = nil
+			}
+			inAgents = true
+			agent := strings.ToLower(value)
+			switch {
+			case agent == "*":
+				current = append(current, &generic)
+			case agent == crawlUserAgentToken:
+				hasSpecific = true
+				current = append(current, &specific)
+			}
+			continue
+		}
+		inAgents = false
+
+		for _, group := range current {
+			switch key {
+			case "allow", "disallow":
+				// "Disallow:" vacío no restringe nada
+				if value != "" {
+					group.rules = append(group.rules, robotsRule{pattern: value, allow: key == "allow"})
+				}
+			case "crawl-delay":
+				if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+					group.crawlDelay = time.Duration(seconds * float64(time.Second))
+				}
+			}
+		}
+	}
+
+	if hasSpecific {
+		return specific
+	}
+	return generic
+}
+
+// allowed indica si la URL puede visitarse: gana la regla más larga y, ante empate, Allow
+func (r robotsRules) allowed(target string) bool {
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+	path := parsed.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if parsed.RawQuery != "" {
+		path += "?" + parsed.RawQuery
+	}
+
+	allowed, matchLength := true, -1
+	for _, rule := range r.rules {
+		if !robotsPatternMatches(rule.pattern, path) {
+			continue
+		}
+		if len(rule.pattern) > matchLength || (len(rule.pattern) == matchLength && rule.allow) {
+			allowed, matchLength = rule.allow, len(rule.pattern)
+		}
+	}
+	return allowed
+}
+
+// robotsPatternMatches compara un path con un patrón de robots.txt (prefijo, con comodín * y ancla $)
+func robotsPatternMatches(pattern string, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	for i, part := range parts[1:] {
+		// La última parte de un patrón anclado debe coincidir con el final del path
+		if anchored && i == len(parts)-2 {
+			return strings.HasSuffix(rest, part)
+		}
+		index := strings.Index(rest, part)
+		if index < 0 {
+			return false
+		}
+		rest = rest[index+len(part):]
+	}
+	return !anchored || rest == ""
+}
@@ -0,0 +1,284 @@
+package monitors
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// FailureDiagnosis resultado del diagnóstico por capas de una falla de conexión
+type FailureDiagnosis struct {
+	Layer     string                   // Capa que falló: "dns", "tcp", "tls" o el protocolo ("http", "postgresql")
+	RootCause string                   // Causa probable legible para operadores
+	Steps     []map[string]interface{} // Resultado de cada capa verificada
+}
+
+// applyTo agrega el diagnóstico a la metadata del check
+func (d FailureDiagnosis) applyTo(metadata map[string]interface{}) {
+	metadata["failure_layer"] = d.Layer
+	metadata["failure_root_cause"] = d.RootCause
+	metadata["diagnostics"] = d.Steps
+}
+
+// minDiagnosisFraction fracción del timeout reservada al diagnóstico cuando la operación original
+// agotó su plazo: el check dura como máximo el timeout más un cuarto de él
+const minDiagnosisFraction = 4
+
+// diagnosisBudget tiempo para diagnosticar una falla: lo que resta del plazo de la operación original,
+// entre un cuarto del timeout y el timeout completo
+func diagnosisBudget(deadline time.Time, timeout time.Duration) time.Duration {
+	budget := time.Until(deadline)
+	if minimum := timeout / minDiagnosisFraction; budget < minimum {
+		return minimum
+	}
+	if budget > timeout {
+		return timeout
+	}
+	return budget
+}
+
+// diagnoseHTTPFailure ejecuta la cadena DNS → TCP → TLS → HTTP para una URL que no respondió
+// requestErr es el error original de la petición, usado para clasificar la capa de protocolo
+func diagnoseHTTPFailure(rawURL string, timeout time.Duration, skipSSLVerify bool, requestErr error) FailureDiagnosis {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Hostname() == "" {
+		return FailureDiagnosis{Layer: "http", RootCause: "URL inválida"}
+	}
+
+	port := parsed.Port()
+	if port == "" {
+		port = "80"
+		if parsed.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	// Un único plazo para todas las capas (DNS, TCP y TLS no suman cada una el timeout completo)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	diagnosis := FailureDiagnosis{}
+	address, ok := diagnoseNetworkLayers(ctx, &diagnosis, parsed.Hostname(), port)
+	if !ok {
+		return diagnosis
+	}
+
+	if parsed.Scheme == "https" {
+		if !diagnoseTLSLayer(ctx, &diagnosis, address, parsed.Hostname(), skipSSLVerify) {
+			return diagnosis
+		}
+	}
+
+	diagnosis.Layer = "http"
+	diagnosis.RootCause = classifyProtocolError("HTTP", requestErr)
+	diagnosis.Steps = append(diagnosis.Steps, diagnosticStep("http", "error", diagnosis.RootCause, 0))
+	return diagnosis
+}
+
+// diagnosePostgreSQLFailure ejecuta la cadena DNS → TCP → PostgreSQL para una conexión fallida
+func diagnosePostgreSQLFailure(host string, port int, timeout time.Duration, connErr error) FailureDiagnosis {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	diagnosis := FailureDiagnosis{}
+	if _, ok := diagnoseNetworkLayers(ctx, &diagnosis, host, strconv.Itoa(port)); !ok {
+		return diagnosis
+	}
+
+	diagnosis.Layer = "postgresql"
+	diagnosis.RootCause = classifyPostgreSQLError(connErr)
+	diagnosis.Steps = append(diagnosis.Steps, diagnosticStep("postgresql", "error", diagnosis.RootCause, 0))
+	return diagnosis
+}
+
+// diagnoseNetworkLayers verifica resolución DNS y conexión TCP dentro del plazo de ctx
+// Prueba cada dirección resuelta (un AAAA primero en un host solo IPv4 no es una falla de red)
+// Retorna la dirección conectada y true si ambas capas funcionan
+func diagnoseNetworkLayers(ctx context.Context, diagnosis *FailureDiagnosis, host string, port string) (string, bool) {
+	// Capa 1: DNS (se omite si el host ya es una IP)
+	addrs := []string{host}
+	if net.ParseIP(host) == nil {
+		start := time.Now()
+		resolved, err := net.DefaultResolver.LookupHost(ctx, host)
+		elapsed := time.Since(start).Milliseconds()
+		if err != nil {
+			diagnosis.Layer = "dns"
+			diagnosis.RootCause = classifyDNSError(err)
+			diagnosis.Steps = append(diagnosis.Steps, diagnosticStep("dns", "error", diagnosis.RootCause, elapsed))
+			return "", false
+		}
+		addrs = resolved
+		diagnosis.Steps = append(diagnosis.Steps, diagnosticStep("dns", "ok", fmt.Sprintf("%s → %v", host, addrs), elapsed))
+	}
+
+	// Capa 2: TCP, cada dirección con una parte del tiempo restante
+	dialer := &net.Dialer{}
+	start := time.Now()
+	var dialErrors []error
+	for i, ip := range addrs {
+		address := net.JoinHostPort(ip, port)
+		attemptCtx, cancel := context.WithCancel(ctx)
+		if deadline, ok := ctx.Deadline(); ok {
+			remaining := time.Until(deadline) / time.Duration(len(addrs)-i)
+			attemptCtx, cancel = context.WithTimeout(ctx, remaining)
+		}
+		conn, err := dialer.DialContext(attemptCtx, "tcp", address)
+		cancel()
+		if err != nil {
+			dialErrors = append(dialErrors, err)
+			continue
+		}
+		conn.Close()
+		diagnosis.Steps = append(diagnosis.Steps, diagnosticStep("tcp", "ok", "Conexión TCP a "+address, time.Since(start).Milliseconds()))
+		return address, true
+	}
+
+	diagnosis.Layer = "tcp"
+	diagnosis.RootCause = classifyTCPError(relevantDialError(dialErrors))
+	diagnosis.Steps = append(diagnosis.Steps, diagnosticStep("tcp", "error", diagnosis.RootCause, time.Since(start).Milliseconds()))
+	return "", false
+}
+
+// relevantDialError elige el error más representativo de los intentos de conexión:
+// "red inalcanzable" solo si todas las direcciones fallaron así (típico de IPv6 sin ruta)
+func relevantDialError(dialErrors []error) error {
+	for _, err := range dialErrors {
+		if !errors.Is(err, syscall.ENETUNREACH) && !errors.Is(err, syscall.EHOSTUNREACH) && !errors.Is(err, syscall.EADDRNOTAVAIL) {
+			return err
+		}
+	}
+	return dialErrors[0]
+}
+
+// diagnoseTLSLayer verifica el handshake TLS contra la dirección ya resuelta dentro del plazo de ctx
+func diagnoseTLSLayer(ctx context.Context, diagnosis *FailureDiagnosis, address string, serverName string, skipSSLVerify bool) bool {
+	dialer := &tls.Dialer{Config: &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: skipSSLVerify,
+	}}
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	elapsed := time.Since(start).Milliseconds()
+	if err != nil {
+		diagnosis.Layer = "tls"
+		diagnosis.RootCause = classifyTLSError(err)
+		diagnosis.Steps = append(diagnosis.Steps, diagnosticStep("tls", "error", diagnosis.RootCause, elapsed))
+		return false
+	}
+	conn.Close()
+	diagnosis.Steps = append(diagnosis.Steps, diagnosticStep("tls", "ok", "Handshake TLS exitoso", elapsed))
+
+	return true
+}
+
+// diagnosticStep construye el resultado de una capa para metadata
+func diagnosticStep(layer string, status string, detail string, elapsedMs int64) map[string]interface{} {
+	return map[string]interface{}{
+		"layer":            layer,
+		"status":           status,
+		"detail":           detail,
+		"response_time_ms": elapsedMs,
+	}
+}
+
+// classifyDNSError traduce un error de resolución a una causa legible
+func classifyDNSError(err error) string {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		if dnsErr.IsNotFound {
+			return "DNS NXDOMAIN — el nombre no existe"
+		}
+		if dnsErr.IsTimeout {
+			return "DNS timeout — el servidor DNS no responde"
+		}
+		if dnsErr.IsTemporary {
+			return "DNS con falla temporal (SERVFAIL)"
+		}
+	}
+	return "Fallo de resolución DNS: " + err.Error()
+}
+
+// classifyTCPError traduce un error de conexión TCP a una causa legible
+func classifyTCPError(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "TCP timeout — probablemente VPN/firewall"
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "Conexión rechazada — servicio caído o puerto cerrado"
+	case errors.Is(err, syscall.ENETUNREACH), errors.Is(err, syscall.EHOSTUNREACH):
+		return "Red inalcanzable — verificar VPN/ruteo"
+	case errors.Is(err, syscall.ECONNRESET):
+		return "Conexión reiniciada por el servidor o un firewall"
+	}
+	return "Fallo de conexión TCP: " + err.Error()
+}
+
+// classifyTLSError traduce un error de handshake TLS a una causa legible
+func classifyTLSError(err error) string {
+	var certErr x509.CertificateInvalidError
+	var hostErr x509.HostnameError
+	var authErr x509.UnknownAuthorityError
+	var verifyErr *tls.CertificateVerificationError
+	var netErr net.Error
+
+	switch {
+	case errors.As(err, &certErr) && certErr.Reason == x509.Expired:
+		return "TLS expirado — certificado vencido o aún no válido"
+	case errors.As(err, &certErr):
+		return "TLS certificado inválido: " + certErr.Error()
+	case errors.As(err, &hostErr):
+		return "TLS nombre no coincide — el certificado no corresponde al host"
+	case errors.As(err, &authErr):
+		return "TLS CA desconocida — certificado autofirmado o de CA privada"
+	case errors.As(err, &verifyErr):
+		return "TLS verificación fallida: " + verifyErr.Err.Error()
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "TLS timeout — el handshake no se completó"
+	}
+	return "Fallo de handshake TLS: " + err.Error()
+}
+
+// classifyProtocolError traduce un error de la capa de aplicación cuando la red funciona
+func classifyProtocolError(protocol string, err error) string {
+	if err == nil {
+		return protocol + " sin respuesta válida"
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return protocol + " timeout — la red responde pero la aplicación no contesta a tiempo"
+	}
+	return fmt.Sprintf("Error de %s: %s", protocol, err.Error())
+}
+
+// classifyPostgreSQLError traduce un error de PostgreSQL a una causa legible
+func classifyPostgreSQLError(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "28P01", "28000":
+			return "Autenticación rechazada — usuario/contraseña o pg_hba.conf"
+		case "3D000":
+			return "La base de datos no existe"
+		case "53300":
+			return "Demasiadas conexiones — max_connections alcanzado"
+		case "57P03":
+			return "El servidor está iniciando o en recuperación"
+		case "42P01":
+			return "La tabla consultada no existe"
+		case "42501":
+			return "Permisos insuficientes para la consulta"
+		}
+		return fmt.Sprintf("Error de PostgreSQL %s: %s", pgErr.Code, pgErr.Message)
+	}
+	return classifyProtocolError("PostgreSQL", err)
+}
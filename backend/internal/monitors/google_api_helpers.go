@@ -0,0 +1,91 @@
+package monitors
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// googleAPIMaxRetries reintentos ante respuestas 429 o 5xx de las APIs de Google
+const googleAPIMaxRetries = 3
+
+// googleAPIRetryBaseDelay espera antes del primer reintento (se duplica en cada intento)
+const googleAPIRetryBaseDelay = time.Second
+
+// googleQuotaReasons motivos de error 403 que corresponden a cuotas o límites de uso
+var googleQuotaReasons = map[string]bool{
+	"rateLimitExceeded":     true,
+	"userRateLimitExceeded": true,
+	"quotaExceeded":         true,
+	"dailyLimitExceeded":    true,
+}
+
+// callGoogleAPI ejecuta una llamada a una API de Google con backoff exponencial
+// Reintenta ante 429 y 5xx respetando Retry-After; el resto de los errores se retorna de inmediato
+func callGoogleAPI(ctx context.Context, call func() error) error {
+	delay := googleAPIRetryBaseDelay
+	for attempt := 0; ; attempt++ {
+		err := call()
+		if err == nil || attempt >= googleAPIMaxRetries || !isRetryableGoogleError(err) {
+			return err
+		}
+
+		wait := delay
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) {
+			if seconds, parseErr := strconv.Atoi(apiErr.Header.Get("Retry-After")); parseErr == nil && seconds > 0 {
+				wait = time.Duration(seconds) * time.Second
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		delay *= 2
+	}
+}
+
+// isRetryableGoogleError indica si el error es transitorio (429 o 5xx)
+func isRetryableGoogleError(err error) bool {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= 500
+}
+
+// googleErrorType clasifica un error de las APIs de Google para el campo error_type de metadata
+func googleErrorType(err error) string {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return "timeout"
+		}
+		return "connection_failed"
+	}
+
+	switch {
+	case apiErr.Code == http.StatusTooManyRequests:
+		return "quota_exceeded"
+	case apiErr.Code == http.StatusForbidden:
+		for _, item := range apiErr.Errors {
+			if googleQuotaReasons[item.Reason] {
+				return "quota_exceeded"
+			}
+		}
+		return "permission_denied"
+	case apiErr.Code == http.StatusUnauthorized:
+		return "auth_failed"
+	case apiErr.Code == http.StatusNotFound:
+		return "not_found"
+	case apiErr.Code >= 500:
+		return "service_unavailable"
+	}
+	return "api_error"
+}
@@ -0,0 +1,240 @@
+package monitors
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+
+	"github.com/saltacompra/monitor/internal/models"
+)
+
+// GoogleDriveFileCheckConfig configuración para verificar un archivo generado en una carpeta de Drive
+type GoogleDriveFileCheckConfig struct {
+	Auth                   GoogleAuthConfig
+	CheckID                string
+	CheckName              string
+	FolderID               string
+	Filename               string    // Nombre exacto esperado (ej: bd_20240510.zip)
+	FileDate               time.Time // Fecha a la que corresponde el archivo (opcional, para validar la modificación)
+	RecentPrefix           string    // Prefijo de los archivos comparables (ej: bd_)
+	RecentFiles            int       // Archivos recientes usados como referencia de tamaño (default 10)
+	MinSizeBytes           int64     // Tamaño mínimo absoluto (0 = no se evalúa)
+	MinSizeRatioPercent    int       // % mínimo respecto de la mediana de los recientes (0 = no se evalúa)
+	MaxSizeRatioPercent    int       // % máximo respecto de la mediana de los recientes (0 = no se evalúa)
+	MaxModifiedOffsetHours int       // Horas máximas entre la fecha del archivo y su modificación (0 = no se evalúa)
+	TimeoutSeconds         int
+}
+
+// driveServices servicios de Google Drive reutilizados entre ejecuciones (token en caché)
+var driveServices = struct {
+	sync.Mutex
+	byAuth map[GoogleAuthConfig]*drive.Service
+}{byAuth: make(map[GoogleAuthConfig]*drive.Service)}
+
+// driveService retorna el servicio de Google Drive para las credenciales indicadas, creándolo una sola vez
+// Solo pide acceso de lectura a la metadata de los archivos
+func driveService(auth GoogleAuthConfig) (*drive.Service, error) {
+	driveServices.Lock()
+	defer driveServices.Unlock()
+
+	if srv, exists := driveServices.byAuth[auth]; exists {
+		return srv, nil
+	}
+
+	options, err := googleClientOptions(auth)
+	if err != nil {
+		return nil, err
+	}
+	options = append(options, option.WithScopes(drive.DriveMetadataReadonlyScope))
+	srv, err := drive.NewService(context.Background(), options...)
+	if err != nil {
+		return nil, err
+	}
+	driveServices.byAuth[auth] = srv
+	return srv, nil
+}
+
+// driveQueryValue escapa un valor para usarlo entre comillas en una consulta de Drive
+func driveQueryValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+}
+
+// CheckGoogleDriveFile verifica que el archivo esperado exista en la carpeta de Drive,
+// que su tamaño sea razonable comparado con los archivos recientes y que su fecha de modificación sea coherente
+func CheckGoogleDriveFile(config GoogleDriveFileCheckConfig) models.Check {
+	check := models.Check{
+		ID:        config.CheckID,
+		Type:      "google-drive",
+		Name:      config.CheckName,
+		LastCheck: time.Now(),
+		Metadata:  make(map[string]interface{}),
+	}
+	check.Metadata["folder_id"] = config.FolderID
+	check.Metadata["expected_filename"] = config.Filename
+
+	if config.Filename == "" {
+		check.Status = "warning"
+		check.Message = "No se pudo determinar el nombre del archivo esperado"
+		return check
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(config.TimeoutSeconds))
+	defer cancel()
+
+	start := time.Now()
+	srv, err := driveService(config.Auth)
+	if err != nil {
+		check.Status = "error"
+		check.Message = "Error al conectar con Google Drive API: " + err.Error()
+		check.Metadata["error_type"] = "connection_failed"
+		return check
+	}
+
+	// Archivo esperado
+	const fields = "files(id,name,size,modifiedTime,md5Checksum)"
+	query := fmt.Sprintf("name = '%s' and '%s' in parents and trashed = false",
+		driveQueryValue(config.Filename), driveQueryValue(config.FolderID))
+	var found *drive.FileList
+	err = callGoogleAPI(ctx, func() (err error) {
+		found, err = srv.Files.List().Q(query).Fields(fields).
+			SupportsAllDrives(true).IncludeItemsFromAllDrives(true).Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		check.ResponseTime = time.Since(start).Milliseconds()
+		setDriveError(&check, err)
+		return check
+	}
+
+	if len(found.Files) == 0 {
+		check.ResponseTime = time.Since(start).Milliseconds()
+		check.Status = "error"
+		check.Message = fmt.Sprintf("El archivo %s no existe en la carpeta de Drive", config.Filename)
+		return check
+	}
+	file := found.Files[0]
+	check.Metadata["file_id"] = file.Id
+	check.Metadata["size_bytes"] = file.Size
+	check.Metadata["modified_time"] = file.ModifiedTime
+	if file.Md5Checksum != "" {
+		check.Metadata["md5"] = file.Md5Checksum
+	}
+	if len(found.Files) > 1 {
+		check.Metadata["duplicates"] = len(found.Files)
+	}
+
+	// Archivos recientes de referencia para el tamaño
+	recentCount := config.RecentFiles
+	if recentCount <= 0 {
+		recentCount = 10
+	}
+	recentQuery := fmt.Sprintf("'%s' in parents and trashed = false", driveQueryValue(config.FolderID))
+	if config.RecentPrefix != "" {
+		recentQuery += fmt.Sprintf(" and name contains '%s'", driveQueryValue(config.RecentPrefix))
+	}
+	var recent *drive.FileList
+	err = callGoogleAPI(ctx, func() (err error) {
+		recent, err = srv.Files.List().Q(recentQuery).Fields(fields).OrderBy("modifiedTime desc").
+			PageSize(int64(recentCount + 1)).SupportsAllDrives(true).IncludeItemsFromAllDrives(true).Context(ctx).Do()
+		return err
+	})
+	check.ResponseTime = time.Since(start).Milliseconds()
+	if err != nil {
+		setDriveError(&check, err)
+		return check
+	}
+
+	var sizes []int64
+	for _, other := range recent.Files {
+		if other.Id == file.Id || other.Size <= 0 || len(sizes) >= recentCount {
+			continue
+		}
+		sizes = append(sizes, other.Size)
+	}
+
+	status := "ok"
+	var problems []string
+	raise := func(newStatus string, problem string) {
+		problems = append(problems, problem)
+		if newStatus == "error" || status == "ok" {
+			status = newStatus
+		}
+	}
+
+	// Tamaño
+	if file.Size == 0 {
+		raise("error", "el archivo está vacío")
+	} else if config.MinSizeBytes > 0 && file.Size < config.MinSizeBytes {
+		raise("error", fmt.Sprintf("tamaño %s menor al mínimo de %s", formatBytes(file.Size), formatBytes(config.MinSizeBytes)))
+	}
+	if len(sizes) > 0 && file.Size > 0 {
+		sort.Slice(sizes, func(i, j int) bool { return sizes[i] < sizes[j] })
+		median := sizes[len(sizes)/2]
+		ratio := float64(file.Size) / float64(median) * 100
+		check.Metadata["recent_files"] = len(sizes)
+		check.Metadata["recent_median_bytes"] = median
+		check.Metadata["size_ratio_percent"] = roundMetric(ratio)
+
+		tooSmall := config.MinSizeRatioPercent > 0 && ratio < float64(config.MinSizeRatioPercent)
+		tooLarge := config.MaxSizeRatioPercent > 0 && ratio > float64(config.MaxSizeRatioPercent)
+		if tooSmall || tooLarge {
+			raise("warning", fmt.Sprintf("tamaño %s es %.0f%% de la mediana reciente (%s)", formatBytes(file.Size), ratio, formatBytes(median)))
+		}
+	}
+
+	// Fecha de modificación respecto de la fecha del archivo
+	if modified, err := time.Parse(time.RFC3339, file.ModifiedTime); err == nil {
+		check.Metadata["hours_since_modified"] = roundMetric(time.Since(modified).Hours())
+		if !config.FileDate.IsZero() && config.MaxModifiedOffsetHours > 0 {
+			fileDay := time.Date(config.FileDate.Year(), config.FileDate.Month(), config.FileDate.Day(), 0, 0, 0, 0, config.FileDate.Location())
+			offset := modified.Sub(fileDay).Hours()
+			check.Metadata["modified_offset_hours"] = roundMetric(offset)
+			if offset < 0 || offset > float64(config.MaxModifiedOffsetHours) {
+				raise("warning", fmt.Sprintf("modificado el %s, fuera del día del archivo",
+					modified.In(config.FileDate.Location()).Format("2006-01-02 15:04")))
+			}
+		}
+	}
+
+	check.Status = status
+	if len(problems) > 0 {
+		check.Message = fmt.Sprintf("%s: %s", config.Filename, strings.Join(problems, "; "))
+	} else {
+		check.Message = fmt.Sprintf("%s presente en Drive (%s)", config.Filename, formatBytes(file.Size))
+	}
+	return check
+}
+
+// setDriveError registra un error de la API de Drive en el check
+func setDriveError(check *models.Check, err error) {
+	errorType := googleErrorType(err)
+	check.Status = "error"
+	check.Metadata["error_type"] = errorType
+	if errorType == "quota_exceeded" {
+		check.Message = "Cuota de Google Drive API excedida: " + err.Error()
+		return
+	}
+	check.Message = "Error al consultar Google Drive: " + err.Error()
+}
+
+// formatBytes formatea un tamaño en bytes con la unidad más adecuada
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value := float64(size)
+	units := []string{"KB", "MB", "GB", "TB"}
+	i := -1
+	for value >= unit && i < len(units)-1 {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", value, units[i])
+}
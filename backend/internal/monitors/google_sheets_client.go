@@ -0,0 +1,151 @@
+package monitors
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+// GoogleAuthConfig credenciales y endpoint para las APIs de Google
+type GoogleAuthConfig struct {
+	AuthMethod      string // "service_account" o "api_key"
+	CredentialsFile string
+	APIKey          string
+	Endpoint        string // Opcional, URL base alternativa (ej: un servidor local de pruebas)
+}
+
+// sheetsServices servicios de Google Sheets reutilizados entre ejecuciones
+// Cada servicio conserva su token OAuth hasta que vence, evitando autenticarse en cada check
+var sheetsServices = struct {
+	sync.Mutex
+	byAuth map[GoogleAuthConfig]*sheets.Service
+}{byAuth: make(map[GoogleAuthConfig]*sheets.Service)}
+
+// sheetsService retorna el servicio de Google Sheets para las credenciales indicadas, creándolo una sola vez
+func sheetsService(auth GoogleAuthConfig) (*sheets.Service, error) {
+	sheetsServices.Lock()
+	defer sheetsServices.Unlock()
+
+	if srv, exists := sheetsServices.byAuth[auth]; exists {
+		return srv, nil
+	}
+
+	options, err := googleClientOptions(auth)
+	if err != nil {
+		return nil, err
+	}
+	// El contexto del servicio debe vivir mientras se reutilice (renovación de tokens)
+	srv, err := sheets.NewService(context.Background(), options...)
+	if err != nil {
+		return nil, err
+	}
+	sheetsServices.byAuth[auth] = srv
+	return srv, nil
+}
+
+// googleClientOptions arma las opciones de cliente según el método de autenticación
+func googleClientOptions(auth GoogleAuthConfig) ([]option.ClientOption, error) {
+	var options []option.ClientOption
+	if auth.Endpoint != "" {
+		options = append(options, option.WithEndpoint(auth.Endpoint))
+	}
+
+	if auth.AuthMethod == "api_key" && auth.APIKey != "" {
+		return append(options, option.WithAPIKey(auth.APIKey)), nil
+	}
+
+	// Service Account (default)
+	if auth.CredentialsFile == "" {
+		return nil, fmt.Errorf("no se especificó archivo de credenciales")
+	}
+	return append(options, option.WithCredentialsFile(auth.CredentialsFile)), nil
+}
+
+// sheetRows filas leídas de una hoja
+type sheetRows struct {
+	values   [][]interface{} // Filas de datos (sin encabezados)
+	firstRow int             // Número de fila en la hoja (desde 1) de values[0]
+	lastRow  int             // Última fila con datos de la hoja (0 si no tiene datos)
+	partial  bool            // Solo se leyó el final de la hoja
+}
+
+// sheetLastRows última fila con datos de cada hoja, usada como referencia para leer solo su final
+var sheetLastRows = struct {
+	sync.Mutex
+	byKey map[string]int
+}{byKey: make(map[string]int)}
+
+// readSheetRows lee las filas de datos de una hoja
+// Con tailRows > 0 y una lectura anterior de la hoja, lee desde tailRows filas antes de la última
+// fila con datos conocida hasta el final (incluye las filas agregadas desde entonces) con una sola
+// llamada; la primera vez, o si la hoja se acortó y el rango no alcanza, la lee completa
+func readSheetRows(ctx context.Context, srv *sheets.Service, spreadsheetID string, sheetName string,
+	lastColumn int, headerRows int, tailRows int) (sheetRows, error) {
+
+	key := spreadsheetID + "/" + sheetName
+	firstDataRow := headerRows + 1
+	start := firstDataRow
+	if tailRows > 0 {
+		sheetLastRows.Lock()
+		hint := sheetLastRows.byKey[key]
+		sheetLastRows.Unlock()
+		if hint-tailRows+1 > start {
+			start = hint - tailRows + 1
+		}
+	}
+
+	rows, err := readSheetRange(ctx, srv, spreadsheetID, sheetName, lastColumn, start)
+	if err != nil {
+		return sheetRows{}, err
+	}
+	if start > firstDataRow && nonEmptyRows(rows.values) < tailRows {
+		rows, err = readSheetRange(ctx, srv, spreadsheetID, sheetName, lastColumn, firstDataRow)
+		if err != nil {
+			return sheetRows{}, err
+		}
+	}
+	rows.partial = rows.firstRow > firstDataRow
+
+	if tailRows > 0 {
+		sheetLastRows.Lock()
+		sheetLastRows.byKey[key] = rows.lastRow
+		sheetLastRows.Unlock()
+	}
+	return rows, nil
+}
+
+// readSheetRange lee las columnas hasta lastColumn desde la fila start hasta el final de la hoja
+func readSheetRange(ctx context.Context, srv *sheets.Service, spreadsheetID string, sheetName string,
+	lastColumn int, start int) (sheetRows, error) {
+
+	readRange := fmt.Sprintf("%s%d:%s", sheetRangePrefix(sheetName), start, sheetColumnLetter(lastColumn))
+	var resp *sheets.ValueRange
+	err := callGoogleAPI(ctx, func() (err error) {
+		resp, err = srv.Spreadsheets.Values.Get(spreadsheetID, readRange).Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return sheetRows{}, err
+	}
+
+	// La API omite las filas vacías del final: la última fila leída es la última con datos
+	rows := sheetRows{values: resp.Values, firstRow: start}
+	if len(resp.Values) > 0 {
+		rows.lastRow = start + len(resp.Values) - 1
+	}
+	return rows, nil
+}
+
+// nonEmptyRows cuenta las filas con al menos una celda
+func nonEmptyRows(values [][]interface{}) int {
+	count := 0
+	for _, row := range values {
+		if len(row) > 0 {
+			count++
+		}
+	}
+	return count
+}
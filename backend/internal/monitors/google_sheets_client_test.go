@@ -0,0 +1,237 @@
+package monitors
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeGoogleTestCredentials escribe un archivo de credenciales de Service Account
+// cuyo token_uri apunta al servidor local, como el archivo que se configura en producción
+func writeGoogleTestCredentials(t *testing.T, serverURL string) string {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("no se pudo generar la clave: %v", err)
+	}
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	credentials, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "monitor-test",
+		"private_key_id": "test-key",
+		"private_key":    string(privateKey),
+		"client_email":   "monitor@monitor-test.iam.gserviceaccount.com",
+		"client_id":      "123",
+		"token_uri":      serverURL + "/token",
+	})
+	if err != nil {
+		t.Fatalf("no se pudieron serializar las credenciales: %v", err)
+	}
+
+	credentialsFile := filepath.Join(t.TempDir(), "credentials.json")
+	if err := os.WriteFile(credentialsFile, credentials, 0600); err != nil {
+		t.Fatalf("no se pudo escribir el archivo de credenciales: %v", err)
+	}
+	return credentialsFile
+}
+
+// serveGoogleToken responde las peticiones de token OAuth; retorna false si la petición es para la API
+func serveGoogleToken(t *testing.T, w http.ResponseWriter, r *http.Request) bool {
+	if r.URL.Path != "/token" {
+		return false
+	}
+	writeJSON(t, w, http.StatusOK, map[string]interface{}{
+		"access_token": "test-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+	})
+	return true
+}
+
+// newSheetsTestServer levanta un servidor local que reemplaza a la API de Google Sheets
+// handler recibe el rango pedido y el número de petición (desde 0) y escribe la respuesta
+func newSheetsTestServer(t *testing.T, handler func(w http.ResponseWriter, readRange string, request int)) (SheetFreshnessCheckConfig, *[]string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if serveGoogleToken(t, w, r) {
+			return
+		}
+		// El token debe venir del intercambio hecho con el archivo de credenciales
+		if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+			t.Errorf("Authorization inesperado: %q", got)
+		}
+		readRange := r.URL.Path[strings.LastIndex(r.URL.Path, "/values/")+len("/values/"):]
+		mu.Lock()
+		ranges = append(ranges, readRange)
+		request := len(ranges) - 1
+		mu.Unlock()
+		handler(w, readRange, request)
+	}))
+	t.Cleanup(server.Close)
+
+	config := SheetFreshnessCheckConfig{
+		// Cada test usa su propio endpoint y planilla: no comparte el servicio ni la última fila conocida
+		AuthMethod:      "service_account",
+		CredentialsFile: writeGoogleTestCredentials(t, server.URL),
+		Endpoint:        server.URL + "/",
+		SpreadsheetID:   strings.ReplaceAll(t.Name(), "/", "-"),
+		SheetName:       "Kairos",
+		CheckID:         "kairos-sheet",
+		CheckName:       "Hoja Kairos",
+		TimestampColumn: 0,
+		FilenameColumn:  -1,
+		HeaderRows:      1,
+		WarningDays:     1,
+		ErrorDays:       2,
+		TailRows:        3,
+	}
+	return config, &ranges
+}
+
+// sheetRangeStart extrae la fila inicial de un rango A1 ('Hoja'!A9:A)
+var sheetRangeStart = regexp.MustCompile(`!A(\d+):`)
+
+// writeSheetValues responde con las filas de la hoja (desde la fila 2, bajo el encabezado) incluidas en el rango
+func writeSheetValues(t *testing.T, w http.ResponseWriter, readRange string, dataRows int) {
+	t.Helper()
+
+	matches := sheetRangeStart.FindStringSubmatch(readRange)
+	if matches == nil {
+		t.Fatalf("rango inesperado: %s", readRange)
+	}
+	start, _ := strconv.Atoi(matches[1])
+
+	today := time.Now().Format("2006-01-02")
+	values := []interface{}{}
+	for row := start; row <= dataRows+1; row++ {
+		values = append(values, []interface{}{today})
+	}
+	writeJSON(t, w, http.StatusOK, map[string]interface{}{"range": readRange, "values": values})
+}
+
+func TestCheckSheetFreshnessReadsTailFromLastRow(t *testing.T) {
+	config, ranges := newSheetsTestServer(t, func(w http.ResponseWriter, readRange string, request int) {
+		writeSheetValues(t, w, readRange, 10)
+	})
+
+	first := CheckSheetFreshness(config)
+	second := CheckSheetFreshness(config)
+
+	if len(*ranges) != 2 {
+		t.Fatalf("se esperaban 2 lecturas, hubo %d: %v", len(*ranges), *ranges)
+	}
+	// La primera vez se lee la hoja completa; luego solo las últimas 3 filas (10 de datos + encabezado)
+	if (*ranges)[0] != "'Kairos'!A2:A" {
+		t.Errorf("primera lectura inesperada: %s", (*ranges)[0])
+	}
+	if (*ranges)[1] != "'Kairos'!A9:A" {
+		t.Errorf("segunda lectura inesperada: %s", (*ranges)[1])
+	}
+	if first.Metadata["total_rows"] != 10 {
+		t.Errorf("total_rows esperado 10, obtenido %v", first.Metadata["total_rows"])
+	}
+	if second.Metadata["rows_read"] != 3 || second.Metadata["sheet_rows"] != 10 {
+		t.Errorf("lectura parcial inesperada: rows_read=%v sheet_rows=%v", second.Metadata["rows_read"], second.Metadata["sheet_rows"])
+	}
+	if second.Status != "ok" {
+		t.Errorf("estado esperado ok, obtenido %s (%s)", second.Status, second.Message)
+	}
+}
+
+func TestCheckSheetFreshnessFallsBackWhenSheetShrinks(t *testing.T) {
+	config, ranges := newSheetsTestServer(t, func(w http.ResponseWriter, readRange string, request int) {
+		// Después de la primera lectura se borran filas: quedan 5 de datos
+		dataRows := 5
+		if request == 0 {
+			dataRows = 10
+		}
+		writeSheetValues(t, w, readRange, dataRows)
+	})
+
+	CheckSheetFreshness(config)
+	check := CheckSheetFreshness(config)
+
+	if len(*ranges) != 3 {
+		t.Fatalf("se esperaban 3 lecturas, hubo %d: %v", len(*ranges), *ranges)
+	}
+	// El final conocido (fila 9 en adelante) ya no tiene filas: se vuelve a leer la hoja completa
+	if (*ranges)[1] != "'Kairos'!A9:A" || (*ranges)[2] != "'Kairos'!A2:A" {
+		t.Errorf("lecturas inesperadas: %v", *ranges)
+	}
+	if check.Metadata["total_rows"] != 5 {
+		t.Errorf("total_rows esperado 5, obtenido %v", check.Metadata["total_rows"])
+	}
+	if _, exists := check.Metadata["rows_read"]; exists {
+		t.Error("la lectura completa no debe informar rows_read")
+	}
+}
+
+func TestCheckSheetFreshnessRetriesTransientErrors(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+		t.Run(strconv.Itoa(status), func(t *testing.T) {
+			config, ranges := newSheetsTestServer(t, func(w http.ResponseWriter, readRange string, request int) {
+				if request == 0 {
+					writeJSON(t, w, status, map[string]interface{}{
+						"error": map[string]interface{}{"code": status, "message": http.StatusText(status)},
+					})
+					return
+				}
+				writeSheetValues(t, w, readRange, 4)
+			})
+
+			check := CheckSheetFreshness(config)
+
+			if len(*ranges) != 2 {
+				t.Fatalf("se esperaban 2 peticiones (un reintento), hubo %d", len(*ranges))
+			}
+			if check.Status != "ok" {
+				t.Errorf("estado esperado ok, obtenido %s (%s)", check.Status, check.Message)
+			}
+		})
+	}
+}
+
+func TestCheckSheetFreshnessQuotaExceeded(t *testing.T) {
+	config, ranges := newSheetsTestServer(t, func(w http.ResponseWriter, readRange string, request int) {
+		writeJSON(t, w, http.StatusForbidden, map[string]interface{}{
+			"error": map[string]interface{}{
+				"code":    http.StatusForbidden,
+				"message": "Quota exceeded",
+				"errors":  []interface{}{map[string]interface{}{"reason": "rateLimitExceeded", "message": "Quota exceeded"}},
+			},
+		})
+	})
+
+	check := CheckSheetFreshness(config)
+
+	if check.Status != "error" {
+		t.Errorf("estado esperado error, obtenido %s", check.Status)
+	}
+	if check.Metadata["error_type"] != "quota_exceeded" {
+		t.Errorf("error_type esperado quota_exceeded, obtenido %v", check.Metadata["error_type"])
+	}
+	if !strings.HasPrefix(check.Message, "Cuota de Google Sheets API excedida") {
+		t.Errorf("mensaje inesperado: %s", check.Message)
+	}
+	// Un 403 no se reintenta
+	if len(*ranges) != 1 {
+		t.Errorf("se esperaba 1 petición, hubo %d", len(*ranges))
+	}
+}
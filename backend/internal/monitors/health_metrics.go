@@ -0,0 +1,127 @@
+package monitors
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/saltacompra/monitor/internal/models"
+)
+
+// MetricThreshold umbrales de una métrica de salud (valor >= umbral); 0 deshabilita el umbral
+type MetricThreshold struct {
+	Warning float64
+	Error   float64
+	Below   bool // true: se alerta con valores <= umbral (ej: espacio libre, page life expectancy)
+}
+
+// evaluate retorna el estado de la métrica para el valor indicado
+func (t MetricThreshold) evaluate(value float64) string {
+	exceeds := func(limit float64) bool {
+		if t.Below {
+			return value <= limit
+		}
+		return value >= limit
+	}
+
+	switch {
+	case t.Error > 0 && exceeds(t.Error):
+		return "error"
+	case t.Warning > 0 && exceeds(t.Warning):
+		return "warning"
+	}
+	return "ok"
+}
+
+// healthReport acumula métricas de salud con su estado individual
+// Las métricas y estados se guardan como mapas de escalares para que queden en el historial
+type healthReport struct {
+	metrics  map[string]interface{}
+	statuses map[string]interface{}
+	errors   map[string]interface{}
+	problems []string
+	status   string
+}
+
+// newHealthReport crea un reporte vacío en estado ok
+func newHealthReport() *healthReport {
+	return &healthReport{
+		metrics:  make(map[string]interface{}),
+		statuses: make(map[string]interface{}),
+		errors:   make(map[string]interface{}),
+		status:   "ok",
+	}
+}
+
+// record registra el valor de una métrica y lo evalúa contra sus umbrales
+func (r *healthReport) record(name string, value float64, threshold MetricThreshold) {
+	value = roundMetric(value)
+	r.metrics[name] = value
+
+	status := threshold.evaluate(value)
+	r.statuses[name] = status
+	if status == "ok" {
+		return
+	}
+
+	limit := threshold.Warning
+	if status == "error" {
+		limit = threshold.Error
+	}
+	r.problems = append(r.problems, fmt.Sprintf("%s=%v (umbral %v)", name, value, limit))
+	r.raise(status)
+}
+
+// info registra una métrica informativa sin umbrales
+func (r *healthReport) info(name string, value float64) {
+	r.metrics[name] = roundMetric(value)
+}
+
+// fail registra que una métrica no pudo obtenerse (permisos, versión del servidor)
+func (r *healthReport) fail(name string, err error) {
+	r.errors[name] = err.Error()
+	r.statuses[name] = "unknown"
+	r.problems = append(r.problems, fmt.Sprintf("%s no disponible", name))
+	r.raise("warning")
+}
+
+// raise eleva el estado general del reporte
+func (r *healthReport) raise(status string) {
+	if status == "error" || r.status == "ok" {
+		r.status = status
+	}
+}
+
+// applyTo completa estado, mensaje y metadata del check con el reporte
+func (r *healthReport) applyTo(check *models.Check, okMessage string) {
+	check.Metadata["metrics"] = r.metrics
+	check.Metadata["metric_status"] = r.statuses
+	if len(r.errors) > 0 {
+		check.Metadata["metric_errors"] = r.errors
+	}
+
+	check.Status = r.status
+	if len(r.problems) == 0 {
+		check.Message = okMessage
+		return
+	}
+
+	// Errores primero para que el mensaje destaque lo más grave
+	sort.SliceStable(r.problems, func(i, j int) bool {
+		return r.metricStatus(r.problems[i]) == "error" && r.metricStatus(r.problems[j]) != "error"
+	})
+	check.Message = strings.Join(r.problems, "; ")
+}
+
+// metricStatus obtiene el estado de la métrica a la que refiere un problema
+func (r *healthReport) metricStatus(problem string) string {
+	name := strings.FieldsFunc(problem, func(c rune) bool { return c == '=' || c == ' ' })[0]
+	status, _ := r.statuses[name].(string)
+	return status
+}
+
+// roundMetric redondea a dos decimales para metadata legible
+func roundMetric(value float64) float64 {
+	return math.Round(value*100) / 100
+}
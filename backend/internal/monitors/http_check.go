@@ -2,6 +2,8 @@ package monitors
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptrace"
 	"time"
 
 	"github.com/saltacompra/monitor/internal/models"
@@ -18,6 +20,8 @@ type HTTPCheckConfig struct {
 	SSLWarningDays       int      // Días antes de expiración para warning
 	TimeoutWarningMs     int64    // Umbral de ms para warning
 	TimeoutErrorMs       int64    // Umbral de ms para error
+	TTFBWarningMs        int64    // Umbral de ms hasta el primer byte para warning (0 = deshabilitado)
+	TTFBErrorMs          int64    // Umbral de ms hasta el primer byte para error (0 = deshabilitado)
 	TimeoutSeconds       int      // Timeout de la petición HTTP
 }
 
@@ -38,19 +42,42 @@ func CheckHTTP(config HTTPCheckConfig) models.Check {
 	}
 	client := getHTTPClient(timeout, config.SkipSSLVerification)
 
-	// Realizar petición HTTP
-	start := time.Now()
-	resp, err := client.Get(config.URL)
-	elapsed := time.Since(start).Milliseconds()
+	req, err := http.NewRequest(http.MethodGet, config.URL, nil)
+	if err != nil {
+		check.Status = "error"
+		check.Message = "URL inválida: " + err.Error()
+		return check
+	}
+
+	// Realizar petición HTTP registrando tiempos por fase (DNS, TCP, TLS, TTFB)
+	timing := &httpTiming{}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), newHTTPTrace(timing)))
+
+	timing.markStart()
+	deadline := time.Now().Add(time.Duration(timeout) * time.Second)
+	resp, err := client.Do(req)
+	elapsed := timing.markResponse()
 	check.ResponseTime = elapsed
 
 	if err != nil {
+		check.Metadata["timing"] = timing.metadata()
+		// Diagnosticar en qué capa está la falla (DNS, TCP, TLS o HTTP)
+		// con el tiempo que resta del plazo de la petición, para no duplicar la duración del check
+		budget := diagnosisBudget(deadline, time.Duration(timeout)*time.Second)
+		diagnosis := diagnoseHTTPFailure(config.URL, budget, config.SkipSSLVerification, err)
+		diagnosis.applyTo(check.Metadata)
 		check.Status = "error"
-		check.Message = "No se pudo conectar: " + err.Error()
+		check.Message = fmt.Sprintf("No se pudo conectar (%s): %s", diagnosis.RootCause, err.Error())
 		return check
 	}
 	defer resp.Body.Close()
 
+	// Leer el body completo para medir también la transferencia de contenido
+	// (el tiempo de respuesta del check es hasta la respuesta, sin la lectura del body)
+	body, bodyErr := readResponseBody(resp)
+	timing.markBodyDone()
+	check.Metadata["timing"] = timing.metadata()
+
 	// Lista de problemas encontrados
 	var issues []string
 	worstStatus := "ok"
@@ -59,6 +86,8 @@ func CheckHTTP(config HTTPCheckConfig) models.Check {
 	if resp.StatusCode >= 500 {
 		issues = append(issues, fmt.Sprintf("Error del servidor (HTTP %d)", resp.StatusCode))
 		worstStatus = "error"
+		check.Metadata["failure_layer"] = "http"
+		check.Metadata["failure_root_cause"] = fmt.Sprintf("Error de la aplicación (HTTP %d)", resp.StatusCode)
 	} else if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		issues = append(issues, fmt.Sprintf("Código HTTP inesperado: %d", resp.StatusCode))
 		if worstStatus == "ok" {
@@ -80,11 +109,25 @@ func CheckHTTP(config HTTPCheckConfig) models.Check {
 		}
 	}
 
+	// 2b. Verificar tiempo hasta el primer byte (lentitud de la aplicación vs. transferencia)
+	ttfb := timing.ttfbMs()
+	ttfbStatus := evaluateResponseTime(ttfb, config.TTFBWarningMs, config.TTFBErrorMs)
+	check.Metadata["ttfb_status"] = ttfbStatus
+
+	if ttfbStatus == "error" {
+		issues = append(issues, fmt.Sprintf("Tiempo hasta el primer byte muy alto: %dms", ttfb))
+		worstStatus = "error"
+	} else if ttfbStatus == "warning" {
+		issues = append(issues, fmt.Sprintf("Tiempo hasta el primer byte elevado: %dms", ttfb))
+		if worstStatus == "ok" {
+			worstStatus = "warning"
+		}
+	}
+
 	// 3. Verificar contenido esperado
 	if len(config.ExpectedContent) > 0 && resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		body, err := readResponseBody(resp)
-		if err != nil {
-			issues = append(issues, "Error al leer contenido: "+err.Error())
+		if bodyErr != nil {
+			issues = append(issues, "Error al leer contenido: "+bodyErr.Error())
 			worstStatus = "error"
 		} else {
 			contentOk, contentMsg := checkContentPresence(body, config.ExpectedContent)
@@ -0,0 +1,176 @@
+package monitors
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// httpHopTiming instantes de cada fase de un salto de la petición (la original o una redirección)
+type httpHopTiming struct {
+	hostPort     string
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	reusedConn   bool
+}
+
+// httpTiming registra los tiempos de una petición HTTP, separados por salto de redirección
+// Los callbacks de httptrace pueden ejecutarse en goroutines de dial concurrentes (Happy Eyeballs)
+// e incluso después de que client.Do retorna, por eso todo acceso pasa por el mutex
+type httpTiming struct {
+	mu         sync.Mutex
+	start      time.Time
+	responseAt time.Time
+	bodyDone   time.Time
+	hops       []*httpHopTiming
+}
+
+// newHTTPTrace crea un ClientTrace que completa los tiempos de timing
+// Cada GetConn inicia un salto nuevo: el cliente reutiliza el contexto (y el trace) en las redirecciones
+func newHTTPTrace(timing *httpTiming) *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			timing.mu.Lock()
+			defer timing.mu.Unlock()
+			timing.hops = append(timing.hops, &httpHopTiming{hostPort: hostPort, start: time.Now()})
+		},
+		DNSStart: func(httptrace.DNSStartInfo) { timing.record(func(hop *httpHopTiming) { hop.dnsStart = time.Now() }) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { timing.record(func(hop *httpHopTiming) { hop.dnsDone = time.Now() }) },
+		ConnectStart: func(network, addr string) {
+			// Con múltiples IPs se registra el primer intento
+			timing.record(func(hop *httpHopTiming) {
+				if hop.connectStart.IsZero() {
+					hop.connectStart = time.Now()
+				}
+			})
+		},
+		ConnectDone: func(network, addr string, err error) {
+			// Con múltiples IPs se registra la primera conexión exitosa
+			timing.record(func(hop *httpHopTiming) {
+				if err == nil && hop.connectDone.IsZero() {
+					hop.connectDone = time.Now()
+				}
+			})
+		},
+		TLSHandshakeStart: func() { timing.record(func(hop *httpHopTiming) { hop.tlsStart = time.Now() }) },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			timing.record(func(hop *httpHopTiming) { hop.tlsDone = time.Now() })
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			timing.record(func(hop *httpHopTiming) { hop.reusedConn = info.Reused })
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			timing.record(func(hop *httpHopTiming) { hop.wroteRequest = time.Now() })
+		},
+		GotFirstResponseByte: func() { timing.record(func(hop *httpHopTiming) { hop.firstByte = time.Now() }) },
+	}
+}
+
+// record aplica un cambio sobre el salto en curso (ignora eventos previos a GetConn)
+func (t *httpTiming) record(update func(hop *httpHopTiming)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.hops) == 0 {
+		return
+	}
+	update(t.hops[len(t.hops)-1])
+}
+
+// markStart registra el inicio de la petición
+func (t *httpTiming) markStart() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.start = time.Now()
+}
+
+// markResponse registra la llegada de la respuesta (headers) y retorna el tiempo de respuesta en ms
+func (t *httpTiming) markResponse() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.responseAt = time.Now()
+	return phaseMs(t.start, t.responseAt)
+}
+
+// markBodyDone registra el fin de la lectura del body
+func (t *httpTiming) markBodyDone() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.bodyDone = time.Now()
+}
+
+// ttfbMs retorna el tiempo hasta el primer byte de la respuesta final, medido desde el inicio
+// (incluye las redirecciones, es lo que espera el usuario)
+func (t *httpTiming) ttfbMs() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.hops) == 0 {
+		return 0
+	}
+	return phaseMs(t.start, t.hops[len(t.hops)-1].firstByte)
+}
+
+// metadata retorna el desglose de tiempos en ms para la metadata del check
+// Las fases (DNS, TCP, TLS, servidor) corresponden al último salto; con redirecciones
+// se agrega el detalle de cada salto. Las fases que no ocurrieron se reportan en 0
+func (t *httpTiming) metadata() map[string]interface{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	final := &httpHopTiming{}
+	if len(t.hops) > 0 {
+		final = t.hops[len(t.hops)-1]
+	}
+
+	result := final.phases()
+	result["ttfb_ms"] = phaseMs(t.start, final.firstByte)
+	result["response_ms"] = phaseMs(t.start, t.responseAt)
+	result["transfer_ms"] = phaseMs(final.firstByte, t.bodyDone)
+	result["total_ms"] = phaseMs(t.start, t.bodyDone)
+	result["redirects"] = 0
+
+	if len(t.hops) > 1 {
+		hops := make([]map[string]interface{}, 0, len(t.hops))
+		for _, hop := range t.hops {
+			phases := hop.phases()
+			phases["host"] = hop.hostPort
+			phases["ttfb_ms"] = phaseMs(hop.start, hop.firstByte)
+			hops = append(hops, phases)
+		}
+		result["redirects"] = len(t.hops) - 1
+		result["hops"] = hops
+	}
+	return result
+}
+
+// phases retorna la duración de las fases de conexión y servidor de un salto
+func (h *httpHopTiming) phases() map[string]interface{} {
+	// El servidor procesa la petición entre que terminamos de escribirla y el primer byte
+	serverStart := h.wroteRequest
+	if serverStart.IsZero() {
+		serverStart = h.start
+	}
+
+	return map[string]interface{}{
+		"dns_ms":            phaseMs(h.dnsStart, h.dnsDone),
+		"connect_ms":        phaseMs(h.connectStart, h.connectDone),
+		"tls_ms":            phaseMs(h.tlsStart, h.tlsDone),
+		"server_ms":         phaseMs(serverStart, h.firstByte),
+		"connection_reused": h.reusedConn,
+	}
+}
+
+// phaseMs calcula la duración entre dos instantes en ms (0 si alguno falta)
+func phaseMs(from time.Time, to time.Time) int64 {
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		return 0
+	}
+	return to.Sub(from).Milliseconds()
+}
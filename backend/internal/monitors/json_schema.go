@@ -0,0 +1,366 @@
+package monitors
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// SchemaViolation describe un valor que no cumple el schema
+type SchemaViolation struct {
+	Pointer string `json:"pointer"` // JSON Pointer al campo que falla (ej: /items/0/fecha)
+	Message string `json:"message"`
+}
+
+// schemaValidator valida documentos JSON contra un subconjunto de JSON Schema / OpenAPI 3
+// Soporta: type, nullable, properties, required, additionalProperties, items, enum, const,
+// rangos numéricos, longitudes, pattern, format, allOf/anyOf/oneOf y $ref locales
+type schemaValidator struct {
+	root       interface{} // Documento completo para resolver $ref ("#/components/schemas/...")
+	violations []SchemaViolation
+	maxReports int
+}
+
+// maxRefDepth cantidad máxima de $ref resueltos seguidos sin avanzar en el valor
+// Evita ciclos infinitos en $ref recursivos (A → allOf B → A); al bajar a una propiedad o
+// un elemento el contador vuelve a 0, así los schemas recursivos (árboles) validan a cualquier profundidad
+const maxRefDepth = 32
+
+// validateJSONSchema valida value contra schema y retorna las violaciones encontradas
+func validateJSONSchema(root interface{}, schema interface{}, value interface{}, maxReports int) []SchemaViolation {
+	v := &schemaValidator{root: root, maxReports: maxReports}
+	v.validate(schema, value, "", 0)
+	return v.violations
+}
+
+// resolveJSONPointer obtiene el nodo de un documento según un JSON Pointer ("#/a/b" o "/a/b")
+func resolveJSONPointer(doc interface{}, pointer string) (interface{}, error) {
+	pointer = strings.TrimPrefix(pointer, "#")
+	if pointer == "" {
+		return doc, nil
+	}
+
+	node := doc
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch current := node.(type) {
+		case map[string]interface{}:
+			next, exists := current[token]
+			if !exists {
+				return nil, fmt.Errorf("%s no existe", pointer)
+			}
+			node = next
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(current) {
+				return nil, fmt.Errorf("índice %s inválido en %s", token, pointer)
+			}
+			node = current[index]
+		default:
+			return nil, fmt.Errorf("%s no existe", pointer)
+		}
+	}
+	return node, nil
+}
+
+// report registra una violación respetando el máximo configurado
+func (v *schemaValidator) report(pointer string, format string, args ...interface{}) {
+	if v.maxReports > 0 && len(v.violations) >= v.maxReports {
+		return
+	}
+	if pointer == "" {
+		pointer = "/"
+	}
+	v.violations = append(v.violations, SchemaViolation{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+}
+
+// validate valida un valor contra un schema en la posición indicada por pointer
+func (v *schemaValidator) validate(schemaNode interface{}, value interface{}, pointer string, refDepth int) {
+	schema, ok := schemaNode.(map[string]interface{})
+	if !ok {
+		// true/false como schema (JSON Schema draft 6+)
+		if allowed, isBool := schemaNode.(bool); isBool && !allowed {
+			v.report(pointer, "valor no permitido")
+		}
+		return
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		if refDepth >= maxRefDepth {
+			v.report(pointer, "profundidad máxima de $ref alcanzada (%s)", ref)
+			return
+		}
+		target, err := resolveJSONPointer(v.root, ref)
+		if err != nil {
+			v.report(pointer, "$ref no resuelto: %s", err.Error())
+			return
+		}
+		v.validate(target, value, pointer, refDepth+1)
+		return
+	}
+
+	// Composición
+	if all, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			v.validate(sub, value, pointer, refDepth)
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		if v.countMatches(anyOf, value, pointer, refDepth) == 0 {
+			v.report(pointer, "no cumple ninguna alternativa de anyOf")
+		}
+	}
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		if matches := v.countMatches(oneOf, value, pointer, refDepth); matches != 1 {
+			v.report(pointer, "debe cumplir exactamente una alternativa de oneOf (cumple %d)", matches)
+		}
+	}
+
+	if value == nil {
+		if nullable, _ := schema["nullable"].(bool); nullable || schemaAllowsType(schema, "null") {
+			return
+		}
+		if _, hasType := schema["type"]; hasType {
+			v.report(pointer, "valor null no permitido")
+		}
+		return
+	}
+
+	if !v.validateType(schema, value, pointer) {
+		return
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, candidate := range enum {
+			if reflect.DeepEqual(candidate, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.report(pointer, "valor %v fuera de enum %v", value, enum)
+		}
+	}
+	if constant, ok := schema["const"]; ok && !reflect.DeepEqual(constant, value) {
+		v.report(pointer, "valor %v distinto del esperado %v", value, constant)
+	}
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(schema, typed, pointer)
+	case []interface{}:
+		v.validateArray(schema, typed, pointer)
+	case string:
+		v.validateString(schema, typed, pointer)
+	case float64:
+		v.validateNumber(schema, typed, pointer)
+	}
+}
+
+// countMatches cuenta cuántos sub-schemas acepta el valor (sin registrar sus violaciones)
+func (v *schemaValidator) countMatches(schemas []interface{}, value interface{}, pointer string, refDepth int) int {
+	matches := 0
+	for _, sub := range schemas {
+		probe := &schemaValidator{root: v.root, maxReports: 1}
+		probe.validate(sub, value, pointer, refDepth)
+		if len(probe.violations) == 0 {
+			matches++
+		}
+	}
+	return matches
+}
+
+// validateType verifica la palabra clave "type" (string o lista de tipos)
+func (v *schemaValidator) validateType(schema map[string]interface{}, value interface{}, pointer string) bool {
+	var types []string
+	switch t := schema["type"].(type) {
+	case string:
+		types = []string{t}
+	case []interface{}:
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+	default:
+		return true
+	}
+
+	actual := jsonTypeOf(value)
+	for _, expected := range types {
+		if expected == actual || (expected == "number" && actual == "integer") {
+			return true
+		}
+	}
+	v.report(pointer, "tipo %s, se esperaba %s", actual, strings.Join(types, "|"))
+	return false
+}
+
+// validateObject valida propiedades, required y additionalProperties
+func (v *schemaValidator) validateObject(schema map[string]interface{}, object map[string]interface{}, pointer string) {
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			if field, ok := name.(string); ok {
+				if _, exists := object[field]; !exists {
+					v.report(pointer+"/"+escapeJSONPointer(field), "campo requerido ausente")
+				}
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+
+	// Recorrer en orden estable para reportes reproducibles
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		childPointer := pointer + "/" + escapeJSONPointer(key)
+		if propSchema, exists := properties[key]; exists {
+			v.validate(propSchema, object[key], childPointer, 0)
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				v.report(childPointer, "propiedad no permitida")
+			}
+		case map[string]interface{}:
+			v.validate(additional, object[key], childPointer, 0)
+		}
+	}
+}
+
+// validateArray valida items y cantidad de elementos
+func (v *schemaValidator) validateArray(schema map[string]interface{}, array []interface{}, pointer string) {
+	if min, ok := schema["minItems"].(float64); ok && float64(len(array)) < min {
+		v.report(pointer, "%d elementos, mínimo %v", len(array), min)
+	}
+	if max, ok := schema["maxItems"].(float64); ok && float64(len(array)) > max {
+		v.report(pointer, "%d elementos, máximo %v", len(array), max)
+	}
+	if items, ok := schema["items"]; ok {
+		for i, item := range array {
+			v.validate(items, item, fmt.Sprintf("%s/%d", pointer, i), 0)
+		}
+	}
+}
+
+// validateString valida longitudes, pattern y format
+func (v *schemaValidator) validateString(schema map[string]interface{}, value string, pointer string) {
+	length := float64(utf8.RuneCountInString(value))
+	if min, ok := schema["minLength"].(float64); ok && length < min {
+		v.report(pointer, "longitud %v menor al mínimo %v", length, min)
+	}
+	if max, ok := schema["maxLength"].(float64); ok && length > max {
+		v.report(pointer, "longitud %v mayor al máximo %v", length, max)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		re, err := regexp.Compile(pattern)
+		if err == nil && !re.MatchString(value) {
+			v.report(pointer, "no cumple el patrón %s", pattern)
+		}
+	}
+	if format, ok := schema["format"].(string); ok && !matchesStringFormat(format, value) {
+		v.report(pointer, "formato %s inválido: %q", format, value)
+	}
+}
+
+// validateNumber valida rangos numéricos y tipo integer
+func (v *schemaValidator) validateNumber(schema map[string]interface{}, value float64, pointer string) {
+	if min, ok := schema["minimum"].(float64); ok {
+		if exclusive, _ := schema["exclusiveMinimum"].(bool); (exclusive && value <= min) || value < min {
+			v.report(pointer, "valor %v menor al mínimo %v", value, min)
+		}
+	}
+	if max, ok := schema["maximum"].(float64); ok {
+		if exclusive, _ := schema["exclusiveMaximum"].(bool); (exclusive && value >= max) || value > max {
+			v.report(pointer, "valor %v mayor al máximo %v", value, max)
+		}
+	}
+	// JSON Schema 2019+: exclusiveMinimum/exclusiveMaximum numéricos
+	if min, ok := schema["exclusiveMinimum"].(float64); ok && value <= min {
+		v.report(pointer, "valor %v debe ser mayor a %v", value, min)
+	}
+	if max, ok := schema["exclusiveMaximum"].(float64); ok && value >= max {
+		v.report(pointer, "valor %v debe ser menor a %v", value, max)
+	}
+}
+
+// schemaAllowsType indica si "type" incluye el tipo indicado
+func schemaAllowsType(schema map[string]interface{}, typeName string) bool {
+	switch t := schema["type"].(type) {
+	case string:
+		return t == typeName
+	case []interface{}:
+		for _, item := range t {
+			if item == typeName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsonTypeOf retorna el tipo JSON Schema de un valor decodificado con encoding/json
+func jsonTypeOf(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if typed == math.Trunc(typed) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "unknown"
+}
+
+// uuidPattern formato UUID canónico
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// matchesStringFormat valida los formats más usados (los desconocidos se aceptan)
+func matchesStringFormat(format string, value string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	case "email":
+		_, err := mail.ParseAddress(value)
+		return err == nil
+	case "uri":
+		parsed, err := url.Parse(value)
+		return err == nil && parsed.Scheme != ""
+	case "uuid":
+		return uuidPattern.MatchString(value)
+	}
+	return true
+}
+
+// escapeJSONPointer escapa un nombre de propiedad para usarlo en un JSON Pointer
+func escapeJSONPointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
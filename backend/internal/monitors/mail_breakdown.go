@@ -0,0 +1,308 @@
+package monitors
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MailCategory agrupa correos por patrones de asunto o destinatario (ej: "notificación de adjudicación")
+// Un correo puede pertenecer a varias categorías; los patrones usan la sintaxis LIKE de SQL Server
+type MailCategory struct {
+	Name                  string
+	SubjectLike           string // Patrón LIKE sobre el asunto (opcional, ej: "%adjudicaci%")
+	RecipientLike         string // Patrón LIKE sobre los destinatarios (opcional)
+	WarningFailedPercent  int    // % de fallidos para warning (0 = umbral general)
+	ErrorFailedPercent    int    // % de fallidos para error (0 = umbral general)
+	MaxMinutesWithoutSent int    // Minutos sin envíos de la categoría para warning (0 = no se evalúa)
+}
+
+// mailStats conteo de correos por estado
+type mailStats struct {
+	total    int
+	sent     int
+	unsent   int
+	failed   int
+	retrying int
+	lastSent *time.Time
+}
+
+// addCount suma los correos de un sent_status de sysmail_mailitems
+// (0 = unsent, 1 = sent, 2 = failed, 3 = retrying)
+func (s *mailStats) addCount(sentStatus int, count int, lastSent *time.Time) {
+	s.total += count
+	switch sentStatus {
+	case 0:
+		s.unsent += count
+	case 1:
+		s.sent += count
+		if lastSent != nil && (s.lastSent == nil || lastSent.After(*s.lastSent)) {
+			s.lastSent = lastSent
+		}
+	case 3:
+		s.retrying += count
+	default:
+		s.failed += count
+	}
+}
+
+// failedPercent porcentaje de correos fallidos
+func (s *mailStats) failedPercent() float64 {
+	if s.total == 0 {
+		return 0
+	}
+	return float64(s.failed) / float64(s.total) * 100
+}
+
+// metadata representa las estadísticas para la metadata del check
+func (s *mailStats) metadata() map[string]interface{} {
+	result := map[string]interface{}{
+		"total":             s.total,
+		"sent":              s.sent,
+		"unsent":            s.unsent,
+		"failed":            s.failed,
+		"retrying":          s.retrying,
+		"failed_percentage": fmt.Sprintf("%.2f", s.failedPercent()),
+	}
+	if s.lastSent != nil {
+		result["last_sent_time"] = s.lastSent.Format(time.RFC3339)
+	}
+	return result
+}
+
+// mailCategoryStats categoría con sus estadísticas
+type mailCategoryStats struct {
+	MailCategory
+	stats mailStats
+}
+
+// mailBreakdown estadísticas de correos por perfil, cuenta y categoría
+type mailBreakdown struct {
+	profiles   map[string]*mailStats
+	accounts   map[string]*mailStats
+	categories []*mailCategoryStats
+}
+
+// newMailBreakdown crea un desglose vacío para las categorías indicadas
+// Las categorías sin patrones se ignoran
+func newMailBreakdown(categories []MailCategory) *mailBreakdown {
+	breakdown := &mailBreakdown{
+		profiles: make(map[string]*mailStats),
+		accounts: make(map[string]*mailStats),
+	}
+	for _, category := range categories {
+		if category.SubjectLike == "" && category.RecipientLike == "" {
+			continue
+		}
+		breakdown.categories = append(breakdown.categories, &mailCategoryStats{MailCategory: category})
+	}
+	return breakdown
+}
+
+// load carga el desglose de la ventana indicada agregando en SQL
+// dateColumn es la columna de sysmail_mailitems usada para la ventana
+// sent_account_id solo se completa en los correos enviados: para los fallidos y pendientes la cuenta
+// se toma del último evento de sysmail_log del correo o, si no hay, de la primera cuenta del perfil
+func (b *mailBreakdown) load(db *sql.DB, dateColumn string, windowMinutes int) error {
+	query := fmt.Sprintf(`
+		SELECT
+			COALESCE(p.name, ''),
+			COALESCE(a.name, ''),
+			m.sent_status,
+			COUNT(*),
+			MAX(m.sent_date)
+		FROM msdb.dbo.sysmail_mailitems m
+		LEFT JOIN msdb.dbo.sysmail_profile p ON p.profile_id = m.profile_id
+		OUTER APPLY (
+			SELECT TOP 1 l.account_id
+			FROM msdb.dbo.sysmail_log l
+			WHERE l.mailitem_id = m.mailitem_id AND l.account_id IS NOT NULL
+			ORDER BY l.log_id DESC
+		) lg
+		OUTER APPLY (
+			SELECT TOP 1 pa.account_id
+			FROM msdb.dbo.sysmail_profileaccount pa
+			WHERE pa.profile_id = m.profile_id
+			ORDER BY pa.sequence_number
+		) pa
+		LEFT JOIN msdb.dbo.sysmail_account a ON a.account_id = COALESCE(m.sent_account_id, lg.account_id, pa.account_id)
+		WHERE %s >= DATEADD(minute, -@window, GETDATE())
+		GROUP BY p.name, a.name, m.sent_status
+	`, dateColumn)
+
+	rows, err := db.Query(query, sql.Named("window", windowMinutes))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var profile, account string
+		var sentStatus, count int
+		var lastSent *time.Time
+		if err := rows.Scan(&profile, &account, &sentStatus, &count, &lastSent); err != nil {
+			return err
+		}
+		if profile == "" {
+			profile = "(sin perfil)"
+		}
+		if account == "" {
+			account = "(sin cuenta asignada)"
+		}
+		if b.profiles[profile] == nil {
+			b.profiles[profile] = &mailStats{}
+		}
+		b.profiles[profile].addCount(sentStatus, count, lastSent)
+		if b.accounts[account] == nil {
+			b.accounts[account] = &mailStats{}
+		}
+		b.accounts[account].addCount(sentStatus, count, lastSent)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return b.loadCategories(db, dateColumn, windowMinutes)
+}
+
+// loadCategories cuenta los correos de cada categoría con una consulta por UNION ALL
+// Los patrones se envían como parámetros (deben cumplirse todos los definidos)
+func (b *mailBreakdown) loadCategories(db *sql.DB, dateColumn string, windowMinutes int) error {
+	if len(b.categories) == 0 {
+		return nil
+	}
+
+	args := []interface{}{sql.Named("window", windowMinutes)}
+	parts := make([]string, 0, len(b.categories))
+	for i, category := range b.categories {
+		conditions := []string{fmt.Sprintf("%s >= DATEADD(minute, -@window, GETDATE())", dateColumn)}
+		if category.SubjectLike != "" {
+			conditions = append(conditions, fmt.Sprintf("m.subject LIKE @subject%d", i))
+			args = append(args, sql.Named(fmt.Sprintf("subject%d", i), category.SubjectLike))
+		}
+		if category.RecipientLike != "" {
+			conditions = append(conditions, fmt.Sprintf("m.recipients LIKE @recipient%d", i))
+			args = append(args, sql.Named(fmt.Sprintf("recipient%d", i), category.RecipientLike))
+		}
+		parts = append(parts, fmt.Sprintf(`
+		SELECT %d, m.sent_status, COUNT(*), MAX(m.sent_date)
+		FROM msdb.dbo.sysmail_mailitems m
+		WHERE %s
+		GROUP BY m.sent_status`, i, strings.Join(conditions, " AND ")))
+	}
+
+	rows, err := db.Query(strings.Join(parts, "\n\t\tUNION ALL"), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var index, sentStatus, count int
+		var lastSent *time.Time
+		if err := rows.Scan(&index, &sentStatus, &count, &lastSent); err != nil {
+			return err
+		}
+		if index >= 0 && index < len(b.categories) {
+			b.categories[index].stats.addCount(sentStatus, count, lastSent)
+		}
+	}
+	return rows.Err()
+}
+
+// evaluate evalúa perfiles y categorías contra sus umbrales
+// Retorna el peor estado y la descripción de los grupos con problemas
+func (b *mailBreakdown) evaluate(config MailCheckConfig, now time.Time) (string, []string) {
+	status := "ok"
+	var problems []string
+	raise := func(newStatus string, problem string) {
+		problems = append(problems, problem)
+		if newStatus == "error" || status == "ok" {
+			status = newStatus
+		}
+	}
+
+	// Perfiles: umbrales generales
+	for _, name := range sortedMailGroups(b.profiles) {
+		stats := b.profiles[name]
+		if groupStatus := mailFailureStatus(stats, config.DailyWarningFailedPercent, config.DailyErrorFailedPercent, config.MinFailureSamples); groupStatus != "ok" {
+			raise(groupStatus, fmt.Sprintf("perfil %s: %.1f%% fallidos (%d de %d)", name, stats.failedPercent(), stats.failed, stats.total))
+		}
+	}
+
+	// Categorías: umbrales propios (o generales si no se configuran)
+	for _, category := range b.categories {
+		if category.stats.total == 0 {
+			continue
+		}
+		warning, errorPercent := category.WarningFailedPercent, category.ErrorFailedPercent
+		if warning == 0 {
+			warning = config.DailyWarningFailedPercent
+		}
+		if errorPercent == 0 {
+			errorPercent = config.DailyErrorFailedPercent
+		}
+
+		stats := &category.stats
+		if groupStatus := mailFailureStatus(stats, warning, errorPercent, config.MinFailureSamples); groupStatus != "ok" {
+			raise(groupStatus, fmt.Sprintf("%s: %.1f%% fallidos (%d de %d)", category.Name, stats.failedPercent(), stats.failed, stats.total))
+			continue
+		}
+		if category.MaxMinutesWithoutSent > 0 {
+			if stats.lastSent == nil {
+				raise("warning", fmt.Sprintf("%s: sin envíos (%d pendientes)", category.Name, stats.unsent+stats.retrying))
+			} else if minutes := int64(now.Sub(*stats.lastSent).Minutes()); minutes > int64(category.MaxMinutesWithoutSent) {
+				raise("warning", fmt.Sprintf("%s: sin envíos hace %d min", category.Name, minutes))
+			}
+		}
+	}
+
+	return status, problems
+}
+
+// metadata representa el desglose para la metadata del check
+func (b *mailBreakdown) metadata() (profiles, accounts, categories []map[string]interface{}) {
+	for _, name := range sortedMailGroups(b.profiles) {
+		entry := b.profiles[name].metadata()
+		entry["profile"] = name
+		profiles = append(profiles, entry)
+	}
+	for _, name := range sortedMailGroups(b.accounts) {
+		entry := b.accounts[name].metadata()
+		entry["account"] = name
+		accounts = append(accounts, entry)
+	}
+	for _, category := range b.categories {
+		entry := category.stats.metadata()
+		entry["category"] = category.Name
+		categories = append(categories, entry)
+	}
+	return profiles, accounts, categories
+}
+
+// mailFailureStatus evalúa el % de fallidos de un grupo
+// Con menos de minSamples correos no se evalúa: un solo fallo sobre 2 correos sería un 50%
+func mailFailureStatus(stats *mailStats, warningPercent int, errorPercent int, minSamples int) string {
+	percent := stats.failedPercent()
+	switch {
+	case stats.failed == 0 || stats.total < minSamples:
+		return "ok"
+	case errorPercent > 0 && percent >= float64(errorPercent):
+		return "error"
+	case warningPercent > 0 && percent >= float64(warningPercent):
+		return "warning"
+	}
+	return "ok"
+}
+
+// sortedMailGroups retorna los nombres de los grupos ordenados
+func sortedMailGroups(groups map[string]*mailStats) []string {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,226 @@
+package monitors
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// mailErrorEventsQuery errores de Database Mail de la ventana agrupados en SQL por descripción y cuenta
+// La cuenta se toma del evento o, si falta, del correo fallido asociado. La fecha que Database Mail
+// incluye en cada descripción ("(2024-05-01T10:00:00)") se quita antes de agrupar; el resto de la
+// normalización se completa en Go, que vuelve a unir los grupos equivalentes
+const mailErrorEventsQuery = `
+	WITH events AS (
+		SELECT
+			l.log_date,
+			CAST(CASE WHEN PATINDEX(@datePattern, l.description) > 0
+				THEN STUFF(l.description, PATINDEX(@datePattern, l.description), 19, '')
+				ELSE l.description END AS nvarchar(2000)) AS description,
+			COALESCE(a.name, '') AS account
+		FROM msdb.dbo.sysmail_event_log l
+		LEFT JOIN msdb.dbo.sysmail_faileditems f ON f.mailitem_id = l.mailitem_id
+		LEFT JOIN msdb.dbo.sysmail_account a ON a.account_id = COALESCE(l.account_id, f.sent_account_id)
+		WHERE l.event_type = 'error' AND l.log_date >= DATEADD(minute, -@window, GETDATE())
+	)
+	SELECT TOP 200 description, account, COUNT(*), MIN(log_date), MAX(log_date)
+	FROM events
+	GROUP BY description, account
+	ORDER BY MAX(log_date) DESC
+`
+
+// mailLogDatePattern patrón PATINDEX de la fecha ISO que Database Mail agrega a las descripciones
+const mailLogDatePattern = "%[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9]T[0-9][0-9]:[0-9][0-9]:[0-9][0-9]%"
+
+// mailFailedItemsQuery últimos correos fallidos con el error registrado para cada uno
+// sent_account_id es NULL en los correos fallidos: la cuenta se toma del último evento del correo
+const mailFailedItemsQuery = `
+	SELECT TOP 10
+		f.mailitem_id,
+		COALESCE(f.recipients, ''),
+		COALESCE(f.subject, ''),
+		f.last_mod_date,
+		COALESCE(a.name, ''),
+		COALESCE(e.description, '')
+	FROM msdb.dbo.sysmail_faileditems f
+	OUTER APPLY (
+		SELECT TOP 1 l.description
+		FROM msdb.dbo.sysmail_event_log l
+		WHERE l.mailitem_id = f.mailitem_id AND l.event_type = 'error'
+		ORDER BY l.log_id DESC
+	) e
+	OUTER APPLY (
+		SELECT TOP 1 l.account_id
+		FROM msdb.dbo.sysmail_event_log l
+		WHERE l.mailitem_id = f.mailitem_id AND l.account_id IS NOT NULL
+		ORDER BY l.log_id DESC
+	) la
+	LEFT JOIN msdb.dbo.sysmail_account a ON a.account_id = COALESCE(f.sent_account_id, la.account_id)
+	WHERE f.last_mod_date >= DATEADD(minute, -@window, GETDATE())
+	ORDER BY f.mailitem_id DESC
+`
+
+// maxReportedMailErrors cantidad máxima de errores distintos reportados
+const maxReportedMailErrors = 5
+
+// emailPattern detecta direcciones de correo en textos del log
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// mailLogVariablePattern fechas e identificadores que hacen única cada descripción del log
+var mailLogVariablePattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?|\bmailitem_id\s*\d+`)
+
+// emptyParenthesesPattern paréntesis que quedan vacíos al quitar fechas
+var emptyParenthesesPattern = regexp.MustCompile(`\s*\(\s*\)`)
+
+// mailErrorGroup errores con la misma descripción normalizada
+type mailErrorGroup struct {
+	description string
+	occurrences int
+	accounts    map[string]bool
+	first       time.Time
+	last        time.Time
+}
+
+// loadMailErrorDetails obtiene los errores recientes de Database Mail agrupados por descripción
+// y los últimos correos fallidos de la ventana indicada, con destinatarios y asuntos enmascarados
+func loadMailErrorDetails(db *sql.DB, windowMinutes int) ([]map[string]interface{}, []map[string]interface{}, error) {
+	rows, err := db.Query(mailErrorEventsQuery, sql.Named("window", windowMinutes), sql.Named("datePattern", mailLogDatePattern))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error al consultar sysmail_event_log: %w", err)
+	}
+	defer rows.Close()
+
+	groups := make(map[string]*mailErrorGroup)
+	for rows.Next() {
+		var description, account string
+		var occurrences int
+		var first, last time.Time
+		if err := rows.Scan(&description, &account, &occurrences, &first, &last); err != nil {
+			return nil, nil, err
+		}
+
+		normalized := normalizeMailLogDescription(description)
+		group, exists := groups[normalized]
+		if !exists {
+			group = &mailErrorGroup{description: normalized, accounts: make(map[string]bool), first: first, last: last}
+			groups[normalized] = group
+		}
+		group.occurrences += occurrences
+		if account != "" {
+			group.accounts[account] = true
+		}
+		if first.Before(group.first) {
+			group.first = first
+		}
+		if last.After(group.last) {
+			group.last = last
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	// Errores más recientes primero
+	sorted := make([]*mailErrorGroup, 0, len(groups))
+	for _, group := range groups {
+		sorted = append(sorted, group)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].last.After(sorted[j].last) })
+	if len(sorted) > maxReportedMailErrors {
+		sorted = sorted[:maxReportedMailErrors]
+	}
+
+	recentErrors := make([]map[string]interface{}, 0, len(sorted))
+	for _, group := range sorted {
+		accounts := make([]string, 0, len(group.accounts))
+		for account := range group.accounts {
+			accounts = append(accounts, account)
+		}
+		sort.Strings(accounts)
+
+		recentErrors = append(recentErrors, map[string]interface{}{
+			"description":      group.description,
+			"occurrences":      group.occurrences,
+			"accounts":         accounts,
+			"first_occurrence": group.first.Format(time.RFC3339),
+			"last_occurrence":  group.last.Format(time.RFC3339),
+		})
+	}
+
+	failedItems, err := loadMailFailedItems(db, windowMinutes)
+	if err != nil {
+		return recentErrors, nil, err
+	}
+	return recentErrors, failedItems, nil
+}
+
+// loadMailFailedItems obtiene los últimos correos fallidos con su error
+func loadMailFailedItems(db *sql.DB, windowMinutes int) ([]map[string]interface{}, error) {
+	rows, err := db.Query(mailFailedItemsQuery, sql.Named("window", windowMinutes))
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar sysmail_faileditems: %w", err)
+	}
+	defer rows.Close()
+
+	items := []map[string]interface{}{}
+	for rows.Next() {
+		var mailitemID int
+		var recipients, subject, account, description string
+		var lastModDate time.Time
+		if err := rows.Scan(&mailitemID, &recipients, &subject, &lastModDate, &account, &description); err != nil {
+			return nil, err
+		}
+		items = append(items, map[string]interface{}{
+			"mailitem_id":   mailitemID,
+			"recipients":    maskRecipients(recipients),
+			"subject":       maskSubject(subject),
+			"account":       account,
+			"last_mod_date": lastModDate.Format(time.RFC3339),
+			"error":         normalizeMailLogDescription(description),
+		})
+	}
+
+	return items, rows.Err()
+}
+
+// normalizeMailLogDescription quita fechas e identificadores y enmascara direcciones
+// para que el mismo error se agrupe y no se expongan destinatarios
+func normalizeMailLogDescription(description string) string {
+	normalized := mailLogVariablePattern.ReplaceAllString(description, "")
+	normalized = emailPattern.ReplaceAllStringFunc(normalized, maskEmail)
+	normalized = emptyParenthesesPattern.ReplaceAllString(normalized, "")
+	return strings.Join(strings.Fields(normalized), " ")
+}
+
+// maskRecipients enmascara una lista de destinatarios separada por ';' o ','
+func maskRecipients(recipients string) string {
+	return emailPattern.ReplaceAllStringFunc(recipients, maskEmail)
+}
+
+// maskEmail conserva la primera letra del usuario y el dominio (ej: j***@gmail.com)
+func maskEmail(address string) string {
+	at := strings.LastIndex(address, "@")
+	if at <= 0 {
+		return "***"
+	}
+	first, _ := utf8.DecodeRuneInString(address)
+	return string(first) + "***" + address[at:]
+}
+
+// maskSubject conserva solo el comienzo del asunto para identificar el tipo de correo
+// Los asuntos cortos también se enmascaran (a lo sumo la mitad visible): pueden ser un nombre o un código
+func maskSubject(subject string) string {
+	runes := []rune(strings.TrimSpace(emailPattern.ReplaceAllStringFunc(subject, maskEmail)))
+	if len(runes) == 0 {
+		return ""
+	}
+	visible := len(runes) / 2
+	if visible > 12 {
+		visible = 12
+	}
+	return fmt.Sprintf("%s… (%d caracteres)", string(runes[:visible]), len(runes))
+}
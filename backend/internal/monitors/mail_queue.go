@@ -0,0 +1,117 @@
+package monitors
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultMailWindows ventanas móviles por defecto: 15 minutos, 1 hora y 24 horas
+var defaultMailWindows = []int{15, 60, 1440}
+
+// mailPendingLookbackDays días hacia atrás en que se buscan correos pendientes
+// Evita que correos abandonados hace meses marquen la cola como atascada para siempre
+const mailPendingLookbackDays = 7
+
+// mailQueueSummary estado general de la cola de Database Mail
+type mailQueueSummary struct {
+	now           time.Time  // GETDATE() del servidor
+	lastSent      *time.Time // Último correo enviado
+	lastCreated   *time.Time // Último correo registrado
+	oldestPending *time.Time // Correo unsent/retrying más antiguo
+	pending       int        // Cantidad de correos unsent/retrying
+}
+
+// normalizeMailWindows ordena las ventanas y descarta valores inválidos o repetidos
+func normalizeMailWindows(windows []int) []int {
+	seen := make(map[int]bool)
+	var result []int
+	for _, minutes := range windows {
+		if minutes <= 0 || seen[minutes] {
+			continue
+		}
+		seen[minutes] = true
+		result = append(result, minutes)
+	}
+	if len(result) == 0 {
+		result = append(result, defaultMailWindows...)
+	}
+	sort.Ints(result)
+	return result
+}
+
+// mailWindowLabel representa una ventana en minutos como texto corto (ej: 15m, 1h, 24h)
+func mailWindowLabel(minutes int) string {
+	if minutes%60 == 0 {
+		return fmt.Sprintf("%dh", minutes/60)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
+
+// queryMailQueue obtiene último envío, último registro y el pendiente más antiguo
+// dateColumn es la columna de sysmail_mailitems que indica cuándo se registró el correo
+func queryMailQueue(db *sql.DB, dateColumn string) (mailQueueSummary, error) {
+	query := fmt.Sprintf(`
+		SELECT
+			GETDATE(),
+			(SELECT MAX(sent_date) FROM msdb.dbo.sysmail_mailitems
+				WHERE sent_status = 1 AND sent_date >= DATEADD(day, -@lookback, GETDATE())),
+			(SELECT MAX(%[1]s) FROM msdb.dbo.sysmail_mailitems m),
+			MIN(%[1]s),
+			COUNT(*)
+		FROM msdb.dbo.sysmail_mailitems m
+		WHERE m.sent_status IN (0, 3) AND %[1]s >= DATEADD(day, -@lookback, GETDATE())
+	`, dateColumn)
+
+	var summary mailQueueSummary
+	err := db.QueryRow(query, sql.Named("lookback", mailPendingLookbackDays)).
+		Scan(&summary.now, &summary.lastSent, &summary.lastCreated, &summary.oldestPending, &summary.pending)
+	return summary, err
+}
+
+// queryMailWindows cuenta los correos por estado en cada ventana móvil
+// La agregación se hace en SQL con una columna por ventana
+func queryMailWindows(db *sql.DB, dateColumn string, windows []int) (map[int]*mailStats, error) {
+	columns := make([]string, 0, len(windows))
+	for _, minutes := range windows {
+		columns = append(columns, fmt.Sprintf(
+			"SUM(CASE WHEN %s >= DATEADD(minute, -%d, GETDATE()) THEN 1 ELSE 0 END)", dateColumn, minutes))
+	}
+	query := fmt.Sprintf(`
+		SELECT m.sent_status, %s
+		FROM msdb.dbo.sysmail_mailitems m
+		WHERE %s >= DATEADD(minute, -%d, GETDATE())
+		GROUP BY m.sent_status
+	`, strings.Join(columns, ", "), dateColumn, windows[len(windows)-1])
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make(map[int]*mailStats, len(windows))
+	for _, minutes := range windows {
+		stats[minutes] = &mailStats{}
+	}
+
+	counts := make([]int, len(windows))
+	dest := make([]interface{}, 0, len(windows)+1)
+	var sentStatus int
+	dest = append(dest, &sentStatus)
+	for i := range counts {
+		dest = append(dest, &counts[i])
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		for i, minutes := range windows {
+			stats[minutes].addCount(sentStatus, counts[i], nil)
+		}
+	}
+	return stats, rows.Err()
+}
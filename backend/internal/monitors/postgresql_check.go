@@ -4,9 +4,9 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strconv"
 	"time"
 
-	"github.com/jackc/pgx/v5"
 	"github.com/saltacompra/monitor/internal/models"
 )
 
@@ -26,20 +26,24 @@ type PostgreSQLCheckConfig struct {
 // CheckVPNConnectivity verifica si hay conectividad con la red privada (VPN)
 // Intenta hacer una conexión TCP simple al host especificado con timeout corto
 func CheckVPNConnectivity(host string, port int, timeoutMs int) bool {
+	return dialVPN(host, port, timeoutMs) == nil
+}
+
+// dialVPN intenta una conexión TCP al host de la red privada y retorna el error de conexión
+func dialVPN(host string, port int, timeoutMs int) error {
 	timeout := time.Duration(timeoutMs) * time.Millisecond
-	address := fmt.Sprintf("%s:%d", host, port)
+	address := net.JoinHostPort(host, strconv.Itoa(port))
 
 	conn, err := net.DialTimeout("tcp", address, timeout)
 	if err != nil {
-		return false
+		return err
 	}
-	defer conn.Close()
-
-	return true
+	return conn.Close()
 }
 
-// CheckPostgreSQL verifica el estado de la conexión a PostgreSQL
+// CheckPostgreSQL verifica la conectividad con PostgreSQL (sin consultas de negocio)
 // Primero verifica si hay conectividad VPN antes de intentar conectar
+// Las consultas de negocio se configuran como checks "postgres-query"
 func CheckPostgreSQL(config PostgreSQLCheckConfig) models.Check {
 	check := models.Check{
 		ID:        config.CheckID,
@@ -60,55 +64,47 @@ func CheckPostgreSQL(config PostgreSQLCheckConfig) models.Check {
 		vpnTimeout = 2000 // Default 2 segundos
 	}
 
-	hasVPN := CheckVPNConnectivity(vpnHost, config.Port, vpnTimeout)
-	check.Metadata["vpn_check_host"] = vpnHost
-	check.Metadata["vpn_available"] = hasVPN
-
-	if !hasVPN {
-		check.Status = "error"
-		check.Message = fmt.Sprintf("No hay conectividad con la red privada (VPN). No se puede acceder a %s:%d", vpnHost, config.Port)
-		check.Metadata["error_type"] = "vpn_unavailable"
+	if !checkVPNPrecondition(&check, vpnHost, config.Port, vpnTimeout) {
 		return check
 	}
 
 	// Si hay VPN, proceder con el check de PostgreSQL
-	connString := fmt.Sprintf("postgres://%s:%s@%s:%d/%s",
-		config.User, config.Password, config.Host, config.Port, config.Database)
-
 	start := time.Now()
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	conn, err := pgx.Connect(ctx, connString)
-	if err != nil {
-		elapsed := time.Since(start).Milliseconds()
-		check.ResponseTime = elapsed
-		check.Status = "error"
-		check.Message = "Error al conectar a PostgreSQL: " + err.Error()
-		check.Metadata["error_type"] = "connection_failed"
+	conn, ok := connectPostgreSQL(ctx, &check, PostgreSQLConnection{
+		Host:     config.Host,
+		Port:     config.Port,
+		User:     config.User,
+		Password: config.Password,
+		Database: config.Database,
+	}, time.Duration(vpnTimeout)*time.Millisecond)
+	if !ok {
+		check.ResponseTime = time.Since(start).Milliseconds()
 		return check
 	}
 	defer conn.Close(ctx)
 
-	// Ejecutar query para contar usuarios
-	var userCount int
-	err = conn.QueryRow(ctx, "SELECT COUNT(*) FROM users.usuarios").Scan(&userCount)
+	// Verificar que el servidor responda consultas
+	err := conn.Ping(ctx)
 	elapsed := time.Since(start).Milliseconds()
 	check.ResponseTime = elapsed
 
 	if err != nil {
 		check.Status = "error"
-		check.Message = "Error al ejecutar query de conteo de usuarios: " + err.Error()
+		check.Message = "El servidor PostgreSQL no responde consultas: " + err.Error()
 		check.Metadata["error_type"] = "query_failed"
+		check.Metadata["failure_layer"] = "postgresql"
+		check.Metadata["failure_root_cause"] = classifyPostgreSQLError(err)
 		return check
 	}
 
 	// Todo OK
 	check.Status = "ok"
-	check.Message = fmt.Sprintf("Conexión PostgreSQL exitosa. %d usuarios registrados (%dms)", userCount, elapsed)
+	check.Message = fmt.Sprintf("Conexión PostgreSQL exitosa (%dms)", elapsed)
 	check.Metadata["database"] = config.Database
 	check.Metadata["server_version"] = conn.PgConn().ParameterStatus("server_version")
-	check.Metadata["user_count"] = userCount
 
 	return check
 }
@@ -0,0 +1,374 @@
+package monitors
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/saltacompra/monitor/internal/models"
+)
+
+// PostgreSQLHealthThresholds umbrales por métrica del check de salud de PostgreSQL
+type PostgreSQLHealthThresholds struct {
+	ConnectionsPercent MetricThreshold // % de max_connections en uso
+	LongRunningQueries MetricThreshold // Consultas activas que superan LongQuerySeconds
+	IdleInTransaction  MetricThreshold // Sesiones idle in transaction que superan IdleTxSeconds
+	BlockedSessions    MetricThreshold // Sesiones esperando un lock
+	ReplicationLagSecs MetricThreshold // Segundos de lag de la réplica más atrasada
+	GrowthMBPerDay     MetricThreshold // Crecimiento de la base en MB por día
+	XIDWraparoundPct   MetricThreshold // Edad de transacciones como % del límite de wraparound
+	StaleVacuumTables  MetricThreshold // Tablas con tuplas muertas sin vacuum reciente
+}
+
+// PostgreSQLHealthCheckConfig contiene la configuración del check de salud de PostgreSQL
+type PostgreSQLHealthCheckConfig struct {
+	Connection               PostgreSQLConnection
+	CheckID                  string
+	CheckName                string
+	VPNCheckHost             string // Host para verificar VPN (default: host de PostgreSQL)
+	VPNTimeoutMs             int
+	SizeSnapshotKey          string // Clave del snapshot de tamaño en el StateStore (para calcular crecimiento)
+	LongQuerySeconds         int    // Duración a partir de la cual una consulta es larga
+	IdleTxSeconds            int    // Duración a partir de la cual una sesión idle in transaction se reporta
+	StaleVacuumHours         int    // Horas sin vacuum para considerar una tabla desatendida
+	StaleVacuumMinDeadTuples int    // Tuplas muertas mínimas para considerar una tabla
+	Thresholds               PostgreSQLHealthThresholds
+}
+
+// postgresSizeSnapshot tamaño de la base en un momento dado (para calcular crecimiento)
+type postgresSizeSnapshot struct {
+	SizeBytes int64     `json:"size_bytes"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// postgresSizeHistory snapshots de tamaño guardados, del más antiguo al más reciente
+type postgresSizeHistory struct {
+	Snapshots []postgresSizeSnapshot `json:"snapshots"`
+}
+
+// minGrowthSpan diferencia mínima entre snapshots para calcular el crecimiento diario
+const minGrowthSpan = 12 * time.Hour
+
+// maxSizeSnapshots snapshots conservados (cubren entre 24 y 36 horas)
+const maxSizeSnapshots = 3
+
+// xidWraparoundLimit cantidad de transacciones hasta el wraparound (2^31)
+const xidWraparoundLimit = 2147483648.0
+
+// maxReportedSessions cantidad máxima de sesiones o tablas listadas por categoría
+const maxReportedSessions = 10
+
+// CheckPostgreSQLHealth inspecciona conexiones, sesiones, locks, replicación, crecimiento,
+// wraparound y autovacuum, evaluando cada métrica contra sus propios umbrales
+func CheckPostgreSQLHealth(config PostgreSQLHealthCheckConfig, store StateStore) models.Check {
+	check := models.Check{
+		ID:        config.CheckID,
+		Type:      "postgres-health",
+		Name:      config.CheckName,
+		LastCheck: time.Now(),
+		Metadata:  make(map[string]interface{}),
+	}
+
+	vpnTimeout := config.VPNTimeoutMs
+	if vpnTimeout == 0 {
+		vpnTimeout = 2000 // Default 2 segundos
+	}
+	vpnHost := config.VPNCheckHost
+	if vpnHost == "" {
+		vpnHost = config.Connection.Host
+	}
+	if !checkVPNPrecondition(&check, vpnHost, config.Connection.Port, vpnTimeout) {
+		return check
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	conn, ok := connectPostgreSQL(ctx, &check, config.Connection, time.Duration(vpnTimeout)*time.Millisecond)
+	if !ok {
+		check.ResponseTime = time.Since(start).Milliseconds()
+		return check
+	}
+	defer conn.Close(context.Background())
+
+	report := newHealthReport()
+	thresholds := config.Thresholds
+
+	// Conexiones en uso vs max_connections
+	var connections, maxConnections float64
+	// Solo sesiones de clientes: los procesos de fondo (autovacuum, walwriter, etc.) no consumen max_connections
+	err := conn.QueryRow(ctx, `SELECT (SELECT count(*) FROM pg_stat_activity WHERE backend_type = 'client backend')::float8,
+		current_setting('max_connections')::float8`).
+		Scan(&connections, &maxConnections)
+	if err != nil {
+		report.fail("connections_used_percent", err)
+	} else {
+		report.info("connections", connections)
+		report.info("max_connections", maxConnections)
+		report.record("connections_used_percent", connections/maxConnections*100, thresholds.ConnectionsPercent)
+	}
+
+	// Consultas activas de larga duración
+	sessions, total, err := queryPostgresSessions(ctx, conn, `
+		SELECT pid, COALESCE(usename, ''), COALESCE(datname, ''), state,
+			EXTRACT(EPOCH FROM now() - query_start)::float8, left(query, 200), count(*) OVER ()
+		FROM pg_stat_activity
+		WHERE state = 'active' AND backend_type = 'client backend' AND pid <> pg_backend_pid()
+			AND now() - query_start > make_interval(secs => $1)
+		ORDER BY query_start
+		LIMIT $2`, float64(config.LongQuerySeconds))
+	if err != nil {
+		report.fail("long_running_queries", err)
+	} else {
+		report.record("long_running_queries", float64(total), thresholds.LongRunningQueries)
+		check.Metadata["long_running_sessions"] = sessions
+	}
+
+	// Sesiones idle in transaction (retienen locks y bloquean vacuum)
+	sessions, total, err = queryPostgresSessions(ctx, conn, `
+		SELECT pid, COALESCE(usename, ''), COALESCE(datname, ''), state,
+			EXTRACT(EPOCH FROM now() - state_change)::float8, left(query, 200), count(*) OVER ()
+		FROM pg_stat_activity
+		WHERE state IN ('idle in transaction', 'idle in transaction (aborted)')
+			AND now() - state_change > make_interval(secs => $1)
+		ORDER BY state_change
+		LIMIT $2`, float64(config.IdleTxSeconds))
+	if err != nil {
+		report.fail("idle_in_transaction", err)
+	} else {
+		report.record("idle_in_transaction", float64(total), thresholds.IdleInTransaction)
+		check.Metadata["idle_in_transaction_sessions"] = sessions
+	}
+
+	// Sesiones bloqueadas esperando locks
+	blocked, total, err := queryPostgresBlockedSessions(ctx, conn)
+	if err != nil {
+		report.fail("blocked_sessions", err)
+	} else {
+		report.record("blocked_sessions", float64(total), thresholds.BlockedSessions)
+		check.Metadata["blocked_sessions"] = blocked
+	}
+
+	// Replicación: lag de las réplicas (primario) o del propio servidor (réplica)
+	if err := recordPostgresReplication(ctx, conn, &check, report, thresholds.ReplicationLagSecs); err != nil {
+		report.fail("replication_lag_seconds", err)
+	}
+
+	// Tamaño de la base y crecimiento diario
+	var sizeBytes int64
+	if err := conn.QueryRow(ctx, `SELECT pg_database_size(current_database())`).Scan(&sizeBytes); err != nil {
+		report.fail("database_size_mb", err)
+	} else {
+		report.info("database_size_mb", float64(sizeBytes)/1024/1024)
+		if growth, ok := postgresDailyGrowth(store, config.SizeSnapshotKey, sizeBytes); ok {
+			report.record("database_growth_mb_per_day", growth, thresholds.GrowthMBPerDay)
+		}
+	}
+
+	// Edad de transacciones (riesgo de wraparound)
+	var xidAge float64
+	if err := conn.QueryRow(ctx, `SELECT max(age(datfrozenxid))::float8 FROM pg_database`).Scan(&xidAge); err != nil {
+		report.fail("xid_wraparound_percent", err)
+	} else {
+		report.info("xid_age", xidAge)
+		report.record("xid_wraparound_percent", xidAge/xidWraparoundLimit*100, thresholds.XIDWraparoundPct)
+	}
+
+	// Tablas con tuplas muertas sin vacuum reciente
+	tables, total, err := queryPostgresStaleVacuum(ctx, conn, config.StaleVacuumMinDeadTuples, config.StaleVacuumHours)
+	if err != nil {
+		report.fail("stale_vacuum_tables", err)
+	} else {
+		report.record("stale_vacuum_tables", float64(total), thresholds.StaleVacuumTables)
+		check.Metadata["stale_vacuum_tables"] = tables
+	}
+
+	check.ResponseTime = time.Since(start).Milliseconds()
+	check.Metadata["database"] = config.Connection.Database
+	report.applyTo(&check, fmt.Sprintf("PostgreSQL saludable: %.0f/%.0f conexiones (%dms)", connections, maxConnections, check.ResponseTime))
+
+	return check
+}
+
+// queryPostgresSessions lista sesiones de pg_stat_activity (pid, usuario, base, estado, segundos, consulta, total)
+func queryPostgresSessions(ctx context.Context, conn *pgx.Conn, query string, seconds float64) ([]map[string]interface{}, int64, error) {
+	rows, err := conn.Query(ctx, query, seconds, maxReportedSessions)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	sessions := []map[string]interface{}{}
+	var total int64
+	for rows.Next() {
+		var pid int32
+		var user, database, state, text string
+		var duration float64
+		if err := rows.Scan(&pid, &user, &database, &state, &duration, &text, &total); err != nil {
+			return nil, 0, err
+		}
+		sessions = append(sessions, map[string]interface{}{
+			"pid":              pid,
+			"user":             user,
+			"database":         database,
+			"state":            state,
+			"duration_seconds": roundMetric(duration),
+			"query":            text,
+		})
+	}
+
+	return sessions, total, rows.Err()
+}
+
+// queryPostgresBlockedSessions lista las sesiones que esperan un lock junto con las que las bloquean
+func queryPostgresBlockedSessions(ctx context.Context, conn *pgx.Conn) ([]map[string]interface{}, int64, error) {
+	rows, err := conn.Query(ctx, `
+		SELECT pid, COALESCE(usename, ''), pg_blocking_pids(pid),
+			EXTRACT(EPOCH FROM now() - COALESCE(query_start, state_change))::float8, left(query, 200), count(*) OVER ()
+		FROM pg_stat_activity
+		WHERE cardinality(pg_blocking_pids(pid)) > 0
+		ORDER BY query_start
+		LIMIT $1`, maxReportedSessions)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	sessions := []map[string]interface{}{}
+	var total int64
+	for rows.Next() {
+		var pid int32
+		var user, text string
+		var blockedBy []int32
+		var waiting float64
+		if err := rows.Scan(&pid, &user, &blockedBy, &waiting, &text, &total); err != nil {
+			return nil, 0, err
+		}
+		sessions = append(sessions, map[string]interface{}{
+			"pid":             pid,
+			"user":            user,
+			"blocked_by":      blockedBy,
+			"waiting_seconds": roundMetric(waiting),
+			"query":           text,
+		})
+	}
+
+	return sessions, total, rows.Err()
+}
+
+// recordPostgresReplication registra el rol del servidor y el lag de replicación si hay réplicas
+func recordPostgresReplication(ctx context.Context, conn *pgx.Conn, check *models.Check, report *healthReport, threshold MetricThreshold) error {
+	var inRecovery bool
+	if err := conn.QueryRow(ctx, `SELECT pg_is_in_recovery()`).Scan(&inRecovery); err != nil {
+		return err
+	}
+
+	if inRecovery {
+		check.Metadata["replication_role"] = "replica"
+		// Si la réplica ya aplicó todo lo recibido el lag es 0: con el primario sin escrituras
+		// now() - pg_last_xact_replay_timestamp() crecería indefinidamente sin haber atraso real
+		var lag float64
+		var caughtUp bool
+		err := conn.QueryRow(ctx, `
+			SELECT COALESCE(pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn(), false),
+				CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+					ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+				END::float8`).Scan(&caughtUp, &lag)
+		if err != nil {
+			return err
+		}
+		check.Metadata["replay_caught_up"] = caughtUp
+		report.record("replication_lag_seconds", lag, threshold)
+		return nil
+	}
+
+	check.Metadata["replication_role"] = "primary"
+	var replicas int64
+	var lag float64
+	err := conn.QueryRow(ctx, `SELECT count(*), COALESCE(max(EXTRACT(EPOCH FROM replay_lag)), 0)::float8 FROM pg_stat_replication`).
+		Scan(&replicas, &lag)
+	if err != nil {
+		return err
+	}
+	check.Metadata["replicas"] = replicas
+	if replicas > 0 {
+		report.record("replication_lag_seconds", lag, threshold)
+	}
+	return nil
+}
+
+// queryPostgresStaleVacuum lista las tablas con más tuplas muertas sin vacuum en las últimas horas indicadas
+func queryPostgresStaleVacuum(ctx context.Context, conn *pgx.Conn, minDeadTuples int, hours int) ([]map[string]interface{}, int64, error) {
+	rows, err := conn.Query(ctx, `
+		SELECT schemaname, relname, n_dead_tup, n_live_tup, GREATEST(last_autovacuum, last_vacuum), count(*) OVER ()
+		FROM pg_stat_user_tables
+		WHERE n_dead_tup >= $1
+			AND COALESCE(GREATEST(last_autovacuum, last_vacuum), 'epoch'::timestamptz) < now() - make_interval(hours => $2)
+		ORDER BY n_dead_tup DESC
+		LIMIT $3`, minDeadTuples, hours, maxReportedSessions)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	tables := []map[string]interface{}{}
+	var total int64
+	for rows.Next() {
+		var schema, name string
+		var deadTuples, liveTuples int64
+		var lastVacuum *time.Time
+		if err := rows.Scan(&schema, &name, &deadTuples, &liveTuples, &lastVacuum, &total); err != nil {
+			return nil, 0, err
+		}
+		table := map[string]interface{}{
+			"table":       schema + "." + name,
+			"dead_tuples": deadTuples,
+			"live_tuples": liveTuples,
+			"last_vacuum": nil,
+		}
+		if lastVacuum != nil {
+			table["last_vacuum"] = lastVacuum.Format(time.RFC3339)
+		}
+		tables = append(tables, table)
+	}
+
+	return tables, total, rows.Err()
+}
+
+// postgresDailyGrowth calcula el crecimiento en MB/día respecto del snapshot más antiguo guardado
+// Se guarda un snapshot cada minGrowthSpan y se conservan los últimos maxSizeSnapshots; sin al menos
+// minGrowthSpan de diferencia no se calcula (extrapolar unos minutos a un día amplifica el ruido)
+func postgresDailyGrowth(store StateStore, key string, sizeBytes int64) (float64, bool) {
+	if store == nil || key == "" {
+		return 0, false
+	}
+
+	var history postgresSizeHistory
+	if _, err := store.Get(key, &history); err != nil {
+		return 0, false
+	}
+
+	now := time.Now()
+	snapshots := history.Snapshots
+	if len(snapshots) == 0 || now.Sub(snapshots[len(snapshots)-1].Timestamp) >= minGrowthSpan {
+		updated := append(append([]postgresSizeSnapshot{}, snapshots...), postgresSizeSnapshot{SizeBytes: sizeBytes, Timestamp: now})
+		if len(updated) > maxSizeSnapshots {
+			updated = updated[len(updated)-maxSizeSnapshots:]
+		}
+		store.Put(key, postgresSizeHistory{Snapshots: updated})
+	}
+	if len(snapshots) == 0 {
+		return 0, false
+	}
+
+	oldest := snapshots[0]
+	elapsed := now.Sub(oldest.Timestamp)
+	if elapsed < minGrowthSpan {
+		return 0, false
+	}
+
+	growthMB := float64(sizeBytes-oldest.SizeBytes) / 1024 / 1024
+	return growthMB / elapsed.Hours() * 24, true
+}
@@ -0,0 +1,133 @@
+package monitors
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/saltacompra/monitor/internal/models"
+)
+
+// PostgreSQLConnection datos de conexión a una base PostgreSQL
+type PostgreSQLConnection struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Database string
+}
+
+// postgresConnString construye el connection string en formato URL (escapa credenciales)
+func postgresConnString(conn PostgreSQLConnection) string {
+	u := &url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(conn.User, conn.Password),
+		Host:   net.JoinHostPort(conn.Host, strconv.Itoa(conn.Port)),
+		Path:   "/" + conn.Database,
+	}
+	return u.String()
+}
+
+// checkVPNPrecondition verifica conectividad con la red privada antes de conectar a la base
+// Si no hay VPN completa el check con el error y diagnóstico, y retorna false
+func checkVPNPrecondition(check *models.Check, vpnHost string, port int, timeoutMs int) bool {
+	if timeoutMs == 0 {
+		timeoutMs = 2000 // Default 2 segundos
+	}
+
+	timeout := time.Duration(timeoutMs) * time.Millisecond
+	deadline := time.Now().Add(timeout)
+	vpnErr := dialVPN(vpnHost, port, timeoutMs)
+	check.Metadata["vpn_check_host"] = vpnHost
+	check.Metadata["vpn_available"] = vpnErr == nil
+
+	if vpnErr != nil {
+		check.Status = "error"
+		check.Message = fmt.Sprintf("No hay conectividad con la red privada (VPN). No se puede acceder a %s:%d", vpnHost, port)
+		check.Metadata["error_type"] = "vpn_unavailable"
+		diagnosis := diagnosePostgreSQLFailure(vpnHost, port, diagnosisBudget(deadline, timeout), vpnErr)
+		diagnosis.applyTo(check.Metadata)
+		return false
+	}
+	return true
+}
+
+// connectPostgreSQL abre una conexión; si falla completa el check con el error y diagnóstico
+// El diagnóstico usa lo que resta del plazo de ctx (acotado a diagnosisTimeout)
+func connectPostgreSQL(ctx context.Context, check *models.Check, conn PostgreSQLConnection, diagnosisTimeout time.Duration) (*pgx.Conn, bool) {
+	pgConn, err := pgx.Connect(ctx, postgresConnString(conn))
+	if err != nil {
+		check.Status = "error"
+		check.Metadata["error_type"] = "connection_failed"
+		budget := diagnosisTimeout
+		if deadline, ok := ctx.Deadline(); ok {
+			budget = diagnosisBudget(deadline, diagnosisTimeout)
+		}
+		diagnosis := diagnosePostgreSQLFailure(conn.Host, conn.Port, budget, err)
+		diagnosis.applyTo(check.Metadata)
+		check.Message = fmt.Sprintf("Error al conectar a PostgreSQL (%s): %s", diagnosis.RootCause, err.Error())
+		return nil, false
+	}
+	return pgConn, true
+}
+
+// scanPgxRows lee todas las filas de un resultado pgx como mapas columna → valor
+func scanPgxRows(rows pgx.Rows) (QueryResult, error) {
+	fields := rows.FieldDescriptions()
+	columns := make([]string, len(fields))
+	for i, field := range fields {
+		columns[i] = field.Name
+	}
+
+	result := QueryResult{Columns: columns, Rows: []map[string]interface{}{}}
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return result, err
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			row[column] = normalizeSQLValue(values[i])
+		}
+		result.Rows = append(result.Rows, row)
+	}
+
+	return result, rows.Err()
+}
+
+// pgNumericToFloat convierte un NUMERIC de PostgreSQL a float64 (false si es NULL, NaN o infinito)
+func pgNumericToFloat(value pgtype.Numeric) (float64, bool) {
+	number, err := value.Float64Value()
+	if err != nil || !number.Valid || math.IsNaN(number.Float64) || math.IsInf(number.Float64, 0) {
+		return 0, false
+	}
+	return number.Float64, true
+}
+
+// pgNumericLabel texto de los NUMERIC especiales (NaN, Infinity, -Infinity)
+func pgNumericLabel(value pgtype.Numeric) string {
+	switch {
+	case value.NaN:
+		return "NaN"
+	case value.InfinityModifier == pgtype.Infinity:
+		return "Infinity"
+	case value.InfinityModifier == pgtype.NegativeInfinity:
+		return "-Infinity"
+	}
+	number, _ := value.Float64Value()
+	return strconv.FormatFloat(number.Float64, 'g', -1, 64)
+}
+
+// pgIntervalSeconds convierte un INTERVAL de PostgreSQL a segundos
+// Como hace PostgreSQL al extraer epoch, un mes cuenta como 30 días
+func pgIntervalSeconds(value pgtype.Interval) float64 {
+	days := float64(value.Months)*30 + float64(value.Days)
+	return days*86400 + float64(value.Microseconds)/1e6
+}
@@ -0,0 +1,104 @@
+package monitors
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/saltacompra/monitor/internal/models"
+)
+
+// PostgreSQLQueryCheckConfig contiene la configuración para un check de consulta PostgreSQL
+type PostgreSQLQueryCheckConfig struct {
+	Connection     PostgreSQLConnection
+	CheckID        string
+	CheckName      string
+	Query          string        // Consulta de solo lectura con parámetros $1, $2...
+	Params         []interface{} // Valores de los parámetros
+	TimeoutSeconds int           // Timeout de la consulta
+	MaxRows        int           // Máximo de filas incluidas en metadata
+	VPNCheck       bool          // Verificar conectividad VPN antes de conectar
+	VPNCheckHost   string        // Host para verificar VPN (default: host de PostgreSQL)
+	VPNTimeoutMs   int           // Timeout en ms para verificar VPN
+	Thresholds     QueryThresholds
+}
+
+// CheckPostgreSQLQuery ejecuta una consulta configurada en una transacción READ ONLY y evalúa su resultado
+func CheckPostgreSQLQuery(config PostgreSQLQueryCheckConfig) models.Check {
+	check := models.Check{
+		ID:        config.CheckID,
+		Type:      "postgres-query",
+		Name:      config.CheckName,
+		LastCheck: time.Now(),
+		Metadata:  make(map[string]interface{}),
+	}
+
+	if err := validateReadOnlyQuery(config.Query); err != nil {
+		check.Status = "error"
+		check.Message = "Consulta rechazada: " + err.Error()
+		check.Metadata["error_type"] = "invalid_query"
+		return check
+	}
+
+	vpnTimeout := config.VPNTimeoutMs
+	if vpnTimeout == 0 {
+		vpnTimeout = 2000 // Default 2 segundos
+	}
+
+	if config.VPNCheck {
+		vpnHost := config.VPNCheckHost
+		if vpnHost == "" {
+			vpnHost = config.Connection.Host
+		}
+		if !checkVPNPrecondition(&check, vpnHost, config.Connection.Port, vpnTimeout) {
+			return check
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(config.TimeoutSeconds))
+	defer cancel()
+
+	start := time.Now()
+	conn, ok := connectPostgreSQL(ctx, &check, config.Connection, time.Duration(vpnTimeout)*time.Millisecond)
+	if !ok {
+		check.ResponseTime = time.Since(start).Milliseconds()
+		return check
+	}
+	defer conn.Close(context.Background())
+
+	// Transacción de solo lectura: el servidor rechaza cualquier escritura
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		check.ResponseTime = time.Since(start).Milliseconds()
+		check.Status = "error"
+		check.Message = "Error al iniciar transacción: " + err.Error()
+		return check
+	}
+	defer tx.Rollback(context.Background())
+
+	rows, err := tx.Query(ctx, config.Query, config.Params...)
+	if err != nil {
+		check.ResponseTime = time.Since(start).Milliseconds()
+		check.Status = "error"
+		check.Message = "Error al ejecutar consulta: " + err.Error()
+		check.Metadata["error_type"] = "query_failed"
+		check.Metadata["failure_root_cause"] = classifyPostgreSQLError(err)
+		return check
+	}
+	result, err := scanPgxRows(rows)
+	rows.Close()
+	check.ResponseTime = time.Since(start).Milliseconds()
+
+	if err != nil {
+		check.Status = "error"
+		check.Message = "Error al leer resultado: " + err.Error()
+		check.Metadata["error_type"] = "query_failed"
+		check.Metadata["failure_root_cause"] = classifyPostgreSQLError(err)
+		return check
+	}
+
+	check.Metadata["database"] = config.Connection.Database
+	applyQueryResult(&check, result, config.Thresholds, config.MaxRows)
+
+	return check
+}
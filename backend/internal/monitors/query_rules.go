@@ -0,0 +1,218 @@
+package monitors
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/saltacompra/monitor/internal/models"
+)
+
+// QueryThresholds umbrales para evaluar el resultado de una consulta configurada
+// Los punteros nil indican umbrales no configurados
+type QueryThresholds struct {
+	Column         string   // Columna evaluada (default: primera columna)
+	WarningMin     *float64 // Valor menor → warning
+	WarningMax     *float64 // Valor mayor → warning
+	ErrorMin       *float64 // Valor menor → error
+	ErrorMax       *float64 // Valor mayor → error
+	Expected       *string  // Valor exacto esperado (distinto → error)
+	MinRows        *int     // Menos filas → error
+	WarningMaxRows *int     // Más filas → warning
+	ErrorMaxRows   *int     // Más filas → error
+	WarningRule    string   // Expresión sobre la primera fila y row_count (verdadera → warning)
+	ErrorRule      string   // Expresión sobre la primera fila y row_count (verdadera → error)
+}
+
+// QueryResult resultado normalizado de una consulta (valores JSON-friendly)
+type QueryResult struct {
+	Columns []string
+	Rows    []map[string]interface{}
+}
+
+// forbiddenStatementPattern detecta sentencias que modifican datos o ejecutan código
+var forbiddenStatementPattern = regexp.MustCompile(`(?i)\b(INSERT|UPDATE|DELETE|MERGE|DROP|ALTER|CREATE|TRUNCATE|EXEC|EXECUTE|GRANT|REVOKE|DENY|BACKUP|RESTORE|SHUTDOWN|KILL|CALL|COPY|VACUUM|REINDEX|CLUSTER|DBCC|INTO)\b`)
+
+// sqlCommentPattern elimina comentarios SQL antes de validar
+var sqlCommentPattern = regexp.MustCompile(`(?s)--[^\n]*|/\*.*?\*/`)
+
+// validateReadOnlyQuery verifica que la consulta sea una única sentencia SELECT/WITH sin escrituras
+func validateReadOnlyQuery(query string) error {
+	cleaned := strings.TrimSpace(sqlCommentPattern.ReplaceAllString(query, " "))
+	cleaned = strings.TrimSuffix(cleaned, ";")
+	upper := strings.ToUpper(cleaned)
+
+	if !strings.HasPrefix(upper, "SELECT") && !strings.HasPrefix(upper, "WITH") {
+		return fmt.Errorf("solo se permiten consultas SELECT o WITH")
+	}
+	if strings.Contains(cleaned, ";") {
+		return fmt.Errorf("no se permiten múltiples sentencias")
+	}
+	if match := forbiddenStatementPattern.FindString(cleaned); match != "" {
+		return fmt.Errorf("palabra clave no permitida en consulta de solo lectura: %s", strings.ToUpper(match))
+	}
+	return nil
+}
+
+// normalizeSQLValue convierte valores de drivers SQL a tipos serializables
+// NUMERIC de PostgreSQL se convierte a número e INTERVAL a segundos, para que las reglas los comparen
+func normalizeSQLValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case pgtype.Numeric:
+		if !v.Valid {
+			return nil
+		}
+		if number, ok := pgNumericToFloat(v); ok {
+			return number
+		}
+		// NaN e infinito no son serializables en JSON
+		return pgNumericLabel(v)
+	case pgtype.Interval:
+		if !v.Valid {
+			return nil
+		}
+		return pgIntervalSeconds(v)
+	case fmt.Stringer:
+		return v.String()
+	}
+	return value
+}
+
+// evaluateQueryResult aplica los umbrales al resultado y retorna el estado y los motivos
+func evaluateQueryResult(result QueryResult, thresholds QueryThresholds) (string, []string, error) {
+	status := "ok"
+	var reasons []string
+	raise := func(newStatus string, reason string) {
+		reasons = append(reasons, reason)
+		if newStatus == "error" || status == "ok" {
+			status = newStatus
+		}
+	}
+
+	rowCount := len(result.Rows)
+
+	// Umbrales sobre cantidad de filas
+	if thresholds.MinRows != nil && rowCount < *thresholds.MinRows {
+		raise("error", fmt.Sprintf("%d filas, mínimo esperado %d", rowCount, *thresholds.MinRows))
+	}
+	if thresholds.ErrorMaxRows != nil && rowCount > *thresholds.ErrorMaxRows {
+		raise("error", fmt.Sprintf("%d filas, máximo %d", rowCount, *thresholds.ErrorMaxRows))
+	} else if thresholds.WarningMaxRows != nil && rowCount > *thresholds.WarningMaxRows {
+		raise("warning", fmt.Sprintf("%d filas, máximo recomendado %d", rowCount, *thresholds.WarningMaxRows))
+	}
+
+	// Umbrales sobre el valor de la columna evaluada (primera fila)
+	column := thresholds.Column
+	if column == "" && len(result.Columns) > 0 {
+		column = result.Columns[0]
+	}
+	hasValueThresholds := thresholds.Expected != nil || thresholds.WarningMin != nil || thresholds.WarningMax != nil ||
+		thresholds.ErrorMin != nil || thresholds.ErrorMax != nil
+
+	if hasValueThresholds {
+		if rowCount == 0 {
+			raise("error", "la consulta no retornó filas para evaluar")
+		} else if value, exists := result.Rows[0][column]; !exists {
+			return "error", nil, fmt.Errorf("columna '%s' no encontrada en el resultado", column)
+		} else {
+			if thresholds.Expected != nil && fmt.Sprintf("%v", value) != *thresholds.Expected {
+				raise("error", fmt.Sprintf("%s=%v, se esperaba %s", column, value, *thresholds.Expected))
+			}
+
+			if thresholds.WarningMin != nil || thresholds.WarningMax != nil || thresholds.ErrorMin != nil || thresholds.ErrorMax != nil {
+				number, ok := toFloat(value)
+				switch {
+				case !ok:
+					raise("error", fmt.Sprintf("%s=%v no es numérico", column, value))
+				case thresholds.ErrorMax != nil && number > *thresholds.ErrorMax:
+					raise("error", fmt.Sprintf("%s=%v supera el máximo %v", column, value, *thresholds.ErrorMax))
+				case thresholds.ErrorMin != nil && number < *thresholds.ErrorMin:
+					raise("error", fmt.Sprintf("%s=%v por debajo del mínimo %v", column, value, *thresholds.ErrorMin))
+				case thresholds.WarningMax != nil && number > *thresholds.WarningMax:
+					raise("warning", fmt.Sprintf("%s=%v supera el umbral %v", column, value, *thresholds.WarningMax))
+				case thresholds.WarningMin != nil && number < *thresholds.WarningMin:
+					raise("warning", fmt.Sprintf("%s=%v por debajo del umbral %v", column, value, *thresholds.WarningMin))
+				}
+			}
+		}
+	}
+
+	// Reglas expresadas sobre la primera fila
+	if thresholds.ErrorRule != "" || thresholds.WarningRule != "" {
+		variables := map[string]interface{}{"row_count": float64(rowCount)}
+		if rowCount > 0 {
+			for name, value := range result.Rows[0] {
+				variables[name] = value
+			}
+		}
+
+		for _, rule := range []struct{ expression, status string }{
+			{thresholds.ErrorRule, "error"},
+			{thresholds.WarningRule, "warning"},
+		} {
+			if rule.expression == "" {
+				continue
+			}
+			matched, err := evaluateRule(rule.expression, variables)
+			if err != nil {
+				return "error", nil, fmt.Errorf("regla inválida '%s': %w", rule.expression, err)
+			}
+			if matched {
+				raise(rule.status, "se cumple la regla: "+rule.expression)
+				break
+			}
+		}
+	}
+
+	return status, reasons, nil
+}
+
+// applyQueryResult completa metadata, estado y mensaje del check con el resultado de la consulta
+func applyQueryResult(check *models.Check, result QueryResult, thresholds QueryThresholds, maxRows int) {
+	if maxRows == 0 {
+		maxRows = 20
+	}
+
+	check.Metadata["row_count"] = len(result.Rows)
+	check.Metadata["columns"] = result.Columns
+	if len(result.Rows) > 0 {
+		check.Metadata["values"] = result.Rows[0]
+	}
+	if len(result.Rows) > 1 {
+		rows := result.Rows
+		if len(rows) > maxRows {
+			rows = rows[:maxRows]
+		}
+		check.Metadata["rows"] = rows
+	}
+
+	status, reasons, err := evaluateQueryResult(result, thresholds)
+	if err != nil {
+		check.Status = "error"
+		check.Message = "Error al evaluar resultado: " + err.Error()
+		return
+	}
+
+	check.Status = status
+	if len(reasons) > 0 {
+		check.Message = strings.Join(reasons, "; ")
+		return
+	}
+
+	summary := fmt.Sprintf("%d filas", len(result.Rows))
+	if len(result.Rows) > 0 && len(result.Columns) > 0 {
+		column := thresholds.Column
+		if column == "" {
+			column = result.Columns[0]
+		}
+		summary = fmt.Sprintf("%s=%v (%d filas)", column, result.Rows[0][column], len(result.Rows))
+	}
+	check.Message = fmt.Sprintf("Consulta dentro de umbrales: %s, %dms", summary, check.ResponseTime)
+}
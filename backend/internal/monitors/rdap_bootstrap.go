@@ -0,0 +1,184 @@
+package monitors
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RDAPBootstrapConfig origen del registro bootstrap de IANA que asocia cada TLD con su servidor RDAP
+type RDAPBootstrapConfig struct {
+	File         string            // Copia local del dns.json (opcional, reemplaza a la incluida y se actualiza al refrescar)
+	URL          string            // URL del registro de IANA para refrescar (vacío = no se refresca)
+	RefreshHours int               // Horas entre refrescos desde URL (0 = no se refresca)
+	Overrides    map[string]string // Etiqueta (TLD o sufijo) → URL base RDAP, prevalece sobre el registro
+}
+
+// bundledRDAPBootstrap copia del registro bootstrap incluida en el binario
+// Reconstruye el dns.json de IANA para los TLDs más usados; se reemplaza al refrescar desde URL
+//
+//go:embed rdap_bootstrap.json
+var bundledRDAPBootstrap []byte
+
+// rdapBootstrapFile formato del dns.json de IANA (RFC 9224)
+// Cada servicio es un par [[etiquetas], [URLs base]]
+type rdapBootstrapFile struct {
+	Services [][][]string `json:"services"`
+}
+
+// rdapBootstrapRegistry registro bootstrap en memoria, compartido entre checks
+type rdapBootstrapRegistry struct {
+	sync.Mutex
+	services    map[string][]string // Etiqueta (TLD o sufijo) → URLs base
+	source      string              // "bundled", "file" o "remote"
+	lastRefresh time.Time           // Último intento de refresco desde URL
+	lastError   string              // Error del último refresco (vacío si fue exitoso)
+	refreshing  bool                // Hay un refresco en curso
+}
+
+var rdapBootstrap = &rdapBootstrapRegistry{}
+
+// parseRDAPBootstrap interpreta un dns.json y retorna el mapa de etiquetas a URLs base
+func parseRDAPBootstrap(data []byte) (map[string][]string, error) {
+	var file rdapBootstrapFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	services := make(map[string][]string)
+	for _, service := range file.Services {
+		if len(service) != 2 || len(service[1]) == 0 {
+			continue
+		}
+		for _, label := range service[0] {
+			services[strings.ToLower(strings.TrimSpace(label))] = service[1]
+		}
+	}
+	if len(services) == 0 {
+		return nil, fmt.Errorf("el registro bootstrap no contiene servicios")
+	}
+	return services, nil
+}
+
+// load carga el registro desde la copia local o, si no existe, desde la copia incluida
+func (r *rdapBootstrapRegistry) load(config RDAPBootstrapConfig) {
+	if config.File != "" {
+		if data, err := os.ReadFile(config.File); err == nil {
+			if services, err := parseRDAPBootstrap(data); err == nil {
+				r.services, r.source = services, "file"
+				return
+			}
+		}
+	}
+
+	services, err := parseRDAPBootstrap(bundledRDAPBootstrap)
+	if err != nil {
+		// La copia incluida se valida al compilar el monitor; un error aquí es un bug
+		panic(fmt.Sprintf("rdap_bootstrap.json inválido: %v", err))
+	}
+	r.services, r.source = services, "bundled"
+}
+
+// refresh descarga el registro desde IANA y, si hay copia local configurada, la actualiza
+// Corre en segundo plano y sin el lock tomado durante la descarga, para no demorar a los checks
+// que resuelven servidores mientras tanto; ante un error se conserva el registro anterior
+func (r *rdapBootstrapRegistry) refresh(config RDAPBootstrapConfig) {
+	services, data, err := fetchRDAPBootstrap(config.URL)
+	if err == nil && config.File != "" {
+		if writeErr := writeRDAPBootstrapFile(config.File, data); writeErr != nil {
+			err = fmt.Errorf("registro actualizado, pero no se pudo guardar la copia local: %w", writeErr)
+		}
+	}
+
+	r.Lock()
+	defer r.Unlock()
+	r.refreshing = false
+	if services != nil {
+		r.services, r.source = services, "remote"
+	}
+	r.lastError = ""
+	if err != nil {
+		r.lastError = err.Error()
+	}
+}
+
+// fetchRDAPBootstrap descarga y valida el registro bootstrap
+func fetchRDAPBootstrap(url string) (map[string][]string, []byte, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	services, err := parseRDAPBootstrap(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return services, data, nil
+}
+
+// writeRDAPBootstrapFile reemplaza la copia local a través de un archivo temporal,
+// para que una escritura interrumpida no deje un dns.json truncado
+func writeRDAPBootstrapFile(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// resolve retorna las URLs base RDAP del dominio según el sufijo más largo registrado
+// (ej: para compras.salta.gob.ar prueba salta.gob.ar, gob.ar y ar); en cada sufijo los overrides
+// configurados prevalecen sobre el registro. Si corresponde refrescar, inicia el refresco en segundo
+// plano y resuelve con el registro actual
+func (r *rdapBootstrapRegistry) resolve(domain string, config RDAPBootstrapConfig) (urls []string, source string, refreshError string) {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.services == nil {
+		r.load(config)
+	}
+	if config.URL != "" && config.RefreshHours > 0 && !r.refreshing &&
+		time.Since(r.lastRefresh) >= time.Duration(config.RefreshHours)*time.Hour {
+		r.refreshing, r.lastRefresh = true, time.Now()
+		go r.refresh(config)
+	}
+
+	labels := strings.Split(strings.ToLower(strings.TrimSuffix(domain, ".")), ".")
+	for i := 1; i < len(labels); i++ {
+		suffix := strings.Join(labels[i:], ".")
+		if url, exists := config.Overrides[suffix]; exists {
+			return []string{url}, "override", r.lastError
+		}
+		if urls, exists := r.services[suffix]; exists {
+			return urls, r.source, r.lastError
+		}
+	}
+	return nil, r.source, r.lastError
+}
+
+// rdapDomainURL arma la URL de consulta de un dominio a partir de la URL base de un servidor RDAP
+func rdapDomainURL(baseURL string, domain string) string {
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return baseURL + "domain/" + domain
+}
@@ -1,10 +1,13 @@
 package monitors
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/saltacompra/monitor/internal/models"
@@ -13,17 +16,46 @@ import (
 // RDAPCheckConfig contiene la configuración para verificaciones RDAP
 type RDAPCheckConfig struct {
 	Domain      string
-	RDAPBaseURL string // URL base del servicio RDAP (ej: https://rdap.nic.ar/domain/)
+	RDAPBaseURL string              // Override de la URL de consulta (ej: https://rdap.nic.ar/domain/); vacío = bootstrap de IANA
+	Bootstrap   RDAPBootstrapConfig // Registro bootstrap y overrides por TLD usados para resolver el servidor RDAP
+	BaselineKey string              // Clave del registro aprobado en el StateStore (vacío = no se comparan cambios)
+	WHOIS       WHOISConfig         // Fallback cuando RDAP no responde
 	CheckID     string
 	CheckName   string
 	WarningDays int // Días antes de expiración para warning
 	ErrorDays   int // Días antes de expiración para error
 }
 
+// domainLookupTimeout tiempo máximo de la consulta completa de un dominio
+// (RDAP, referencia de IANA y WHOIS comparten el mismo deadline)
+const domainLookupTimeout = 25 * time.Second
+
+// rdapRequestTimeout tiempo máximo de la petición RDAP, para dejar margen al fallback WHOIS
+const rdapRequestTimeout = 10 * time.Second
+
+// errDomainNotRegistered la fuente de consulta informa que el dominio no existe
+// Es un problema del dominio, no de la consulta: no se intenta otra fuente
+var errDomainNotRegistered = errors.New("el dominio no está registrado")
+
+// rdapUnavailableError falla de RDAP que justifica consultar WHOIS: error de red,
+// HTTP 5xx o 429, o un TLD sin servidor RDAP
+type rdapUnavailableError struct {
+	err error
+}
+
+// Error retorna el mensaje del error original
+func (e *rdapUnavailableError) Error() string { return e.err.Error() }
+
+// Unwrap expone el error original a errors.Is y errors.As
+func (e *rdapUnavailableError) Unwrap() error { return e.err }
+
 // RDAPResponse estructura simplificada de la respuesta RDAP
 type RDAPResponse struct {
-	Events []RDAPEvent `json:"events"`
-	Status []string    `json:"status"`
+	Events      []RDAPEvent      `json:"events"`
+	Status      []string         `json:"status"`
+	Nameservers []RDAPNameserver `json:"nameservers"`
+	Entities    []RDAPEntity     `json:"entities"`
+	SecureDNS   *RDAPSecureDNS   `json:"secureDNS"`
 }
 
 // RDAPEvent representa un evento en la respuesta RDAP
@@ -32,8 +64,12 @@ type RDAPEvent struct {
 	EventDate   string `json:"eventDate"`
 }
 
-// CheckRDAPDomain verifica la fecha de expiración de un dominio vía RDAP
-func CheckRDAPDomain(config RDAPCheckConfig) models.Check {
+// CheckRDAPDomain verifica la fecha de expiración de un dominio vía RDAP, sus estados
+// y los cambios de nameservers, registrar o DNSSEC respecto del registro aprobado
+// Si RDAP no está disponible (red, 5xx, 429) usa WHOIS (puerto 43); si ninguna fuente responde el check
+// queda en warning con error_type lookup_failed, para no confundir una falla de consulta con un problema
+// del dominio. Un dominio inexistente (RDAP 404 o "no match" de WHOIS) es error
+func CheckRDAPDomain(config RDAPCheckConfig, store StateStore) models.Check {
 	check := models.Check{
 		ID:        config.CheckID,
 		Type:      "rdap",
@@ -42,71 +78,170 @@ func CheckRDAPDomain(config RDAPCheckConfig) models.Check {
 		Metadata:  make(map[string]interface{}),
 	}
 
-	// URL del servicio RDAP (configurable vía env var)
-	baseURL := config.RDAPBaseURL
-	if baseURL == "" {
-		baseURL = "https://rdap.nic.ar/domain/" // Fallback por defecto
-	}
-	url := fmt.Sprintf("%s%s", baseURL, config.Domain)
+	check.Metadata["domain"] = config.Domain
+
+	ctx, cancel := context.WithTimeout(context.Background(), domainLookupTimeout)
+	defer cancel()
 
-	// Realizar petición HTTP
 	start := time.Now()
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(url)
-	elapsed := time.Since(start).Milliseconds()
-	check.ResponseTime = elapsed
+	rdapData, err := lookupRDAP(ctx, &check, config)
+	var expirationDate time.Time
+	if err == nil {
+		expirationDate, err = rdapExpiration(rdapData)
+	}
+	if err == nil {
+		check.ResponseTime = time.Since(start).Milliseconds()
+		check.Metadata["lookup_source"] = "rdap"
+		check.Metadata["domain_status"] = rdapData.Status
 
-	if err != nil {
-		check.Status = "error"
-		check.Message = "No se pudo consultar RDAP: " + err.Error()
+		// Expiración y cambios en los datos de registro (el peor estado prevalece)
+		check.Status, check.Message = evaluateDomainExpiration(&check, expirationDate, config)
+		applyRDAPRegistration(&check, newRDAPRegistration(rdapData), config.BaselineKey, store)
+		return check
+	}
+	check.Metadata["rdap_error"] = err.Error()
+	if errors.Is(err, errDomainNotRegistered) {
+		setDomainNotRegistered(&check, config.Domain, "rdap", start)
 		return check
 	}
+	sourceErrors := []string{"RDAP: " + err.Error()}
+
+	// Fallback WHOIS: sin comparación contra el registro aprobado (los datos no son equivalentes a RDAP)
+	var unavailable *rdapUnavailableError
+	if config.WHOIS.Enabled && errors.As(err, &unavailable) {
+		record, whoisErr := lookupWHOIS(ctx, config.Domain, config.WHOIS)
+		check.ResponseTime = time.Since(start).Milliseconds()
+		if record.server != "" {
+			check.Metadata["whois_server"] = record.server
+		}
+		if whoisErr == nil {
+			check.Metadata["lookup_source"] = "whois"
+			check.Metadata["domain_status"] = record.statuses
+			check.Status, check.Message = evaluateDomainExpiration(&check, record.expiration, config)
+			check.Message += " (vía WHOIS, RDAP no disponible)"
+			applyRDAPRegistration(&check, RDAPRegistration{
+				Registrar:   record.registrar,
+				Nameservers: record.nameservers,
+				Statuses:    record.statuses,
+				CapturedAt:  time.Now(),
+			}, "", nil)
+			// El registro aprobado no se compara ni se actualiza: se informa para no dar por revisados los cambios
+			if store != nil && config.BaselineKey != "" {
+				check.Metadata["registration_compare_skipped"] = true
+				check.Message += ". Cambios de registro no verificados (WHOIS no se compara con el registro aprobado)"
+			}
+			return check
+		}
+		check.Metadata["whois_error"] = whoisErr.Error()
+		if errors.Is(whoisErr, errDomainNotRegistered) {
+			setDomainNotRegistered(&check, config.Domain, "whois", start)
+			return check
+		}
+		sourceErrors = append(sourceErrors, "WHOIS: "+whoisErr.Error())
+	}
+
+	check.ResponseTime = time.Since(start).Milliseconds()
+	check.Status = "warning"
+	check.Message = "No se pudo consultar el dominio (falla de la fuente de consulta, no del dominio): " + strings.Join(sourceErrors, "; ")
+	check.Metadata["lookup_source"] = "none"
+	check.Metadata["error_type"] = "lookup_failed"
+	return check
+}
+
+// setDomainNotRegistered marca el check en error porque la fuente de consulta informa que el dominio no existe
+func setDomainNotRegistered(check *models.Check, domain string, source string, start time.Time) {
+	check.ResponseTime = time.Since(start).Milliseconds()
+	check.Status = "error"
+	check.Message = fmt.Sprintf("El dominio %s no está registrado (según %s)", domain, strings.ToUpper(source))
+	check.Metadata["lookup_source"] = source
+	check.Metadata["error_type"] = "domain_not_registered"
+}
+
+// rdapLookupURL arma la URL de consulta del dominio: override global configurado o, si no hay,
+// la del servidor de su TLD según los overrides por TLD y el bootstrap de IANA
+func rdapLookupURL(check *models.Check, config RDAPCheckConfig) (string, error) {
+	if config.RDAPBaseURL != "" {
+		check.Metadata["rdap_source"] = "override"
+		return fmt.Sprintf("%s%s", config.RDAPBaseURL, config.Domain), nil
+	}
+
+	servers, source, refreshError := rdapBootstrap.resolve(config.Domain, config.Bootstrap)
+	check.Metadata["rdap_source"] = "bootstrap"
+	if source == "override" {
+		check.Metadata["rdap_source"] = "override"
+	} else {
+		check.Metadata["bootstrap_source"] = source
+	}
+	if refreshError != "" {
+		check.Metadata["bootstrap_refresh_error"] = refreshError
+	}
+	if len(servers) == 0 {
+		return "", &rdapUnavailableError{fmt.Errorf("el registro bootstrap de IANA no tiene servidor RDAP para %s", config.Domain)}
+	}
+	return rdapDomainURL(servers[0], config.Domain), nil
+}
+
+// lookupRDAP consulta el dominio en el servidor RDAP que indique rdapLookupURL
+// Un 404 retorna errDomainNotRegistered; las fallas de red, 5xx y 429 retornan rdapUnavailableError
+func lookupRDAP(ctx context.Context, check *models.Check, config RDAPCheckConfig) (RDAPResponse, error) {
+	var rdapData RDAPResponse
+
+	url, err := rdapLookupURL(check, config)
+	if err != nil {
+		return rdapData, err
+	}
+	check.Metadata["rdap_url"] = url
+
+	// Realizar petición HTTP
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return rdapData, fmt.Errorf("URL RDAP inválida: %w", err)
+	}
+	req.Header.Set("Accept", "application/rdap+json, application/json")
+	client := &http.Client{Timeout: rdapRequestTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return rdapData, &rdapUnavailableError{fmt.Errorf("no se pudo consultar RDAP: %w", err)}
+	}
 	defer resp.Body.Close()
 
 	// Verificar código HTTP
-	if resp.StatusCode != 200 {
-		check.Status = "error"
-		check.Message = fmt.Sprintf("Error en respuesta RDAP (HTTP %d)", resp.StatusCode)
-		return check
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return rdapData, fmt.Errorf("%w (RDAP HTTP 404)", errDomainNotRegistered)
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return rdapData, &rdapUnavailableError{fmt.Errorf("error en respuesta RDAP (HTTP %d)", resp.StatusCode)}
+	case resp.StatusCode != http.StatusOK:
+		return rdapData, fmt.Errorf("error en respuesta RDAP (HTTP %d)", resp.StatusCode)
 	}
 
 	// Leer y parsear respuesta JSON
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		check.Status = "error"
-		check.Message = "Error al leer respuesta RDAP: " + err.Error()
-		return check
+		return rdapData, fmt.Errorf("error al leer respuesta RDAP: %w", err)
 	}
-
-	var rdapData RDAPResponse
 	if err := json.Unmarshal(body, &rdapData); err != nil {
-		check.Status = "error"
-		check.Message = "Error al parsear JSON de RDAP: " + err.Error()
-		return check
+		return rdapData, fmt.Errorf("error al parsear JSON de RDAP: %w", err)
 	}
+	return rdapData, nil
+}
 
-	// Buscar fecha de expiración en los eventos
-	var expirationDate time.Time
-	found := false
+// rdapExpiration busca la fecha de expiración en los eventos de la respuesta RDAP
+func rdapExpiration(rdapData RDAPResponse) (time.Time, error) {
 	for _, event := range rdapData.Events {
 		if event.EventAction == "expiration" {
-			expirationDate, err = time.Parse(time.RFC3339, event.EventDate)
+			expirationDate, err := time.Parse(time.RFC3339, event.EventDate)
 			if err != nil {
-				check.Status = "error"
-				check.Message = "Error al parsear fecha de expiración: " + err.Error()
-				return check
+				return time.Time{}, fmt.Errorf("error al parsear fecha de expiración: %w", err)
 			}
-			found = true
-			break
+			return expirationDate, nil
 		}
 	}
+	return time.Time{}, fmt.Errorf("no se encontró fecha de expiración en respuesta RDAP")
+}
 
-	if !found {
-		check.Status = "error"
-		check.Message = "No se encontró fecha de expiración en respuesta RDAP"
-		return check
-	}
-
+// evaluateDomainExpiration evalúa los días hasta la expiración según los umbrales
+func evaluateDomainExpiration(check *models.Check, expirationDate time.Time, config RDAPCheckConfig) (string, string) {
 	// Calcular días hasta expiración
 	now := time.Now()
 	daysRemaining := int(expirationDate.Sub(now).Hours() / 24)
@@ -114,25 +249,88 @@ func CheckRDAPDomain(config RDAPCheckConfig) models.Check {
 	// Guardar metadata
 	check.Metadata["expiration_date"] = expirationDate.Format("2006-01-02")
 	check.Metadata["days_remaining"] = daysRemaining
-	check.Metadata["domain_status"] = rdapData.Status
 
 	// Determinar estado según umbrales
 	if daysRemaining < 0 {
-		check.Status = "error"
-		check.Message = fmt.Sprintf("¡DOMINIO VENCIDO! Expiró hace %d días", -daysRemaining)
+		return "error", fmt.Sprintf("¡DOMINIO VENCIDO! Expiró hace %d días", -daysRemaining)
 	} else if daysRemaining <= config.ErrorDays {
-		check.Status = "error"
-		check.Message = fmt.Sprintf("¡Dominio expira pronto! Quedan solo %d días (expira: %s)",
+		return "error", fmt.Sprintf("¡Dominio expira pronto! Quedan solo %d días (expira: %s)",
 			daysRemaining, expirationDate.Format("02/01/2006"))
 	} else if daysRemaining <= config.WarningDays {
-		check.Status = "warning"
-		check.Message = fmt.Sprintf("Dominio debe renovarse pronto. Quedan %d días (expira: %s)",
-			daysRemaining, expirationDate.Format("02/01/2006"))
-	} else {
-		check.Status = "ok"
-		check.Message = fmt.Sprintf("Dominio válido. Expira en %d días (%s)",
+		return "warning", fmt.Sprintf("Dominio debe renovarse pronto. Quedan %d días (expira: %s)",
 			daysRemaining, expirationDate.Format("02/01/2006"))
 	}
+	return "ok", fmt.Sprintf("Dominio válido. Expira en %d días (%s)",
+		daysRemaining, expirationDate.Format("02/01/2006"))
+}
 
-	return check
+// applyRDAPRegistration agrega al check los datos de registro, los estados peligrosos
+// y los cambios respecto del registro aprobado (si no hay baseline, el actual queda como inicial)
+func applyRDAPRegistration(check *models.Check, current RDAPRegistration, baselineKey string, store StateStore) {
+	check.Metadata["registrar"] = current.Registrar
+	check.Metadata["nameservers"] = current.Nameservers
+	check.Metadata["dnssec_signed"] = current.DelegationSigned
+	check.Metadata["ds_records"] = current.DSRecords
+
+	var problems []string
+	raise := func(newStatus string, problem string) {
+		problems = append(problems, problem)
+		if newStatus == "error" || check.Status == "ok" {
+			check.Status = newStatus
+		}
+	}
+
+	var dangerous []string
+	for _, status := range current.Statuses {
+		if dangerousDomainStatuses[normalizeDomainStatus(status)] {
+			dangerous = append(dangerous, status)
+		}
+	}
+	check.Metadata["dangerous_statuses"] = dangerous
+	if len(dangerous) > 0 {
+		raise("error", "estado peligroso: "+strings.Join(dangerous, ", "))
+	}
+
+	if store != nil && baselineKey != "" {
+		raiseRDAPRegistrationChanges(check, current, baselineKey, store, raise)
+	}
+
+	if len(problems) > 0 {
+		check.Message = fmt.Sprintf("%s. Registro: %s", check.Message, strings.Join(problems, "; "))
+	}
+}
+
+// raiseRDAPRegistrationChanges compara el registro actual con el aprobado y reporta cada cambio
+func raiseRDAPRegistrationChanges(check *models.Check, current RDAPRegistration, baselineKey string, store StateStore,
+	raise func(status string, problem string)) {
+
+	// Guardar siempre lo observado para poder aprobarlo como nuevo baseline
+	if err := store.Put(observedRDAPKey(baselineKey), current); err != nil {
+		raise("warning", "no se pudo guardar el registro observado: "+err.Error())
+		return
+	}
+
+	var baseline RDAPRegistration
+	found, err := store.Get(approvedRDAPKey(baselineKey), &baseline)
+	if err != nil {
+		raise("warning", "no se pudo leer el registro aprobado: "+err.Error())
+		return
+	}
+	if !found {
+		if err := store.Put(approvedRDAPKey(baselineKey), current); err != nil {
+			raise("warning", "no se pudo registrar el baseline inicial: "+err.Error())
+			return
+		}
+		baseline = current
+	}
+	check.Metadata["registration_baseline_at"] = baseline.CapturedAt.Format(time.RFC3339)
+
+	changes := compareRDAPRegistration(baseline, current)
+	check.Metadata["registration_changes"] = changes
+	for _, change := range changes {
+		raise(change.Severity, fmt.Sprintf("%s cambió de [%s] a [%s]", change.Field, change.Previous, change.Current))
+	}
+	if len(changes) > 0 {
+		check.Metadata["registration_changed"] = true
+	}
 }
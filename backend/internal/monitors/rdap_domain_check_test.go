@@ -0,0 +1,96 @@
+package monitors
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// memoryStateStore StateStore en memoria para los tests
+type memoryStateStore map[string][]byte
+
+func (s memoryStateStore) Get(key string, v interface{}) (bool, error) {
+	data, exists := s[key]
+	if !exists {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, v)
+}
+
+func (s memoryStateStore) Put(key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s[key] = data
+	return nil
+}
+
+// newWHOISTestServer levanta un servidor WHOIS local que responde siempre lo mismo y retorna su dirección
+func newWHOISTestServer(t *testing.T, response string) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("no se pudo abrir el servidor WHOIS: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Read(make([]byte, 512))
+			conn.Write([]byte(response))
+			conn.Close()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestCheckRDAPDomainWHOISFallbackReportsSkippedComparison(t *testing.T) {
+	rdap := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(rdap.Close)
+
+	whois := newWHOISTestServer(t, "Domain Name: ejemplo.com.ar\r\n"+
+		"Registrar: NIC Argentina\r\n"+
+		"Registry Expiry Date: 2099-01-01T00:00:00Z\r\n"+
+		"Name Server: ns1.ejemplo.com.ar\r\n"+
+		"Domain Status: ok\r\n")
+
+	store := memoryStateStore{}
+	check := CheckRDAPDomain(RDAPCheckConfig{
+		Domain:      "ejemplo.com.ar",
+		RDAPBaseURL: rdap.URL + "/domain/",
+		BaselineKey: "infraestructura/domain-expiry",
+		WHOIS:       WHOISConfig{Enabled: true, Server: whois},
+		CheckID:     "domain-expiry",
+		CheckName:   "Expiración de dominio",
+		WarningDays: 30,
+		ErrorDays:   7,
+	}, store)
+
+	if check.Metadata["lookup_source"] != "whois" {
+		t.Fatalf("lookup_source esperado whois, obtenido %v (%s)", check.Metadata["lookup_source"], check.Message)
+	}
+	if check.Status != "ok" {
+		t.Errorf("estado esperado ok, obtenido %s (%s)", check.Status, check.Message)
+	}
+	if check.Metadata["registration_compare_skipped"] != true {
+		t.Error("falta registration_compare_skipped en la metadata")
+	}
+	if !strings.Contains(check.Message, "no verificados") {
+		t.Errorf("el mensaje debe informar que no se verificaron cambios: %s", check.Message)
+	}
+	// Los datos de WHOIS no deben quedar como registro observado ni aprobado
+	if len(store) != 0 {
+		t.Errorf("no se esperaban escrituras en el StateStore, hay %d", len(store))
+	}
+}
@@ -0,0 +1,189 @@
+package monitors
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RDAPNameserver servidor de nombres delegado en la respuesta RDAP
+type RDAPNameserver struct {
+	LDHName string `json:"ldhName"`
+}
+
+// RDAPEntity entidad asociada al dominio (registrar, registrante, contactos)
+type RDAPEntity struct {
+	Handle     string        `json:"handle"`
+	Roles      []string      `json:"roles"`
+	VCardArray []interface{} `json:"vcardArray"`
+	PublicIDs  []struct {
+		Type       string `json:"type"`
+		Identifier string `json:"identifier"`
+	} `json:"publicIds"`
+	Entities []RDAPEntity `json:"entities"`
+}
+
+// RDAPSecureDNS datos DNSSEC de la delegación
+type RDAPSecureDNS struct {
+	DelegationSigned *bool `json:"delegationSigned"`
+	DSData           []struct {
+		KeyTag     int    `json:"keyTag"`
+		Algorithm  int    `json:"algorithm"`
+		DigestType int    `json:"digestType"`
+		Digest     string `json:"digest"`
+	} `json:"dsData"`
+}
+
+// RDAPRegistration datos de registro de un dominio que se comparan entre ejecuciones
+type RDAPRegistration struct {
+	Registrar        string    `json:"registrar"`
+	Nameservers      []string  `json:"nameservers"`
+	DelegationSigned bool      `json:"delegation_signed"`
+	DSRecords        []string  `json:"ds_records"`
+	Statuses         []string  `json:"statuses"`
+	CapturedAt       time.Time `json:"captured_at"`
+}
+
+// dangerousDomainStatuses estados RDAP/EPP que dejan el dominio fuera de servicio o a punto de perderse
+// Se comparan normalizados (sin espacios y en minúsculas: "client hold" = "clientHold")
+var dangerousDomainStatuses = map[string]bool{
+	"clienthold":       true,
+	"serverhold":       true,
+	"pendingdelete":    true,
+	"redemptionperiod": true,
+}
+
+// normalizeDomainStatus unifica la forma RDAP ("client hold") y EPP ("clientHold") de un estado
+func normalizeDomainStatus(status string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(status), " ", ""))
+}
+
+// newRDAPRegistration extrae los datos de registro de una respuesta RDAP
+// Las listas se ordenan para que la comparación no dependa del orden de la respuesta
+func newRDAPRegistration(data RDAPResponse) RDAPRegistration {
+	registration := RDAPRegistration{CapturedAt: time.Now()}
+
+	for _, nameserver := range data.Nameservers {
+		if name := strings.ToLower(strings.TrimSuffix(nameserver.LDHName, ".")); name != "" {
+			registration.Nameservers = append(registration.Nameservers, name)
+		}
+	}
+	sort.Strings(registration.Nameservers)
+
+	if registrar := findRDAPEntity(data.Entities, "registrar"); registrar != nil {
+		registration.Registrar = rdapEntityLabel(*registrar)
+	}
+
+	if data.SecureDNS != nil {
+		registration.DelegationSigned = data.SecureDNS.DelegationSigned != nil && *data.SecureDNS.DelegationSigned
+		for _, ds := range data.SecureDNS.DSData {
+			registration.DSRecords = append(registration.DSRecords,
+				fmt.Sprintf("%d %d %d %s", ds.KeyTag, ds.Algorithm, ds.DigestType, strings.ToUpper(ds.Digest)))
+		}
+		sort.Strings(registration.DSRecords)
+	}
+
+	for _, status := range data.Status {
+		registration.Statuses = append(registration.Statuses, strings.ToLower(strings.TrimSpace(status)))
+	}
+	sort.Strings(registration.Statuses)
+
+	return registration
+}
+
+// findRDAPEntity busca (también en entidades anidadas) la primera entidad con el rol indicado
+func findRDAPEntity(entities []RDAPEntity, role string) *RDAPEntity {
+	for i := range entities {
+		for _, entityRole := range entities[i].Roles {
+			if strings.EqualFold(entityRole, role) {
+				return &entities[i]
+			}
+		}
+		if nested := findRDAPEntity(entities[i].Entities, role); nested != nil {
+			return nested
+		}
+	}
+	return nil
+}
+
+// rdapEntityLabel nombre legible de una entidad: fn del vCard, con el ID IANA o el handle si existen
+func rdapEntityLabel(entity RDAPEntity) string {
+	name := ""
+	if len(entity.VCardArray) == 2 {
+		if properties, ok := entity.VCardArray[1].([]interface{}); ok {
+			for _, property := range properties {
+				fields, ok := property.([]interface{})
+				if ok && len(fields) == 4 && fields[0] == "fn" {
+					name, _ = fields[3].(string)
+					break
+				}
+			}
+		}
+	}
+
+	id := entity.Handle
+	for _, publicID := range entity.PublicIDs {
+		if strings.Contains(strings.ToLower(publicID.Type), "iana") {
+			id = publicID.Identifier
+			break
+		}
+	}
+
+	switch {
+	case name != "" && id != "":
+		return fmt.Sprintf("%s (%s)", name, id)
+	case name != "":
+		return name
+	}
+	return id
+}
+
+// rdapRegistrationChange cambio detectado en un campo del registro
+type rdapRegistrationChange struct {
+	Field    string `json:"field"`
+	Previous string `json:"previous"`
+	Current  string `json:"current"`
+	Severity string `json:"severity"`
+}
+
+// compareRDAPRegistration lista los cambios entre el registro aprobado y el actual
+// Nameservers, registrar y DNSSEC son error (posible secuestro del dominio); los estados, warning
+func compareRDAPRegistration(previous RDAPRegistration, current RDAPRegistration) []rdapRegistrationChange {
+	var changes []rdapRegistrationChange
+	compare := func(field string, before string, after string, severity string) {
+		if before != after {
+			changes = append(changes, rdapRegistrationChange{Field: field, Previous: before, Current: after, Severity: severity})
+		}
+	}
+
+	compare("nameservers", strings.Join(previous.Nameservers, ", "), strings.Join(current.Nameservers, ", "), "error")
+	compare("registrar", previous.Registrar, current.Registrar, "error")
+	compare("dnssec", fmt.Sprintf("%t", previous.DelegationSigned), fmt.Sprintf("%t", current.DelegationSigned), "error")
+	compare("ds_records", strings.Join(previous.DSRecords, "; "), strings.Join(current.DSRecords, "; "), "error")
+	compare("statuses", strings.Join(previous.Statuses, ", "), strings.Join(current.Statuses, ", "), "warning")
+	return changes
+}
+
+// AcceptRDAPRegistration aprueba el último registro observado como baseline de un check RDAP
+func AcceptRDAPRegistration(store StateStore, baselineKey string) (RDAPRegistration, error) {
+	var observed RDAPRegistration
+	found, err := store.Get(observedRDAPKey(baselineKey), &observed)
+	if err != nil {
+		return observed, err
+	}
+	if !found {
+		return observed, fmt.Errorf("no hay registro RDAP observado para %s", baselineKey)
+	}
+	return observed, store.Put(approvedRDAPKey(baselineKey), observed)
+}
+
+// approvedRDAPKey clave del registro RDAP aprobado en el StateStore
+func approvedRDAPKey(baselineKey string) string {
+	return "rdap-baseline/" + baselineKey
+}
+
+// observedRDAPKey clave del último registro RDAP observado en el StateStore
+func observedRDAPKey(baselineKey string) string {
+	return "rdap-observed/" + baselineKey
+}
@@ -0,0 +1,333 @@
+package monitors
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// evaluateRule evalúa una expresión booleana sobre las variables indicadas
+// Sintaxis: comparaciones (== != < <= > >=), lógicos (&& || !), paréntesis,
+// números, 'textos', true/false/null y nombres de columnas
+// Ejemplo: "pendientes > 10 && antiguedad_horas >= 48"
+func evaluateRule(expression string, variables map[string]interface{}) (bool, error) {
+	tokens, err := tokenizeRule(expression)
+	if err != nil {
+		return false, err
+	}
+
+	parser := &ruleParser{tokens: tokens, variables: variables}
+	value, err := parser.parseOr()
+	if err != nil {
+		return false, err
+	}
+	if parser.pos < len(parser.tokens) {
+		return false, fmt.Errorf("token inesperado '%s'", parser.tokens[parser.pos].text)
+	}
+
+	result, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("la expresión no retorna un booleano")
+	}
+	return result, nil
+}
+
+// ruleToken token de una expresión de regla
+type ruleToken struct {
+	kind string // "number", "string", "ident", "op", "paren"
+	text string
+}
+
+// tokenizeRule separa la expresión en tokens
+func tokenizeRule(expression string) ([]ruleToken, error) {
+	var tokens []ruleToken
+	runes := []rune(expression)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, ruleToken{kind: "paren", text: string(r)})
+			i++
+		case r == '\'' || r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("texto sin cerrar en la posición %d", i)
+			}
+			tokens = append(tokens, ruleToken{kind: "string", text: string(runes[i+1 : end])})
+			i = end + 1
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]) && !previousIsOperand(tokens)):
+			end := i + 1
+			for end < len(runes) && (unicode.IsDigit(runes[end]) || runes[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, ruleToken{kind: "number", text: string(runes[i:end])})
+			i = end
+		case unicode.IsLetter(r) || r == '_':
+			end := i + 1
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) || runes[end] == '_' || runes[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, ruleToken{kind: "ident", text: string(runes[i:end])})
+			i = end
+		default:
+			matched := false
+			for _, op := range []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!"} {
+				if strings.HasPrefix(string(runes[i:]), op) {
+					tokens = append(tokens, ruleToken{kind: "op", text: op})
+					i += len([]rune(op))
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("carácter inesperado '%c' en la posición %d", r, i)
+			}
+		}
+	}
+
+	return tokens, nil
+}
+
+// previousIsOperand indica si el último token es un valor (para distinguir "-" binario de negativo)
+func previousIsOperand(tokens []ruleToken) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+	last := tokens[len(tokens)-1]
+	return last.kind == "number" || last.kind == "string" || last.kind == "ident" || last.text == ")"
+}
+
+// ruleParser parser descendente recursivo de expresiones de regla
+type ruleParser struct {
+	tokens    []ruleToken
+	pos       int
+	variables map[string]interface{}
+}
+
+// peek retorna el token actual sin consumirlo
+func (p *ruleParser) peek() *ruleToken {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.pos]
+}
+
+// parseOr: and ("||" and)*
+func (p *ruleParser) parseOr() (interface{}, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t != nil && t.text == "||"; t = p.peek() {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = truthy(left) || truthy(right)
+	}
+	return left, nil
+}
+
+// parseAnd: unary ("&&" unary)*
+func (p *ruleParser) parseAnd() (interface{}, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t != nil && t.text == "&&"; t = p.peek() {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = truthy(left) && truthy(right)
+	}
+	return left, nil
+}
+
+// parseUnary: "!" unary | comparison
+func (p *ruleParser) parseUnary() (interface{}, error) {
+	if t := p.peek(); t != nil && t.text == "!" {
+		p.pos++
+		value, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return !truthy(value), nil
+	}
+	return p.parseComparison()
+}
+
+// parseComparison: primary (op primary)?
+func (p *ruleParser) parseComparison() (interface{}, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+	if t == nil || t.kind != "op" || t.text == "&&" || t.text == "||" || t.text == "!" {
+		return left, nil
+	}
+	p.pos++
+
+	right, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	return compareRuleValues(left, t.text, right)
+}
+
+// parsePrimary: número | texto | identificador | "(" or ")"
+func (p *ruleParser) parsePrimary() (interface{}, error) {
+	t := p.peek()
+	if t == nil {
+		return nil, fmt.Errorf("expresión incompleta")
+	}
+	p.pos++
+
+	switch t.kind {
+	case "number":
+		return strconv.ParseFloat(t.text, 64)
+	case "string":
+		return t.text, nil
+	case "paren":
+		if t.text != "(" {
+			return nil, fmt.Errorf("')' inesperado")
+		}
+		value, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.peek(); closing == nil || closing.text != ")" {
+			return nil, fmt.Errorf("falta ')'")
+		}
+		p.pos++
+		return value, nil
+	case "ident":
+		switch strings.ToLower(t.text) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		value, exists := p.variables[t.text]
+		if !exists {
+			return nil, fmt.Errorf("columna desconocida '%s'", t.text)
+		}
+		return value, nil
+	}
+	return nil, fmt.Errorf("token inesperado '%s'", t.text)
+}
+
+// compareRuleValues compara dos valores numérica o textualmente
+func compareRuleValues(left interface{}, op string, right interface{}) (bool, error) {
+	if left == nil || right == nil {
+		switch op {
+		case "==":
+			return left == nil && right == nil, nil
+		case "!=":
+			return !(left == nil && right == nil), nil
+		}
+		return false, nil
+	}
+
+	leftNum, leftIsNum := toFloat(left)
+	rightNum, rightIsNum := toFloat(right)
+	if leftIsNum && rightIsNum {
+		switch op {
+		case "==":
+			return leftNum == rightNum, nil
+		case "!=":
+			return leftNum != rightNum, nil
+		case "<":
+			return leftNum < rightNum, nil
+		case "<=":
+			return leftNum <= rightNum, nil
+		case ">":
+			return leftNum > rightNum, nil
+		case ">=":
+			return leftNum >= rightNum, nil
+		}
+	}
+
+	leftStr, rightStr := fmt.Sprintf("%v", left), fmt.Sprintf("%v", right)
+	switch op {
+	case "==":
+		return leftStr == rightStr, nil
+	case "!=":
+		return leftStr != rightStr, nil
+	case "<":
+		return leftStr < rightStr, nil
+	case "<=":
+		return leftStr <= rightStr, nil
+	case ">":
+		return leftStr > rightStr, nil
+	case ">=":
+		return leftStr >= rightStr, nil
+	}
+	return false, fmt.Errorf("operador desconocido '%s'", op)
+}
+
+// truthy interpreta un valor como booleano
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	}
+	if n, ok := toFloat(value); ok {
+		return n != 0
+	}
+	return true
+}
+
+// toFloat convierte valores numéricos (o textos numéricos, como DECIMAL de SQL Server) a float64
+// NUMERIC de PostgreSQL se convierte con Float64Value, INTERVAL a segundos y las fechas a segundos Unix
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case bool:
+		return 0, false
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil
+	case pgtype.Numeric:
+		return pgNumericToFloat(v)
+	case pgtype.Interval:
+		return pgIntervalSeconds(v), v.Valid
+	case time.Time:
+		// Fechas como segundos Unix para poder compararlas
+		return float64(v.Unix()), !v.IsZero()
+	}
+	return 0, false
+}
@@ -0,0 +1,334 @@
+package monitors
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/saltacompra/monitor/internal/models"
+)
+
+// ScenarioCheckConfig contiene la configuración para un check sintético de varios pasos
+type ScenarioCheckConfig struct {
+	CheckID             string
+	CheckName           string
+	BaseURL             string            // URL base para pasos con URL relativa
+	TimeoutSeconds      int               // Timeout de cada petición HTTP
+	SkipSSLVerification bool              // Saltar verificación SSL
+	Variables           map[string]string // Variables iniciales (ej: usuario y contraseña)
+	Steps               []ScenarioStep
+}
+
+// ScenarioStep define un paso HTTP del escenario
+type ScenarioStep struct {
+	Name              string
+	Method            string            // GET por defecto, POST si tiene Form o Body
+	URL               string            // Absoluta o relativa a BaseURL, admite {{variable}}
+	Headers           map[string]string // Admiten {{variable}}
+	Form              map[string]string // Campos de formulario, admiten {{variable}}
+	Body              string            // Body crudo, admite {{variable}}
+	ExpectStatus      []int             // Códigos aceptados (por defecto 2xx)
+	ExpectContains    []string          // Textos que deben estar en la respuesta
+	ExpectNotContains []string          // Textos que no deben estar (ej: "Usuario o contraseña incorrectos")
+	WarningMs         int64             // Umbral de ms para warning del paso
+	Extract           []ScenarioExtract // Variables a capturar para pasos siguientes
+}
+
+// ScenarioExtract define una variable a capturar de la respuesta
+type ScenarioExtract struct {
+	Name    string
+	Source  string // "body", "input", "header" o "cookie"
+	Key     string // Nombre del input, header o cookie
+	Pattern string // Regex con un grupo de captura (source "body")
+}
+
+// scenarioVariablePattern encuentra referencias {{variable}} en plantillas
+var scenarioVariablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// inputTagPattern encuentra tags <input ...> en HTML
+var inputTagPattern = regexp.MustCompile(`(?is)<input\b[^>]*>`)
+
+// htmlAttributePattern encuentra atributos nombre=valor (con comillas dobles, simples o sin comillas) de un tag
+var htmlAttributePattern = regexp.MustCompile(`(?s)\s([^\s=/>]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+
+// CheckScenario ejecuta secuencialmente los pasos del escenario compartiendo cookies
+// Se detiene en el primer paso que falla y reporta tiempos por paso en metadata
+func CheckScenario(config ScenarioCheckConfig) models.Check {
+	check := models.Check{
+		ID:        config.CheckID,
+		Type:      "login",
+		Name:      config.CheckName,
+		LastCheck: time.Now(),
+		Metadata:  make(map[string]interface{}),
+	}
+
+	if len(config.Steps) == 0 {
+		check.Status = "error"
+		check.Message = "El escenario no tiene pasos configurados"
+		return check
+	}
+
+	timeout := config.TimeoutSeconds
+	if timeout == 0 {
+		timeout = 30 // Default 30 segundos
+	}
+
+	// Cliente con cookie jar compartido entre pasos
+	client := getHTTPClient(timeout, config.SkipSSLVerification)
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		check.Status = "error"
+		check.Message = "Error al crear cookie jar: " + err.Error()
+		return check
+	}
+	client.Jar = jar
+
+	variables := make(map[string]string)
+	for name, value := range config.Variables {
+		variables[name] = value
+	}
+
+	var stepResults []map[string]interface{}
+	var warnings []string
+	start := time.Now()
+
+	for i, step := range config.Steps {
+		stepName := step.Name
+		if stepName == "" {
+			stepName = fmt.Sprintf("paso %d", i+1)
+		}
+
+		result := runScenarioStep(client, config.BaseURL, step, variables)
+		result["step"] = i + 1
+		result["name"] = stepName
+		stepResults = append(stepResults, result)
+
+		if result["status"] == "error" {
+			check.ResponseTime = time.Since(start).Milliseconds()
+			check.Status = "error"
+			check.Message = fmt.Sprintf("Falló el paso %d (%s): %s", i+1, stepName, result["message"])
+			check.Metadata["steps"] = stepResults
+			check.Metadata["failed_step"] = stepName
+			check.Metadata["failed_step_index"] = i + 1
+			return check
+		}
+		if result["status"] == "warning" {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", stepName, result["message"]))
+		}
+	}
+
+	check.ResponseTime = time.Since(start).Milliseconds()
+	check.Metadata["steps"] = stepResults
+	check.Metadata["steps_completed"] = len(config.Steps)
+
+	if len(warnings) > 0 {
+		check.Status = "warning"
+		check.Message = fmt.Sprintf("Escenario completado con advertencias (%dms): %v", check.ResponseTime, warnings)
+	} else {
+		check.Status = "ok"
+		check.Message = fmt.Sprintf("Escenario completado: %d pasos en %dms", len(config.Steps), check.ResponseTime)
+	}
+
+	return check
+}
+
+// runScenarioStep ejecuta un paso y captura variables en el mapa recibido
+// Retorna el resultado del paso para metadata (status, message, http_status, response_time_ms)
+func runScenarioStep(client *http.Client, baseURL string, step ScenarioStep, variables map[string]string) map[string]interface{} {
+	result := map[string]interface{}{}
+	fail := func(message string) map[string]interface{} {
+		result["status"] = "error"
+		result["message"] = message
+		return result
+	}
+
+	stepURL, err := resolveScenarioURL(baseURL, expandScenarioTemplate(step.URL, variables))
+	if err != nil {
+		return fail("URL inválida: " + err.Error())
+	}
+	result["url"] = stepURL
+
+	// Construir body (formulario o crudo)
+	method := strings.ToUpper(step.Method)
+	var body string
+	contentType := ""
+	if len(step.Form) > 0 {
+		form := url.Values{}
+		for name, value := range step.Form {
+			form.Set(name, expandScenarioTemplate(value, variables))
+		}
+		body = form.Encode()
+		contentType = "application/x-www-form-urlencoded"
+	} else if step.Body != "" {
+		body = expandScenarioTemplate(step.Body, variables)
+	}
+	if method == "" {
+		method = http.MethodGet
+		if body != "" {
+			method = http.MethodPost
+		}
+	}
+
+	req, err := http.NewRequest(method, stepURL, strings.NewReader(body))
+	if err != nil {
+		return fail("Error al crear petición: " + err.Error())
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for name, value := range step.Headers {
+		req.Header.Set(name, expandScenarioTemplate(value, variables))
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		result["response_time_ms"] = time.Since(start).Milliseconds()
+		return fail("No se pudo conectar: " + err.Error())
+	}
+	defer resp.Body.Close()
+
+	respBody, err := readResponseBody(resp)
+	elapsed := time.Since(start).Milliseconds()
+	result["response_time_ms"] = elapsed
+	result["http_status"] = resp.StatusCode
+	result["final_url"] = resp.Request.URL.String()
+	if err != nil {
+		return fail("Error al leer contenido: " + err.Error())
+	}
+
+	// Validar código HTTP
+	if !scenarioStatusAccepted(resp.StatusCode, step.ExpectStatus) {
+		return fail(fmt.Sprintf("Código HTTP inesperado: %d", resp.StatusCode))
+	}
+
+	// Validar contenido
+	if ok, msg := checkContentPresence(respBody, step.ExpectContains); !ok {
+		return fail(msg)
+	}
+	for _, text := range step.ExpectNotContains {
+		if strings.Contains(respBody, text) {
+			return fail("Contenido no esperado encontrado: " + text)
+		}
+	}
+
+	// Capturar variables para los pasos siguientes
+	for _, extract := range step.Extract {
+		value, err := extractScenarioValue(client, resp, respBody, extract)
+		if err != nil {
+			return fail(fmt.Sprintf("No se pudo capturar '%s': %s", extract.Name, err.Error()))
+		}
+		variables[extract.Name] = value
+	}
+
+	result["status"] = "ok"
+	result["message"] = fmt.Sprintf("HTTP %d en %dms", resp.StatusCode, elapsed)
+	if step.WarningMs > 0 && elapsed >= step.WarningMs {
+		result["status"] = "warning"
+		result["message"] = fmt.Sprintf("Tiempo de respuesta elevado: %dms", elapsed)
+	}
+
+	return result
+}
+
+// extractScenarioValue obtiene el valor de una variable desde la respuesta
+func extractScenarioValue(client *http.Client, resp *http.Response, body string, extract ScenarioExtract) (string, error) {
+	switch extract.Source {
+	case "header":
+		value := resp.Header.Get(extract.Key)
+		if value == "" {
+			return "", fmt.Errorf("header %s ausente", extract.Key)
+		}
+		return value, nil
+
+	case "cookie":
+		for _, cookie := range client.Jar.Cookies(resp.Request.URL) {
+			if cookie.Name == extract.Key {
+				return cookie.Value, nil
+			}
+		}
+		return "", fmt.Errorf("cookie %s ausente", extract.Key)
+
+	case "input":
+		value, found := findInputValue(body, extract.Key)
+		if !found {
+			return "", fmt.Errorf("campo de formulario %s no encontrado", extract.Key)
+		}
+		return value, nil
+
+	default: // "body"
+		re, err := regexp.Compile(extract.Pattern)
+		if err != nil {
+			return "", fmt.Errorf("regex inválida: %w", err)
+		}
+		matches := re.FindStringSubmatch(body)
+		if len(matches) < 2 {
+			return "", fmt.Errorf("patrón sin coincidencias")
+		}
+		return matches[1], nil
+	}
+}
+
+// findInputValue busca el atributo value de un <input name="..."> (ej: token CSRF)
+func findInputValue(body string, name string) (string, bool) {
+	for _, tag := range inputTagPattern.FindAllString(body, -1) {
+		if getHTMLAttribute(tag, "name") == name {
+			return getHTMLAttribute(tag, "value"), true
+		}
+	}
+	return "", false
+}
+
+// getHTMLAttribute obtiene el valor de un atributo dentro de un tag HTML
+func getHTMLAttribute(tag string, attr string) string {
+	for _, matches := range htmlAttributePattern.FindAllStringSubmatch(tag, -1) {
+		if strings.EqualFold(matches[1], attr) {
+			return matches[2] + matches[3] + matches[4]
+		}
+	}
+	return ""
+}
+
+// expandScenarioTemplate reemplaza {{variable}} por su valor actual
+func expandScenarioTemplate(template string, variables map[string]string) string {
+	return scenarioVariablePattern.ReplaceAllStringFunc(template, func(match string) string {
+		name := scenarioVariablePattern.FindStringSubmatch(match)[1]
+		if value, ok := variables[name]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// resolveScenarioURL resuelve una URL relativa contra la URL base
+func resolveScenarioURL(baseURL string, stepURL string) (string, error) {
+	ref, err := url.Parse(stepURL)
+	if err != nil {
+		return "", err
+	}
+	if ref.IsAbs() || baseURL == "" {
+		return ref.String(), nil
+	}
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+// scenarioStatusAccepted indica si el código HTTP es aceptado por el paso
+func scenarioStatusAccepted(statusCode int, expected []int) bool {
+	if len(expected) == 0 {
+		return statusCode >= 200 && statusCode < 300
+	}
+	for _, code := range expected {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,344 @@
+package monitors
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/saltacompra/monitor/internal/models"
+)
+
+// Reglas para elegir la fila más reciente de la hoja
+const (
+	LatestRowLast         = "last"          // Última fila con datos
+	LatestRowMaxTimestamp = "max_timestamp" // Fila con el timestamp más reciente
+)
+
+// SheetFreshnessCheckConfig configuración para verificar que una hoja alimentada
+// por Apps Script (u otro proceso) recibe filas nuevas a diario
+type SheetFreshnessCheckConfig struct {
+	SpreadsheetID      string
+	SheetName          string
+	AuthMethod         string // "service_account" o "api_key"
+	CredentialsFile    string
+	APIKey             string
+	Endpoint           string // Opcional, URL base alternativa de la API (ej: servidor local de pruebas)
+	CheckID            string
+	CheckName          string
+	TimestampColumn    int      // Índice (desde 0) de la columna con la fecha de la fila
+	FilenameColumn     int      // Índice de la columna con el nombre de archivo (-1 = sin archivo)
+	HeaderRows         int      // Filas de encabezado a omitir
+	TimestampLayouts   []string // Formatos de fecha/hora aceptados (layouts de Go, default 2006-01-02)
+	TimeZone           string   // Zona horaria de la hoja (IANA, default la del servidor)
+	FilenamePattern    string   // Regex con un grupo nombrado "date" (o el primer grupo) con la fecha
+	FilenameDateLayout string   // Formato de la fecha dentro del nombre de archivo (default 20060102)
+	ExpectedFilename   string   // Nombre esperado con {date} como marcador (opcional, ej: bd_{date}.zip)
+	LatestRow          string   // "last" (default) o "max_timestamp"
+	WarningDays        int
+	ErrorDays          int
+	History            SheetHistoryConfig // Análisis del historial (opcional)
+	TailRows           int                // Filas leídas desde el final de la hoja (0 = hoja completa)
+	TimeoutSeconds     int
+}
+
+// sheetFreshnessRules reglas de parseo ya resueltas de la configuración
+type sheetFreshnessRules struct {
+	location        *time.Location
+	layouts         []string
+	filenamePattern *regexp.Regexp
+	filenameLayout  string
+}
+
+// newSheetFreshnessRules valida y completa con defaults la configuración de parseo
+func newSheetFreshnessRules(config SheetFreshnessCheckConfig) (sheetFreshnessRules, error) {
+	rules := sheetFreshnessRules{
+		location:       time.Local,
+		layouts:        config.TimestampLayouts,
+		filenameLayout: config.FilenameDateLayout,
+	}
+	if len(rules.layouts) == 0 {
+		rules.layouts = []string{"2006-01-02"}
+	}
+	if rules.filenameLayout == "" {
+		rules.filenameLayout = "20060102"
+	}
+	if config.TimeZone != "" {
+		location, err := time.LoadLocation(config.TimeZone)
+		if err != nil {
+			return rules, fmt.Errorf("zona horaria inválida '%s': %w", config.TimeZone, err)
+		}
+		rules.location = location
+	}
+	if config.FilenameColumn >= 0 && config.FilenamePattern != "" {
+		re, err := regexp.Compile(config.FilenamePattern)
+		if err != nil {
+			return rules, fmt.Errorf("patrón de nombre de archivo inválido: %w", err)
+		}
+		if re.NumSubexp() == 0 {
+			return rules, fmt.Errorf("el patrón de nombre de archivo debe tener un grupo de captura con la fecha")
+		}
+		rules.filenamePattern = re
+	}
+	return rules, nil
+}
+
+// parseTimestamp interpreta un valor de la hoja con el primer formato que coincida
+func (r sheetFreshnessRules) parseTimestamp(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range r.layouts {
+		if parsed, err := time.ParseInLocation(layout, value, r.location); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("formato de fecha no reconocido")
+}
+
+// filenameDate extrae la fecha de un nombre de archivo según el patrón configurado
+func (r sheetFreshnessRules) filenameDate(filename string) (time.Time, error) {
+	matches := r.filenamePattern.FindStringSubmatch(filename)
+	if matches == nil {
+		return time.Time{}, fmt.Errorf("formato de archivo inválido")
+	}
+
+	group := 1
+	if index := r.filenamePattern.SubexpIndex("date"); index > 0 {
+		group = index
+	}
+	return time.ParseInLocation(r.filenameLayout, matches[group], r.location)
+}
+
+// sameDay indica si dos fechas corresponden al mismo día calendario
+func sameDay(a time.Time, b time.Time) bool {
+	return a.Year() == b.Year() && a.Month() == b.Month() && a.Day() == b.Day()
+}
+
+// daysBetween cantidad de días calendario entre dos fechas (b - a)
+func daysBetween(a time.Time, b time.Time) int {
+	dayA := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	dayB := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(dayB.Sub(dayA).Hours() / 24)
+}
+
+// sheetColumnLetter convierte un índice de columna (desde 0) a su letra (0 = A, 26 = AA)
+func sheetColumnLetter(index int) string {
+	letter := ""
+	for index >= 0 {
+		letter = string(rune('A'+index%26)) + letter
+		index = index/26 - 1
+	}
+	return letter
+}
+
+// sheetRangePrefix arma el comienzo de un rango A1 con el nombre de la hoja entre comillas ('Hoja'!A)
+func sheetRangePrefix(sheetName string) string {
+	return fmt.Sprintf("'%s'!A", strings.ReplaceAll(sheetName, "'", "''"))
+}
+
+// CheckSheetFreshness verifica que la fila más reciente de una hoja sea del día
+// y, si la hoja registra archivos, que la fecha del archivo coincida con la de la fila
+func CheckSheetFreshness(config SheetFreshnessCheckConfig) models.Check {
+	check := models.Check{
+		ID:        config.CheckID,
+		Type:      "sheet-freshness",
+		Name:      config.CheckName,
+		LastCheck: time.Now(),
+		Metadata:  make(map[string]interface{}),
+	}
+
+	rules, err := newSheetFreshnessRules(config)
+	if err != nil {
+		check.Status = "error"
+		check.Message = "Configuración inválida: " + err.Error()
+		return check
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(config.TimeoutSeconds))
+	defer cancel()
+
+	start := time.Now()
+
+	// Servicio de Google Sheets reutilizado entre ejecuciones (token en caché)
+	srv, err := sheetsService(GoogleAuthConfig{
+		AuthMethod:      config.AuthMethod,
+		CredentialsFile: config.CredentialsFile,
+		APIKey:          config.APIKey,
+		Endpoint:        config.Endpoint,
+	})
+	if err != nil {
+		check.Status = "error"
+		check.Message = "Error al conectar con Google Sheets API: " + err.Error()
+		check.Metadata["error_type"] = "connection_failed"
+		return check
+	}
+
+	// Leer solo hasta la última columna configurada y, si corresponde, solo el final de la hoja
+	lastColumn := config.TimestampColumn
+	if config.FilenameColumn > lastColumn {
+		lastColumn = config.FilenameColumn
+	}
+	tailRows := config.TailRows
+	if tailRows > 0 && config.History.Days > 0 && tailRows < config.History.Days*2 {
+		// El historial necesita al menos los días analizados (con margen para duplicados)
+		tailRows = config.History.Days * 2
+	}
+	sheet, err := readSheetRows(ctx, srv, config.SpreadsheetID, config.SheetName, lastColumn, config.HeaderRows, tailRows)
+	check.ResponseTime = time.Since(start).Milliseconds()
+
+	if err != nil {
+		errorType := googleErrorType(err)
+		check.Status = "error"
+		check.Metadata["error_type"] = errorType
+		if errorType == "quota_exceeded" {
+			check.Message = "Cuota de Google Sheets API excedida: " + err.Error()
+		} else {
+			check.Message = "Error al leer datos de la hoja: " + err.Error()
+		}
+		return check
+	}
+
+	rows := sheet.values
+	if nonEmptyRows(rows) == 0 {
+		check.Status = "error"
+		check.Message = "La hoja no contiene datos"
+		return check
+	}
+	if sheet.partial {
+		check.Metadata["sheet_rows"] = sheet.lastRow - config.HeaderRows
+		check.Metadata["rows_read"] = len(rows)
+	} else {
+		check.Metadata["total_rows"] = len(rows)
+	}
+	check.Metadata["latest_row_rule"] = latestRowRule(config.LatestRow)
+
+	evaluateLatestSheetRow(&check, rows, config, rules)
+
+	// Historial: días faltantes, duplicados, inconsistencias y cambios de horario
+	if config.History.Days > 0 {
+		applySheetHistory(&check, analyzeSheetHistory(rows, sheet.firstRow, config, rules, time.Now().In(rules.location)))
+	}
+
+	return check
+}
+
+// evaluateLatestSheetRow evalúa la antigüedad de la fila más reciente y la coherencia con su archivo
+func evaluateLatestSheetRow(check *models.Check, rows [][]interface{}, config SheetFreshnessCheckConfig, rules sheetFreshnessRules) {
+	// Elegir la fila más reciente según la regla configurada
+	latest, timestampDate, err := pickLatestRow(rows, config, rules)
+	if err != nil {
+		check.Status = "error"
+		check.Message = err.Error()
+		return
+	}
+
+	timestampStr := getStringValue(latest, config.TimestampColumn)
+	check.Metadata["last_timestamp"] = timestampStr
+
+	// Validar archivo asociado a la fila
+	var filenameStr string
+	if config.FilenameColumn >= 0 {
+		filenameStr = getStringValue(latest, config.FilenameColumn)
+		check.Metadata["last_filename"] = filenameStr
+	}
+
+	expectedFilename := ""
+	if config.ExpectedFilename != "" {
+		expectedFilename = strings.ReplaceAll(config.ExpectedFilename, "{date}", timestampDate.Format(rules.filenameLayout))
+		check.Metadata["expected_filename"] = expectedFilename
+	}
+
+	if rules.filenamePattern != nil {
+		filenameDate, err := rules.filenameDate(filenameStr)
+		check.Metadata["filename_recognized"] = err == nil
+		if err != nil {
+			check.Status = "warning"
+			check.Message = fmt.Sprintf("Formato de nombre de archivo no reconocido: %s", filenameStr)
+			if expectedFilename != "" {
+				check.Message += fmt.Sprintf(" (esperado: %s)", expectedFilename)
+			}
+			return
+		}
+		check.Metadata["filename_date"] = filenameDate.Format("2006-01-02")
+
+		// Validar coherencia: fecha de la fila debe coincidir con fecha del archivo
+		if !sameDay(timestampDate, filenameDate) {
+			check.Status = "error"
+			check.Message = fmt.Sprintf("Inconsistencia: TimeStamp (%s) no coincide con fecha del archivo (%s)",
+				timestampDate.Format("2006-01-02"), filenameDate.Format("2006-01-02"))
+			return
+		}
+	}
+
+	// Calcular días de antigüedad en la zona horaria de la hoja
+	daysOld := daysBetween(timestampDate, time.Now().In(rules.location))
+	check.Metadata["days_old"] = daysOld
+
+	description := timestampStr
+	if filenameStr != "" {
+		description = fmt.Sprintf("%s con %s", timestampStr, filenameStr)
+	}
+
+	// Determinar estado según antigüedad
+	if daysOld <= 0 {
+		check.Status = "ok"
+		if filenameStr != "" {
+			check.Message = fmt.Sprintf("Actualización del día completada con %s", filenameStr)
+		} else {
+			check.Message = fmt.Sprintf("Actualización del día completada (%s)", timestampStr)
+		}
+	} else if daysOld <= config.WarningDays {
+		check.Status = "warning"
+		check.Message = fmt.Sprintf("Última actualización es de hace %d día(s): %s", daysOld, description)
+	} else {
+		check.Status = "error"
+		check.Message = fmt.Sprintf("Actualización desactualizada: hace %d días (%s)", daysOld, description)
+	}
+}
+
+// latestRowRule normaliza la regla de fila más reciente
+func latestRowRule(rule string) string {
+	if rule == LatestRowMaxTimestamp {
+		return LatestRowMaxTimestamp
+	}
+	return LatestRowLast
+}
+
+// pickLatestRow retorna la fila más reciente y su fecha según la regla configurada
+func pickLatestRow(rows [][]interface{}, config SheetFreshnessCheckConfig, rules sheetFreshnessRules) ([]interface{}, time.Time, error) {
+	if latestRowRule(config.LatestRow) == LatestRowMaxTimestamp {
+		var latest []interface{}
+		var latestDate time.Time
+		for _, row := range rows {
+			parsed, err := rules.parseTimestamp(getStringValue(row, config.TimestampColumn))
+			if err != nil {
+				continue
+			}
+			if latest == nil || parsed.After(latestDate) {
+				latest, latestDate = row, parsed
+			}
+		}
+		if latest == nil {
+			return nil, time.Time{}, fmt.Errorf("Ninguna fila tiene un TimeStamp con formato válido")
+		}
+		return latest, latestDate, nil
+	}
+
+	// Última fila con datos (las filas vacías al final se ignoran)
+	for i := len(rows) - 1; i >= 0; i-- {
+		row := rows[i]
+		if len(row) == 0 {
+			continue
+		}
+		if len(row) <= config.TimestampColumn || len(row) <= config.FilenameColumn {
+			return nil, time.Time{}, fmt.Errorf("La última fila no tiene todas las columnas esperadas")
+		}
+		timestampStr := getStringValue(row, config.TimestampColumn)
+		parsed, err := rules.parseTimestamp(timestampStr)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("Formato de fecha inválido en TimeStamp: %s", timestampStr)
+		}
+		return row, parsed, nil
+	}
+	return nil, time.Time{}, fmt.Errorf("La hoja no contiene datos")
+}
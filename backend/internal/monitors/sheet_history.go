@@ -0,0 +1,287 @@
+package monitors
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/saltacompra/monitor/internal/models"
+)
+
+// SheetHistoryConfig configuración del análisis del historial de una hoja
+// Days = 0 deshabilita el análisis
+type SheetHistoryConfig struct {
+	Days                       int            // Días hacia atrás analizados (sin contar hoy)
+	BusinessDays               []time.Weekday // Días en que se espera una carga (vacío = todos)
+	Holidays                   []string       // Fechas sin carga esperada (YYYY-MM-DD)
+	TimeShiftMinutes           int            // Desvío del horario habitual de carga para warning (0 = no se evalúa)
+	CompletenessWarningPercent int            // % de días completos por debajo del cual hay warning
+	CompletenessErrorPercent   int            // % de días completos por debajo del cual hay error
+}
+
+// maxReportedHistoryItems cantidad máxima de huecos, duplicados o inconsistencias reportados
+const maxReportedHistoryItems = 10
+
+// recentHistoryDays días (contando hoy) en que un duplicado o una fecha inconsistente genera alerta
+// Los anteriores ya fueron alertados o corregidos a mano y solo se informan en la metadata
+const recentHistoryDays = 2
+
+// minTimeShiftSamples cantidad mínima de cargas previas para evaluar cambios de horario
+const minTimeShiftSamples = 5
+
+// sheetUpload carga registrada en la hoja
+type sheetUpload struct {
+	row          int // Número de fila en la hoja (desde 1)
+	timestamp    time.Time
+	timestampStr string
+	filename     string
+}
+
+// sheetHistoryReport resultado del análisis del historial
+type sheetHistoryReport struct {
+	status   string
+	problems []string
+	metadata map[string]interface{}
+}
+
+// raise registra un problema y actualiza el peor estado
+func (r *sheetHistoryReport) raise(status string, problem string) {
+	r.problems = append(r.problems, problem)
+	if status == "error" || r.status == "ok" {
+		r.status = status
+	}
+}
+
+// analyzeSheetHistory recorre las filas leídas y evalúa los últimos días del historial:
+// días faltantes según el calendario, cargas duplicadas, fechas de archivo inconsistentes
+// y cambios bruscos en el horario de carga (firstRow es el número de fila de rows[0])
+// Duplicados e inconsistencias solo alertan si son de hoy o ayer; los anteriores quedan en la metadata
+func analyzeSheetHistory(rows [][]interface{}, firstRow int, config SheetFreshnessCheckConfig, rules sheetFreshnessRules, now time.Time) sheetHistoryReport {
+	history := config.History
+	report := sheetHistoryReport{status: "ok", metadata: make(map[string]interface{})}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, rules.location)
+	from := today.AddDate(0, 0, -history.Days)
+	recentFrom := today.AddDate(0, 0, 1-recentHistoryDays)
+
+	// Agrupar cargas de la ventana por día
+	uploadsByDay := make(map[string][]sheetUpload)
+	unparsable := 0
+	var mismatches []map[string]interface{}
+	mismatchCount, recentMismatches := 0, 0
+	for i, row := range rows {
+		timestampStr := getStringValue(row, config.TimestampColumn)
+		if strings.TrimSpace(timestampStr) == "" {
+			continue
+		}
+		timestamp, err := rules.parseTimestamp(timestampStr)
+		if err != nil {
+			unparsable++
+			continue
+		}
+		if timestamp.Before(from) {
+			continue
+		}
+
+		upload := sheetUpload{row: firstRow + i, timestamp: timestamp, timestampStr: timestampStr}
+		if config.FilenameColumn >= 0 {
+			upload.filename = getStringValue(row, config.FilenameColumn)
+		}
+		day := timestamp.Format("2006-01-02")
+		uploadsByDay[day] = append(uploadsByDay[day], upload)
+
+		// Fecha del archivo distinta a la del TimeStamp
+		if rules.filenamePattern != nil && upload.filename != "" {
+			if filenameDate, err := rules.filenameDate(upload.filename); err == nil && !sameDay(timestamp, filenameDate) {
+				mismatchCount++
+				if !timestamp.Before(recentFrom) {
+					recentMismatches++
+				}
+				if len(mismatches) < maxReportedHistoryItems {
+					mismatches = append(mismatches, map[string]interface{}{
+						"row":           upload.row,
+						"timestamp":     timestampStr,
+						"filename":      upload.filename,
+						"filename_date": filenameDate.Format("2006-01-02"),
+					})
+				}
+			}
+		}
+	}
+
+	// Días esperados según el calendario (hoy no cuenta: la carga puede llegar más tarde)
+	businessDays := make(map[time.Weekday]bool)
+	for _, weekday := range history.BusinessDays {
+		businessDays[weekday] = true
+	}
+	holidays := make(map[string]bool)
+	for _, holiday := range history.Holidays {
+		holidays[strings.TrimSpace(holiday)] = true
+	}
+
+	expectedDays, presentDays := 0, 0
+	var gaps []map[string]interface{}
+	var gapStart, gapEnd string
+	gapDays := 0
+	closeGap := func() {
+		if gapDays > 0 {
+			gaps = append(gaps, map[string]interface{}{"from": gapStart, "to": gapEnd, "days": gapDays})
+			gapDays = 0
+		}
+	}
+	for day := from; day.Before(today); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		if (len(businessDays) > 0 && !businessDays[day.Weekday()]) || holidays[key] {
+			continue
+		}
+		expectedDays++
+		if len(uploadsByDay[key]) > 0 {
+			presentDays++
+			closeGap()
+			continue
+		}
+		if gapDays == 0 {
+			gapStart = key
+		}
+		gapEnd = key
+		gapDays++
+	}
+	closeGap()
+
+	completeness := 100.0
+	if expectedDays > 0 {
+		completeness = float64(presentDays) / float64(expectedDays) * 100
+	}
+	missingDays := expectedDays - presentDays
+
+	report.metadata["history_days"] = history.Days
+	report.metadata["daily_completeness_percent"] = roundMetric(completeness)
+	report.metadata["expected_days"] = expectedDays
+	report.metadata["missing_days"] = missingDays
+	report.metadata["unparsable_rows"] = unparsable
+
+	// Huecos más recientes primero
+	sort.SliceStable(gaps, func(i, j int) bool { return gaps[i]["from"].(string) > gaps[j]["from"].(string) })
+	if len(gaps) > maxReportedHistoryItems {
+		gaps = gaps[:maxReportedHistoryItems]
+	}
+	report.metadata["gaps"] = gaps
+
+	if missingDays > 0 {
+		problem := fmt.Sprintf("%d día(s) sin carga en los últimos %d (%.1f%% completo)", missingDays, history.Days, completeness)
+		switch {
+		case history.CompletenessErrorPercent > 0 && completeness < float64(history.CompletenessErrorPercent):
+			report.raise("error", problem)
+		case history.CompletenessWarningPercent > 0 && completeness < float64(history.CompletenessWarningPercent):
+			report.raise("warning", problem)
+		}
+	}
+
+	// Cargas duplicadas para la misma fecha
+	days := make([]string, 0, len(uploadsByDay))
+	for day := range uploadsByDay {
+		days = append(days, day)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(days)))
+
+	var duplicates []map[string]interface{}
+	duplicateDays, recentDuplicateDays := 0, 0
+	recentKey := recentFrom.Format("2006-01-02")
+	for _, day := range days {
+		uploads := uploadsByDay[day]
+		if len(uploads) < 2 {
+			continue
+		}
+		duplicateDays++
+		if day >= recentKey {
+			recentDuplicateDays++
+		}
+		if len(duplicates) >= maxReportedHistoryItems {
+			continue
+		}
+		rowNumbers := make([]int, 0, len(uploads))
+		for _, upload := range uploads {
+			rowNumbers = append(rowNumbers, upload.row)
+		}
+		duplicates = append(duplicates, map[string]interface{}{"date": day, "uploads": len(uploads), "rows": rowNumbers})
+	}
+	report.metadata["duplicate_dates"] = duplicates
+	report.metadata["duplicate_days"] = duplicateDays
+	if recentDuplicateDays > 0 {
+		report.raise("warning", fmt.Sprintf("cargas duplicadas el %s", duplicates[0]["date"]))
+	}
+
+	report.metadata["date_mismatches"] = mismatches
+	report.metadata["date_mismatch_count"] = mismatchCount
+	if recentMismatches > 0 {
+		report.raise("warning", fmt.Sprintf("%d carga(s) reciente(s) con fecha de archivo distinta al TimeStamp", recentMismatches))
+	}
+
+	// Cambio de horario de carga: última carga contra la mediana de las anteriores
+	if history.TimeShiftMinutes > 0 {
+		evaluateUploadTimeShift(&report, uploadsByDay, days, history.TimeShiftMinutes)
+	}
+
+	return report
+}
+
+// evaluateUploadTimeShift compara el horario de la última carga con el habitual
+// Solo considera cargas con hora (un TimeStamp sin hora no permite evaluar el horario)
+func evaluateUploadTimeShift(report *sheetHistoryReport, uploadsByDay map[string][]sheetUpload, daysDesc []string, thresholdMinutes int) {
+	var minutesOfDay []int
+	for _, day := range daysDesc {
+		first := uploadsByDay[day][0]
+		for _, upload := range uploadsByDay[day][1:] {
+			if upload.timestamp.Before(first.timestamp) {
+				first = upload
+			}
+		}
+		minutes := first.timestamp.Hour()*60 + first.timestamp.Minute()
+		if minutes == 0 && first.timestamp.Second() == 0 {
+			continue
+		}
+		minutesOfDay = append(minutesOfDay, minutes)
+	}
+	if len(minutesOfDay) <= minTimeShiftSamples {
+		return
+	}
+
+	last := minutesOfDay[0]
+	previous := append([]int(nil), minutesOfDay[1:]...)
+	sort.Ints(previous)
+	usual := previous[len(previous)/2]
+
+	report.metadata["upload_time_last"] = formatMinutesOfDay(last)
+	report.metadata["upload_time_usual"] = formatMinutesOfDay(usual)
+
+	shift := last - usual
+	if shift < 0 {
+		shift = -shift
+	}
+	report.metadata["upload_time_shift_minutes"] = shift
+	if shift > thresholdMinutes {
+		report.raise("warning", fmt.Sprintf("la última carga fue a las %s (habitual %s)",
+			formatMinutesOfDay(last), formatMinutesOfDay(usual)))
+	}
+}
+
+// formatMinutesOfDay formatea minutos desde la medianoche como HH:MM
+func formatMinutesOfDay(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// applySheetHistory agrega el análisis del historial al check (el peor estado prevalece)
+func applySheetHistory(check *models.Check, report sheetHistoryReport) {
+	for key, value := range report.metadata {
+		check.Metadata[key] = value
+	}
+	if len(report.problems) == 0 {
+		return
+	}
+	check.Metadata["history_problems"] = report.problems
+	if report.status == "error" || check.Status == "ok" {
+		check.Status = report.status
+	}
+	check.Message = fmt.Sprintf("%s. Historial: %s", check.Message, strings.Join(report.problems, "; "))
+}
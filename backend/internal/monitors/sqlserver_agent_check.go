@@ -0,0 +1,284 @@
+package monitors
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/saltacompra/monitor/internal/models"
+)
+
+// SQLServerAgentCheckConfig contiene la configuración para el check de jobs del SQL Server Agent
+type SQLServerAgentCheckConfig struct {
+	Connection            SQLServerConnection
+	CheckID               string
+	CheckName             string
+	LongRunningFactor     float64 // Un job en ejecución es largo si supera factor × su duración promedio
+	MinLongRunningMinutes int     // Duración mínima para considerar un job como largo
+	ScheduleGraceMinutes  int     // Tolerancia tras la próxima ejecución programada antes de reportarla como omitida
+	TimeoutSeconds        int
+}
+
+// agentJobsQuery obtiene por job: último resultado, duración promedio de las últimas
+// ejecuciones exitosas, ejecución en curso y próxima ejecución programada
+// Las fechas de msdb son hora local del servidor; se comparan contra su GETDATE()
+const agentJobsQuery = `
+	SELECT
+		j.name,
+		j.enabled,
+		last_run.run_status,
+		last_run.run_date,
+		last_run.run_time,
+		last_run.message,
+		avg_run.avg_seconds,
+		running.start_execution_date,
+		sched.schedules,
+		sched.next_run,
+		GETDATE()
+	FROM msdb.dbo.sysjobs j
+	OUTER APPLY (
+		SELECT TOP 1 h.run_status, h.run_date, h.run_time, h.message
+		FROM msdb.dbo.sysjobhistory h
+		WHERE h.job_id = j.job_id AND h.step_id = 0
+		ORDER BY h.instance_id DESC
+	) last_run
+	OUTER APPLY (
+		SELECT AVG(CAST(d.run_duration / 10000 * 3600 + d.run_duration / 100 % 100 * 60 + d.run_duration % 100 AS float)) AS avg_seconds
+		FROM (
+			SELECT TOP 10 h.run_duration
+			FROM msdb.dbo.sysjobhistory h
+			WHERE h.job_id = j.job_id AND h.step_id = 0 AND h.run_status = 1
+			ORDER BY h.instance_id DESC
+		) d
+	) avg_run
+	OUTER APPLY (
+		SELECT TOP 1 a.start_execution_date
+		FROM msdb.dbo.sysjobactivity a
+		WHERE a.job_id = j.job_id
+			AND a.session_id = (SELECT MAX(session_id) FROM msdb.dbo.syssessions)
+			AND a.start_execution_date IS NOT NULL
+			AND a.stop_execution_date IS NULL
+	) running
+	OUTER APPLY (
+		SELECT
+			COUNT(*) AS schedules,
+			MIN(CASE WHEN js.next_run_date > 0 THEN CAST(js.next_run_date AS bigint) * 1000000 + js.next_run_time END) AS next_run
+		FROM msdb.dbo.sysjobschedules js
+		JOIN msdb.dbo.sysschedules s ON s.schedule_id = js.schedule_id
+		WHERE js.job_id = j.job_id AND s.enabled = 1
+	) sched
+	ORDER BY j.name
+`
+
+// agentJobRow fila del resultado de agentJobsQuery
+type agentJobRow struct {
+	name       string
+	enabled    bool
+	runStatus  sql.NullInt64
+	runDate    sql.NullInt64
+	runTime    sql.NullInt64
+	message    sql.NullString
+	avgSeconds sql.NullFloat64
+	startedAt  sql.NullTime
+	schedules  int64
+	nextRun    sql.NullInt64
+	serverNow  time.Time
+}
+
+// agentRunStatusNames nombres de run_status de sysjobhistory
+var agentRunStatusNames = map[int64]string{
+	0: "failed",
+	1: "succeeded",
+	2: "retry",
+	3: "canceled",
+	4: "in_progress",
+}
+
+// CheckSQLServerAgentJobs verifica los jobs del SQL Server Agent: último resultado fallido,
+// ejecuciones más largas que lo habitual y jobs habilitados que no corrieron según su programación
+func CheckSQLServerAgentJobs(config SQLServerAgentCheckConfig) models.Check {
+	check := models.Check{
+		ID:        config.CheckID,
+		Type:      "mssql-agent",
+		Name:      config.CheckName,
+		LastCheck: time.Now(),
+		Metadata:  make(map[string]interface{}),
+	}
+
+	factor := config.LongRunningFactor
+	if factor <= 0 {
+		factor = 2
+	}
+	minLongRunning := time.Duration(config.MinLongRunningMinutes) * time.Minute
+	grace := time.Duration(config.ScheduleGraceMinutes) * time.Minute
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(config.TimeoutSeconds))
+	defer cancel()
+
+	start := time.Now()
+	db, err := openSQLServer(ctx, config.Connection)
+	if err != nil {
+		check.ResponseTime = time.Since(start).Milliseconds()
+		check.Status = "error"
+		check.Message = err.Error()
+		check.Metadata["error_type"] = "connection_failed"
+		return check
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, agentJobsQuery)
+	if err != nil {
+		check.ResponseTime = time.Since(start).Milliseconds()
+		check.Status = "error"
+		check.Message = "Error al consultar jobs del SQL Server Agent: " + err.Error()
+		check.Metadata["error_type"] = "query_failed"
+		return check
+	}
+	defer rows.Close()
+
+	var failedJobs, canceledJobs, longRunningJobs, missedJobs []map[string]interface{}
+	var failedNames, longRunningNames, missedNames []string
+	totalJobs, enabledJobs, runningJobs := 0, 0, 0
+
+	for rows.Next() {
+		var job agentJobRow
+		if err := rows.Scan(&job.name, &job.enabled, &job.runStatus, &job.runDate, &job.runTime, &job.message,
+			&job.avgSeconds, &job.startedAt, &job.schedules, &job.nextRun, &job.serverNow); err != nil {
+			check.Status = "error"
+			check.Message = "Error al leer jobs del SQL Server Agent: " + err.Error()
+			return check
+		}
+
+		totalJobs++
+		if job.enabled {
+			enabledJobs++
+		}
+
+		var lastRun time.Time
+		if job.runDate.Valid && job.runDate.Int64 > 0 {
+			lastRun = agentDateTime(job.runDate.Int64, job.runTime.Int64)
+		}
+		entry := map[string]interface{}{
+			"name":     job.name,
+			"last_run": formatAgentTime(lastRun),
+		}
+
+		// Job en ejecución: comparar contra su duración habitual
+		// Se evalúa aunque el job esté deshabilitado (deshabilitarlo no detiene la ejecución en curso)
+		if job.startedAt.Valid {
+			runningJobs++
+			elapsed := job.serverNow.Sub(job.startedAt.Time)
+			if job.avgSeconds.Valid && job.avgSeconds.Float64 > 0 {
+				usual := time.Duration(job.avgSeconds.Float64 * factor * float64(time.Second))
+				if elapsed > usual && elapsed >= minLongRunning {
+					entry["running_minutes"] = roundMetric(elapsed.Minutes())
+					entry["average_minutes"] = roundMetric(job.avgSeconds.Float64 / 60)
+					longRunningJobs = append(longRunningJobs, entry)
+					longRunningNames = append(longRunningNames, job.name)
+				}
+			}
+			continue
+		}
+
+		// Los jobs deshabilitados no se evalúan por su último resultado ni su programación
+		if !job.enabled {
+			continue
+		}
+
+		// Último resultado
+		if job.runStatus.Valid {
+			entry["last_status"] = agentRunStatusNames[job.runStatus.Int64]
+			switch job.runStatus.Int64 {
+			case 0:
+				entry["message"] = truncateMessage(job.message.String)
+				failedJobs = append(failedJobs, entry)
+				failedNames = append(failedNames, job.name)
+			case 3:
+				canceledJobs = append(canceledJobs, entry)
+			}
+		}
+
+		// Próxima ejecución programada ya vencida (el Agent no la ejecutó)
+		if job.schedules > 0 && job.nextRun.Valid {
+			nextRun := agentDateTime(job.nextRun.Int64/1000000, job.nextRun.Int64%1000000)
+			if job.serverNow.Sub(nextRun) > grace {
+				missed := map[string]interface{}{
+					"name":          job.name,
+					"last_run":      formatAgentTime(lastRun),
+					"scheduled_run": formatAgentTime(nextRun),
+					"overdue_hours": roundMetric(job.serverNow.Sub(nextRun).Hours()),
+				}
+				missedJobs = append(missedJobs, missed)
+				missedNames = append(missedNames, job.name)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		check.Status = "error"
+		check.Message = "Error al leer jobs del SQL Server Agent: " + err.Error()
+		return check
+	}
+
+	check.ResponseTime = time.Since(start).Milliseconds()
+	check.Metadata["jobs_total"] = totalJobs
+	check.Metadata["jobs_enabled"] = enabledJobs
+	check.Metadata["jobs_running"] = runningJobs
+	check.Metadata["failed_count"] = len(failedJobs)
+	check.Metadata["long_running_count"] = len(longRunningJobs)
+	check.Metadata["missed_schedule_count"] = len(missedJobs)
+	check.Metadata["failed_jobs"] = failedJobs
+	check.Metadata["canceled_jobs"] = canceledJobs
+	check.Metadata["long_running_jobs"] = longRunningJobs
+	check.Metadata["missed_schedule_jobs"] = missedJobs
+
+	var problems []string
+	if len(failedNames) > 0 {
+		problems = append(problems, "fallaron: "+strings.Join(failedNames, ", "))
+	}
+	if len(longRunningNames) > 0 {
+		problems = append(problems, "más lentos que lo habitual: "+strings.Join(longRunningNames, ", "))
+	}
+	if len(missedNames) > 0 {
+		problems = append(problems, "sin ejecutar según su programación: "+strings.Join(missedNames, ", "))
+	}
+
+	switch {
+	case len(failedJobs) > 0:
+		check.Status = "error"
+		check.Message = "Jobs del SQL Server Agent " + strings.Join(problems, "; ")
+	case len(problems) > 0:
+		check.Status = "warning"
+		check.Message = "Jobs del SQL Server Agent " + strings.Join(problems, "; ")
+	default:
+		check.Status = "ok"
+		check.Message = fmt.Sprintf("%d jobs habilitados sin fallas (%d en ejecución)", enabledJobs, runningJobs)
+	}
+
+	return check
+}
+
+// agentDateTime convierte fecha (YYYYMMDD) y hora (HHMMSS) enteras de msdb a time.Time
+// Se usa UTC como zona nominal, igual que el driver para los datetime del servidor
+func agentDateTime(date int64, clock int64) time.Time {
+	return time.Date(int(date/10000), time.Month(date/100%100), int(date%100),
+		int(clock/10000), int(clock/100%100), int(clock%100), 0, time.UTC)
+}
+
+// formatAgentTime formatea una fecha de msdb (vacío si nunca ocurrió)
+func formatAgentTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// truncateMessage limita un mensaje de error para metadata y mensajes de checks
+func truncateMessage(message string) string {
+	runes := []rune(strings.TrimSpace(message))
+	if len(runes) > 300 {
+		return string(runes[:300]) + "…"
+	}
+	return string(runes)
+}
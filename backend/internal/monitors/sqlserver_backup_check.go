@@ -0,0 +1,235 @@
+package monitors
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/saltacompra/monitor/internal/models"
+)
+
+// SQLServerBackupCheckConfig contiene la configuración para el check de antigüedad de backups
+type SQLServerBackupCheckConfig struct {
+	Connection       SQLServerConnection
+	CheckID          string
+	CheckName        string
+	FullAgeHours     MetricThreshold // Horas desde el último backup completo
+	DiffAgeHours     MetricThreshold // Horas desde el último backup diferencial o completo
+	LogAgeMinutes    MetricThreshold // Minutos desde el último backup de log (recovery FULL/BULK_LOGGED)
+	ExcludeDatabases []string        // Bases a ignorar (tempdb siempre se ignora; model, por defecto desde la configuración)
+	TimeoutSeconds   int
+}
+
+// backupFreshnessQuery obtiene el último backup completo, diferencial y de log de cada base
+// Las fechas son hora local del servidor; se comparan contra su GETDATE()
+// Se ignoran los backups copy-only (no forman parte de la cadena de restauración) y los
+// registrados con otro server_name (msdb restaurado o historial importado de otra instancia)
+const backupFreshnessQuery = `
+	SELECT
+		d.name,
+		d.recovery_model_desc,
+		d.state_desc,
+		MAX(CASE WHEN b.type = 'D' THEN b.backup_finish_date END) AS last_full,
+		MAX(CASE WHEN b.type = 'I' THEN b.backup_finish_date END) AS last_diff,
+		MAX(CASE WHEN b.type = 'L' THEN b.backup_finish_date END) AS last_log,
+		GETDATE()
+	FROM sys.databases d
+	LEFT JOIN msdb.dbo.backupset b ON b.database_name = d.name
+		AND b.is_copy_only = 0
+		AND b.server_name = @@SERVERNAME
+	WHERE d.name <> 'tempdb' AND d.source_database_id IS NULL
+	GROUP BY d.name, d.recovery_model_desc, d.state_desc
+	ORDER BY d.name
+`
+
+// CheckSQLServerBackups verifica la antigüedad de los backups completos, diferenciales y de log
+// de cada base de la instancia, y marca bases en recovery FULL sin backup de log reciente
+func CheckSQLServerBackups(config SQLServerBackupCheckConfig) models.Check {
+	check := models.Check{
+		ID:        config.CheckID,
+		Type:      "mssql-backup",
+		Name:      config.CheckName,
+		LastCheck: time.Now(),
+		Metadata:  make(map[string]interface{}),
+	}
+
+	excluded := make(map[string]bool)
+	for _, name := range config.ExcludeDatabases {
+		excluded[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(config.TimeoutSeconds))
+	defer cancel()
+
+	start := time.Now()
+	db, err := openSQLServer(ctx, config.Connection)
+	if err != nil {
+		check.ResponseTime = time.Since(start).Milliseconds()
+		check.Status = "error"
+		check.Message = err.Error()
+		check.Metadata["error_type"] = "connection_failed"
+		return check
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, backupFreshnessQuery)
+	if err != nil {
+		check.ResponseTime = time.Since(start).Milliseconds()
+		check.Status = "error"
+		check.Message = "Error al consultar historial de backups: " + err.Error()
+		check.Metadata["error_type"] = "query_failed"
+		return check
+	}
+	defer rows.Close()
+
+	var databases []map[string]interface{}
+	var errorNames, warningNames, missingLogNames []string
+	skipped := []map[string]interface{}{}
+
+	for rows.Next() {
+		var name, recoveryModel, state string
+		var lastFull, lastDiff, lastLog sql.NullTime
+		var serverNow time.Time
+		if err := rows.Scan(&name, &recoveryModel, &state, &lastFull, &lastDiff, &lastLog, &serverNow); err != nil {
+			check.ResponseTime = time.Since(start).Milliseconds()
+			check.Status = "error"
+			check.Message = "Error al leer historial de backups: " + err.Error()
+			return check
+		}
+		if excluded[strings.ToLower(name)] {
+			continue
+		}
+		// Bases OFFLINE, RESTORING, RECOVERING, etc. no admiten backups: se informan sin evaluarlas
+		if state != "ONLINE" {
+			skipped = append(skipped, map[string]interface{}{"name": name, "state": state})
+			continue
+		}
+
+		entry, status, problems := evaluateDatabaseBackups(config, name, recoveryModel, state, lastFull, lastDiff, lastLog, serverNow)
+		databases = append(databases, entry)
+
+		label := fmt.Sprintf("%s (%s)", name, strings.Join(problems, ", "))
+		switch status {
+		case "error":
+			errorNames = append(errorNames, label)
+		case "warning":
+			warningNames = append(warningNames, label)
+		}
+		if entry["missing_log_backup"] == true {
+			missingLogNames = append(missingLogNames, name)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		check.ResponseTime = time.Since(start).Milliseconds()
+		check.Status = "error"
+		check.Message = "Error al leer historial de backups: " + err.Error()
+		return check
+	}
+
+	// Bases con problemas primero
+	statusOrder := map[string]int{"error": 0, "warning": 1, "ok": 2}
+	sort.SliceStable(databases, func(i, j int) bool {
+		return statusOrder[databases[i]["status"].(string)] < statusOrder[databases[j]["status"].(string)]
+	})
+
+	check.ResponseTime = time.Since(start).Milliseconds()
+	check.Metadata["databases"] = databases
+	check.Metadata["databases_checked"] = len(databases)
+	check.Metadata["databases_error"] = len(errorNames)
+	check.Metadata["databases_warning"] = len(warningNames)
+	check.Metadata["missing_log_backup"] = missingLogNames
+	check.Metadata["skipped_databases"] = skipped
+
+	switch {
+	case len(errorNames) > 0:
+		check.Status = "error"
+		check.Message = "Backups vencidos: " + strings.Join(append(errorNames, warningNames...), "; ")
+	case len(warningNames) > 0:
+		check.Status = "warning"
+		check.Message = "Backups próximos a vencer: " + strings.Join(warningNames, "; ")
+	default:
+		check.Status = "ok"
+		check.Message = fmt.Sprintf("Backups al día en %d bases", len(databases))
+	}
+
+	return check
+}
+
+// evaluateDatabaseBackups evalúa los backups de una base y retorna su detalle, estado y problemas
+func evaluateDatabaseBackups(config SQLServerBackupCheckConfig, name, recoveryModel, state string,
+	lastFull, lastDiff, lastLog sql.NullTime, serverNow time.Time) (map[string]interface{}, string, []string) {
+
+	entry := map[string]interface{}{
+		"name":           name,
+		"recovery_model": recoveryModel,
+		"state":          state,
+		"last_full":      formatBackupTime(lastFull),
+		"last_diff":      formatBackupTime(lastDiff),
+		"last_log":       formatBackupTime(lastLog),
+	}
+
+	status := "ok"
+	var problems []string
+	raise := func(newStatus string, problem string) {
+		if newStatus == "ok" {
+			return
+		}
+		problems = append(problems, problem)
+		if newStatus == "error" || status == "ok" {
+			status = newStatus
+		}
+	}
+
+	// Backup completo
+	if !lastFull.Valid {
+		raise("error", "sin backup completo")
+	} else {
+		age := serverNow.Sub(lastFull.Time).Hours()
+		entry["full_age_hours"] = roundMetric(age)
+		raise(config.FullAgeHours.evaluate(age), fmt.Sprintf("completo hace %.0fh", age))
+	}
+
+	// Diferencial: un backup completo posterior también cubre el período
+	if lastFull.Valid {
+		latest := lastFull.Time
+		if lastDiff.Valid && lastDiff.Time.After(latest) {
+			latest = lastDiff.Time
+		}
+		age := serverNow.Sub(latest).Hours()
+		entry["diff_age_hours"] = roundMetric(age)
+		raise(config.DiffAgeHours.evaluate(age), fmt.Sprintf("diferencial hace %.0fh", age))
+	}
+
+	// Log: solo aplica a bases con recovery FULL o BULK_LOGGED
+	if recoveryModel == "FULL" || recoveryModel == "BULK_LOGGED" {
+		if !lastLog.Valid {
+			entry["missing_log_backup"] = true
+			raise("error", "recovery "+recoveryModel+" sin backup de log")
+		} else {
+			age := serverNow.Sub(lastLog.Time).Minutes()
+			entry["log_age_minutes"] = roundMetric(age)
+			logStatus := config.LogAgeMinutes.evaluate(age)
+			if logStatus != "ok" {
+				entry["missing_log_backup"] = true
+			}
+			raise(logStatus, fmt.Sprintf("log hace %.0f min", age))
+		}
+	}
+
+	entry["status"] = status
+	if len(problems) > 0 {
+		entry["problems"] = problems
+	}
+	return entry, status, problems
+}
+
+// formatBackupTime formatea la fecha de un backup (vacío si nunca se realizó)
+func formatBackupTime(t sql.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,438 @@
+package monitors
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/saltacompra/monitor/internal/models"
+)
+
+// SQLServerHealthThresholds umbrales por métrica del check de salud de SQL Server
+type SQLServerHealthThresholds struct {
+	BlockedSessions     MetricThreshold // Sesiones esperando a otra sesión
+	LongestBlockSeconds MetricThreshold // Espera más larga por bloqueo
+	Deadlocks           MetricThreshold // Deadlocks en la ventana configurada
+	LogUsedPercent      MetricThreshold // % de log usado de la base más cargada
+	VolumeFreePercent   MetricThreshold // % libre del volumen más lleno con archivos de datos/log (Below)
+	FileMaxSizePercent  MetricThreshold // % usado del archivo más cercano a su max_size
+	DatabasesNotOnline  MetricThreshold // Bases en RECOVERING, RESTORING, OFFLINE, etc.
+	CPUPercent          MetricThreshold // CPU usada por SQL Server (promedio de los últimos minutos)
+	PageLifeExpectancy  MetricThreshold // Segundos de vida de páginas en el buffer pool (Below)
+	MemoryGrantsPending MetricThreshold // Consultas esperando memoria
+}
+
+// SQLServerHealthCheckConfig contiene la configuración del check de salud de la instancia SQL Server
+type SQLServerHealthCheckConfig struct {
+	Connection            SQLServerConnection
+	CheckID               string
+	CheckName             string
+	DeadlockWindowMinutes int // Ventana para contar deadlocks del system_health
+	TimeoutSeconds        int
+	Thresholds            SQLServerHealthThresholds
+}
+
+// suspectDatabaseStates estados de base que siempre son error
+var suspectDatabaseStates = map[string]bool{
+	"SUSPECT":          true,
+	"RECOVERY_PENDING": true,
+	"EMERGENCY":        true,
+}
+
+// maxReportedOffenders cantidad máxima de elementos listados por métrica en metadata
+const maxReportedOffenders = 5
+
+// CheckSQLServerHealth inspecciona bloqueos, deadlocks, espacio de log y archivos, estado de las bases
+// y presión de CPU/memoria de la instancia, evaluando cada métrica contra sus propios umbrales
+func CheckSQLServerHealth(config SQLServerHealthCheckConfig) models.Check {
+	check := models.Check{
+		ID:        config.CheckID,
+		Type:      "mssql-health",
+		Name:      config.CheckName,
+		LastCheck: time.Now(),
+		Metadata:  make(map[string]interface{}),
+	}
+
+	deadlockWindow := config.DeadlockWindowMinutes
+	if deadlockWindow == 0 {
+		deadlockWindow = 60
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(config.TimeoutSeconds))
+	defer cancel()
+
+	start := time.Now()
+	db, err := openSQLServer(ctx, config.Connection)
+	if err != nil {
+		check.ResponseTime = time.Since(start).Milliseconds()
+		check.Status = "error"
+		check.Message = err.Error()
+		check.Metadata["error_type"] = "connection_failed"
+		return check
+	}
+	defer db.Close()
+
+	report := newHealthReport()
+	thresholds := config.Thresholds
+
+	// Cadenas de bloqueo
+	if err := recordSQLServerBlocking(ctx, db, &check, report, thresholds); err != nil {
+		report.fail("blocked_sessions", err)
+	}
+
+	// Deadlocks registrados por la sesión system_health
+	var deadlocks float64
+	err = db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM (
+			SELECT CAST(t.target_data AS XML) AS target_data
+			FROM sys.dm_xe_session_targets t
+			JOIN sys.dm_xe_sessions s ON s.address = t.event_session_address
+			WHERE s.name = 'system_health' AND t.target_name = 'ring_buffer'
+		) x
+		CROSS APPLY x.target_data.nodes('RingBufferTarget/event[@name="xml_deadlock_report"]') n(e)
+		WHERE n.e.value('@timestamp', 'datetime2') > DATEADD(minute, -@window, SYSUTCDATETIME())`,
+		sql.Named("window", deadlockWindow)).Scan(&deadlocks)
+	if err != nil {
+		report.fail("deadlocks", err)
+	} else {
+		check.Metadata["deadlock_window_minutes"] = deadlockWindow
+		report.record("deadlocks", deadlocks, thresholds.Deadlocks)
+	}
+
+	// Uso del log de transacciones por base
+	if err := recordSQLServerLogSpace(ctx, db, &check, report, thresholds.LogUsedPercent); err != nil {
+		report.fail("log_used_percent", err)
+	}
+
+	// Espacio libre en volúmenes y archivos cerca de su max_size
+	if err := recordSQLServerFileSpace(ctx, db, &check, report, thresholds); err != nil {
+		report.fail("volume_free_percent", err)
+	}
+
+	// Estado de las bases
+	if err := recordSQLServerDatabaseStates(ctx, db, &check, report, thresholds.DatabasesNotOnline); err != nil {
+		report.fail("databases_not_online", err)
+	}
+
+	// Presión de CPU y memoria
+	if err := recordSQLServerPressure(ctx, db, &check, report, thresholds); err != nil {
+		report.fail("cpu_percent", err)
+	}
+
+	check.ResponseTime = time.Since(start).Milliseconds()
+	report.applyTo(&check, fmt.Sprintf("Instancia SQL Server saludable (%dms)", check.ResponseTime))
+
+	return check
+}
+
+// recordSQLServerBlocking registra sesiones bloqueadas y lista los bloqueadores raíz con más sesiones en espera
+func recordSQLServerBlocking(ctx context.Context, db *sql.DB, check *models.Check, report *healthReport, thresholds SQLServerHealthThresholds) error {
+	rows, err := db.QueryContext(ctx, `
+		SELECT r.session_id, r.blocking_session_id, r.wait_time, COALESCE(DB_NAME(r.database_id), ''),
+			COALESCE(bs.login_name, ''), COALESCE(bs.host_name, ''), COALESCE(bs.program_name, '')
+		FROM sys.dm_exec_requests r
+		LEFT JOIN sys.dm_exec_sessions bs ON bs.session_id = r.blocking_session_id
+		WHERE r.blocking_session_id <> 0`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	blockedBy := make(map[int64]int64)
+	waitMs := make(map[int64]int64)
+	blockers := make(map[int64]map[string]interface{})
+	var longestWait int64
+
+	for rows.Next() {
+		var session, blocker, wait int64
+		var database, login, host, program string
+		if err := rows.Scan(&session, &blocker, &wait, &database, &login, &host, &program); err != nil {
+			return err
+		}
+		blockedBy[session] = blocker
+		waitMs[session] = wait
+		if wait > longestWait {
+			longestWait = wait
+		}
+		if _, exists := blockers[blocker]; !exists {
+			blockers[blocker] = map[string]interface{}{
+				"session_id": blocker,
+				"login":      login,
+				"host":       host,
+				"program":    program,
+				"database":   database,
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Agrupar cada sesión bloqueada bajo su bloqueador raíz (el que no está bloqueado)
+	headCounts := make(map[int64]int)
+	headWaits := make(map[int64]int64)
+	for session := range blockedBy {
+		head := blockedBy[session]
+		for i := 0; i < 100; i++ {
+			next, blocked := blockedBy[head]
+			if !blocked || next == session {
+				break
+			}
+			head = next
+		}
+		headCounts[head]++
+		if waitMs[session] > headWaits[head] {
+			headWaits[head] = waitMs[session]
+		}
+	}
+
+	var heads []map[string]interface{}
+	for head, count := range headCounts {
+		entry, exists := blockers[head]
+		if !exists {
+			entry = map[string]interface{}{"session_id": head}
+		}
+		entry["blocked_sessions"] = count
+		entry["longest_wait_seconds"] = roundMetric(float64(headWaits[head]) / 1000)
+		heads = append(heads, entry)
+	}
+	sort.Slice(heads, func(i, j int) bool {
+		return heads[i]["blocked_sessions"].(int) > heads[j]["blocked_sessions"].(int)
+	})
+	if len(heads) > maxReportedOffenders {
+		heads = heads[:maxReportedOffenders]
+	}
+
+	check.Metadata["head_blockers"] = heads
+	report.record("blocked_sessions", float64(len(blockedBy)), thresholds.BlockedSessions)
+	report.record("longest_block_seconds", float64(longestWait)/1000, thresholds.LongestBlockSeconds)
+	return nil
+}
+
+// recordSQLServerLogSpace registra el % de log usado de cada base (contadores de rendimiento)
+func recordSQLServerLogSpace(ctx context.Context, db *sql.DB, check *models.Check, report *healthReport, threshold MetricThreshold) error {
+	rows, err := db.QueryContext(ctx, `
+		SELECT c.instance_name,
+			MAX(CASE WHEN RTRIM(c.counter_name) = 'Log File(s) Size (KB)' THEN c.cntr_value END),
+			MAX(CASE WHEN RTRIM(c.counter_name) = 'Log File(s) Used Size (KB)' THEN c.cntr_value END),
+			COALESCE(MAX(d.log_reuse_wait_desc), '')
+		FROM sys.dm_os_performance_counters c
+		LEFT JOIN sys.databases d ON d.name = RTRIM(c.instance_name)
+		WHERE c.object_name LIKE '%:Databases%'
+			AND RTRIM(c.counter_name) IN ('Log File(s) Size (KB)', 'Log File(s) Used Size (KB)')
+			AND RTRIM(c.instance_name) NOT IN ('_Total', 'mssqlsystemresource')
+		GROUP BY c.instance_name`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var databases []map[string]interface{}
+	var worst float64
+	for rows.Next() {
+		var name, reuseWait string
+		var sizeKB, usedKB sql.NullInt64
+		if err := rows.Scan(&name, &sizeKB, &usedKB, &reuseWait); err != nil {
+			return err
+		}
+		if !sizeKB.Valid || sizeKB.Int64 == 0 {
+			continue
+		}
+		percent := float64(usedKB.Int64) / float64(sizeKB.Int64) * 100
+		if percent > worst {
+			worst = percent
+		}
+		databases = append(databases, map[string]interface{}{
+			"database":       strings.TrimRight(name, " "),
+			"log_size_mb":    roundMetric(float64(sizeKB.Int64) / 1024),
+			"log_used_pct":   roundMetric(percent),
+			"log_reuse_wait": reuseWait,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	sort.Slice(databases, func(i, j int) bool {
+		return databases[i]["log_used_pct"].(float64) > databases[j]["log_used_pct"].(float64)
+	})
+	if len(databases) > maxReportedOffenders {
+		databases = databases[:maxReportedOffenders]
+	}
+
+	check.Metadata["log_space"] = databases
+	report.record("log_used_percent", worst, threshold)
+	return nil
+}
+
+// recordSQLServerFileSpace registra el espacio libre de los volúmenes con archivos de datos/log
+// y los archivos más cercanos a su tamaño máximo
+func recordSQLServerFileSpace(ctx context.Context, db *sql.DB, check *models.Check, report *healthReport, thresholds SQLServerHealthThresholds) error {
+	rows, err := db.QueryContext(ctx, `
+		SELECT DISTINCT vs.volume_mount_point, vs.total_bytes, vs.available_bytes
+		FROM sys.master_files f
+		CROSS APPLY sys.dm_os_volume_stats(f.database_id, f.file_id) vs`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var volumes []map[string]interface{}
+	worstFree := 100.0
+	for rows.Next() {
+		var mountPoint string
+		var total, available int64
+		if err := rows.Scan(&mountPoint, &total, &available); err != nil {
+			return err
+		}
+		if total == 0 {
+			continue
+		}
+		free := float64(available) / float64(total) * 100
+		if free < worstFree {
+			worstFree = free
+		}
+		volumes = append(volumes, map[string]interface{}{
+			"volume":       mountPoint,
+			"total_gb":     roundMetric(float64(total) / 1024 / 1024 / 1024),
+			"available_gb": roundMetric(float64(available) / 1024 / 1024 / 1024),
+			"free_percent": roundMetric(free),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	sort.Slice(volumes, func(i, j int) bool {
+		return volumes[i]["free_percent"].(float64) < volumes[j]["free_percent"].(float64)
+	})
+	check.Metadata["volumes"] = volumes
+	report.record("volume_free_percent", worstFree, thresholds.VolumeFreePercent)
+
+	// Archivos con tamaño máximo definido (size y max_size están en páginas de 8 KB)
+	fileRows, err := db.QueryContext(ctx, `
+		SELECT TOP (@top) DB_NAME(database_id), name, type_desc, size * 8 / 1024, max_size * 8 / 1024,
+			CAST(size AS float) * 100 / max_size
+		FROM sys.master_files
+		WHERE max_size > 0
+		ORDER BY CAST(size AS float) / max_size DESC`, sql.Named("top", maxReportedOffenders))
+	if err != nil {
+		return err
+	}
+	defer fileRows.Close()
+
+	var files []map[string]interface{}
+	var worstFile float64
+	for fileRows.Next() {
+		var database, name, fileType string
+		var sizeMB, maxMB int64
+		var percent float64
+		if err := fileRows.Scan(&database, &name, &fileType, &sizeMB, &maxMB, &percent); err != nil {
+			return err
+		}
+		if percent > worstFile {
+			worstFile = percent
+		}
+		files = append(files, map[string]interface{}{
+			"database":     database,
+			"file":         name,
+			"type":         fileType,
+			"size_mb":      sizeMB,
+			"max_size_mb":  maxMB,
+			"used_percent": roundMetric(percent),
+		})
+	}
+	if err := fileRows.Err(); err != nil {
+		return err
+	}
+	check.Metadata["files_near_max_size"] = files
+	report.record("file_max_size_percent", worstFile, thresholds.FileMaxSizePercent)
+	return nil
+}
+
+// recordSQLServerDatabaseStates registra las bases que no están ONLINE
+// SUSPECT, RECOVERY_PENDING y EMERGENCY son siempre error
+func recordSQLServerDatabaseStates(ctx context.Context, db *sql.DB, check *models.Check, report *healthReport, threshold MetricThreshold) error {
+	rows, err := db.QueryContext(ctx, `SELECT name, state_desc FROM sys.databases WHERE state_desc <> 'ONLINE' ORDER BY name`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var databases []map[string]interface{}
+	notOnline, suspect := 0, 0
+	for rows.Next() {
+		var name, state string
+		if err := rows.Scan(&name, &state); err != nil {
+			return err
+		}
+		databases = append(databases, map[string]interface{}{"database": name, "state": state})
+		if suspectDatabaseStates[state] {
+			suspect++
+		} else {
+			notOnline++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	check.Metadata["databases_not_online_list"] = databases
+	report.record("databases_suspect", float64(suspect), MetricThreshold{Error: 1})
+	report.record("databases_not_online", float64(notOnline), threshold)
+	return nil
+}
+
+// recordSQLServerPressure registra CPU de SQL Server (ring buffer del scheduler monitor),
+// tareas en cola, page life expectancy y memory grants pendientes
+func recordSQLServerPressure(ctx context.Context, db *sql.DB, check *models.Check, report *healthReport, thresholds SQLServerHealthThresholds) error {
+	var cpu sql.NullFloat64
+	err := db.QueryRowContext(ctx, `
+		SELECT AVG(CAST(x.cpu AS float))
+		FROM (
+			SELECT TOP 10
+				CAST(record AS XML).value('(./Record/SchedulerMonitorEvent/SystemHealth/ProcessUtilization)[1]', 'int') AS cpu
+			FROM sys.dm_os_ring_buffers
+			WHERE ring_buffer_type = N'RING_BUFFER_SCHEDULER_MONITOR' AND record LIKE N'%<SystemHealth>%'
+			ORDER BY timestamp DESC
+		) x`).Scan(&cpu)
+	if err != nil {
+		return err
+	}
+	if cpu.Valid {
+		report.record("cpu_percent", cpu.Float64, thresholds.CPUPercent)
+	}
+
+	var runnable int64
+	err = db.QueryRowContext(ctx, `SELECT COALESCE(SUM(runnable_tasks_count), 0) FROM sys.dm_os_schedulers WHERE status = 'VISIBLE ONLINE'`).Scan(&runnable)
+	if err != nil {
+		return err
+	}
+	report.info("runnable_tasks", float64(runnable))
+
+	var pageLife, grantsPending sql.NullInt64
+	err = db.QueryRowContext(ctx, `
+		SELECT
+			MAX(CASE WHEN c.object_name LIKE '%Buffer Manager%' AND RTRIM(c.counter_name) = 'Page life expectancy' THEN c.cntr_value END),
+			MAX(CASE WHEN c.object_name LIKE '%Memory Manager%' AND RTRIM(c.counter_name) = 'Memory Grants Pending' THEN c.cntr_value END)
+		FROM sys.dm_os_performance_counters c
+		WHERE RTRIM(c.counter_name) IN ('Page life expectancy', 'Memory Grants Pending')`).Scan(&pageLife, &grantsPending)
+	if err != nil {
+		return err
+	}
+	if pageLife.Valid {
+		report.record("page_life_expectancy", float64(pageLife.Int64), thresholds.PageLifeExpectancy)
+	}
+	if grantsPending.Valid {
+		report.record("memory_grants_pending", float64(grantsPending.Int64), thresholds.MemoryGrantsPending)
+	}
+
+	var memoryState string
+	if err := db.QueryRowContext(ctx, `SELECT system_memory_state_desc FROM sys.dm_os_sys_memory`).Scan(&memoryState); err == nil {
+		check.Metadata["memory_state"] = memoryState
+	}
+	return nil
+}
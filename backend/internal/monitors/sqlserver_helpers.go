@@ -0,0 +1,93 @@
+package monitors
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	mssql "github.com/microsoft/go-mssqldb"
+)
+
+// SQLServerConnection datos de conexión a una instancia SQL Server
+type SQLServerConnection struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Database string
+}
+
+// sqlServerConnString construye el connection string de SQL Server
+func sqlServerConnString(conn SQLServerConnection) string {
+	return fmt.Sprintf("server=%s;user id=%s;password=%s;port=%d;database=%s",
+		conn.Host, conn.User, conn.Password, conn.Port, conn.Database)
+}
+
+// openSQLServer abre una conexión y verifica que responda dentro del timeout
+func openSQLServer(ctx context.Context, conn SQLServerConnection) (*sql.DB, error) {
+	db, err := sql.Open("sqlserver", sqlServerConnString(conn))
+	if err != nil {
+		return nil, fmt.Errorf("error al conectar a SQL Server: %w", err)
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("no se pudo conectar a la base de datos: %w", err)
+	}
+	return db, nil
+}
+
+// scanSQLRows lee todas las filas de un resultado como mapas columna → valor
+func scanSQLRows(rows *sql.Rows) (QueryResult, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return QueryResult{}, err
+	}
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return QueryResult{}, err
+	}
+
+	result := QueryResult{Columns: columns, Rows: []map[string]interface{}{}}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return result, err
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			row[column] = normalizeSQLServerValue(values[i], columnTypes[i].DatabaseTypeName())
+		}
+		result.Rows = append(result.Rows, row)
+	}
+
+	return result, rows.Err()
+}
+
+// normalizeSQLServerValue convierte un valor de SQL Server a un tipo serializable
+// uniqueidentifier llega como 16 bytes con los tres primeros grupos en little-endian:
+// se formatea como GUID (el mismo texto que muestra SQL Server) en lugar de texto binario
+func normalizeSQLServerValue(value interface{}, databaseType string) interface{} {
+	if raw, ok := value.([]byte); ok && databaseType == "UNIQUEIDENTIFIER" {
+		var guid mssql.UniqueIdentifier
+		if err := guid.Scan(raw); err == nil {
+			return guid.String()
+		}
+	}
+	return normalizeSQLValue(value)
+}
+
+// queryTimeout retorna el timeout de una consulta (default 30 segundos)
+func queryTimeout(seconds int) time.Duration {
+	if seconds <= 0 {
+		seconds = 30
+	}
+	return time.Duration(seconds) * time.Second
+}
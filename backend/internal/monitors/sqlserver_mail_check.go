@@ -3,6 +3,7 @@ package monitors
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	_ "github.com/microsoft/go-mssqldb"
@@ -17,8 +18,13 @@ type MailCheckConfig struct {
 	Password                     string
 	Database                     string
 	MaxMinutesWithoutSent        int // Umbral de minutos sin correo 'sent' antes de warning
-	DailyWarningFailedPercent    int // % de fallidos para warning
-	DailyErrorFailedPercent      int // % de fallidos para error
+	DailyWarningFailedPercent    int // % de fallidos para warning (en cualquiera de las ventanas)
+	DailyErrorFailedPercent      int // % de fallidos para error (en cualquiera de las ventanas)
+	Categories                   []MailCategory // Tipos de correo con umbrales propios (opcional)
+	WindowsMinutes               []int // Ventanas móviles en minutos (default 15, 60 y 1440)
+	StuckWarningMinutes          int // Antigüedad del correo pendiente más viejo para warning (0 = deshabilitado)
+	StuckErrorMinutes            int // Antigüedad del correo pendiente más viejo para error (0 = deshabilitado)
+	MinFailureSamples            int // Correos mínimos para evaluar un % de fallidos (con pocos correos un fallo es un % alto)
 }
 
 // CheckMailService verifica el estado del servicio de mails en SQL Server
@@ -32,8 +38,13 @@ func CheckMailService(config MailCheckConfig, checkID string, checkName string)
 	}
 
 	// Construir connection string
-	connString := fmt.Sprintf("server=%s;user id=%s;password=%s;port=%d;database=%s",
-		config.Host, config.User, config.Password, config.Port, config.Database)
+	connString := sqlServerConnString(SQLServerConnection{
+		Host:     config.Host,
+		Port:     config.Port,
+		User:     config.User,
+		Password: config.Password,
+		Database: config.Database,
+	})
 
 	start := time.Now()
 	db, err := sql.Open("sqlserver", connString)
@@ -55,153 +66,188 @@ func CheckMailService(config MailCheckConfig, checkID string, checkName string)
 		return check
 	}
 
-	// Query para obtener todos los correos del día
-	// Intentar con send_request_date primero, si falla usar last_mod_date como fallback
-	query := `
-		SELECT
-			mailitem_id,
-			CASE sent_status
-				WHEN 0 THEN 'unsent'
-				WHEN 1 THEN 'sent'
-				WHEN 3 THEN 'retrying'
-				ELSE 'failed'
-			END as sent_status,
-			send_request_date,
-			sent_date,
-			last_mod_date
-		FROM msdb.dbo.sysmail_mailitems
-		WHERE CAST(send_request_date AS DATE) = CAST(GETDATE() AS DATE)
-		ORDER BY mailitem_id DESC
-	`
-
-	rows, err := db.Query(query)
+	// Ventanas móviles: los umbrales de % fallidos se evalúan en todas; la más amplia se usa para el resumen
+	windows := normalizeMailWindows(config.WindowsMinutes)
+	evaluationWindow := windows[len(windows)-1]
+
+	// Resumen de la cola: último envío, último registro y pendiente más antiguo
+	// Si send_request_date no existe, usar last_mod_date como fallback
+	dateColumn := "m.send_request_date"
+	queue, err := queryMailQueue(db, dateColumn)
 	if err != nil {
-		// Si send_request_date no existe, usar last_mod_date como fallback
-		query = `
-			SELECT
-				mailitem_id,
-				CASE sent_status
-					WHEN 0 THEN 'unsent'
-					WHEN 1 THEN 'sent'
-					WHEN 3 THEN 'retrying'
-					ELSE 'failed'
-				END as sent_status,
-				last_mod_date as send_request_date,
-				sent_date,
-				last_mod_date
-			FROM msdb.dbo.sysmail_mailitems
-			WHERE CAST(last_mod_date AS DATE) = CAST(GETDATE() AS DATE)
-			ORDER BY mailitem_id DESC
-		`
-		rows, err = db.Query(query)
+		dateColumn = "m.last_mod_date"
+		queue, err = queryMailQueue(db, dateColumn)
 		if err != nil {
 			check.Status = "error"
 			check.Message = "Error al consultar sysmail_mailitems: " + err.Error()
 			return check
 		}
 	}
-	defer rows.Close()
-
-	// Variables para análisis
-	var mailitemID int
-	var sentStatus string
-	var sendRequestDate, sentDate, lastModDate *time.Time
-
-	statusCount := make(map[string]int)
-	var lastSentTime, lastCreatedTime *time.Time
-	totalToday := 0
-
-	// Procesar todos los registros del día
-	for rows.Next() {
-		err := rows.Scan(&mailitemID, &sentStatus, &sendRequestDate, &sentDate, &lastModDate)
-		if err != nil {
-			check.Status = "error"
-			check.Message = "Error al leer datos: " + err.Error()
-			return check
-		}
-
-		totalToday++
-		statusCount[sentStatus]++
-
-		// Rastrear último correo enviado (sent)
-		if sentStatus == "sent" && sentDate != nil {
-			if lastSentTime == nil || sentDate.After(*lastSentTime) {
-				lastSentTime = sentDate
-			}
-		}
 
-		// Rastrear último registro creado
-		if sendRequestDate != nil {
-			if lastCreatedTime == nil || sendRequestDate.After(*lastCreatedTime) {
-				lastCreatedTime = sendRequestDate
-			}
-		}
+	// Conteos por estado en cada ventana (agregados en SQL)
+	windowStats, err := queryMailWindows(db, dateColumn, windows)
+	if err != nil {
+		check.Status = "error"
+		check.Message = "Error al consultar sysmail_mailitems: " + err.Error()
+		return check
 	}
 
-	// Si no hay correos del día, reportar estado especial
-	if totalToday == 0 {
-		check.Status = "warning"
-		check.Message = "No hay correos registrados hoy"
-		check.Metadata["today_total"] = 0
+	// Desglose por perfil, cuenta y tipo de correo en la ventana de evaluación
+	breakdown := newMailBreakdown(config.Categories)
+	if err := breakdown.load(db, dateColumn, evaluationWindow); err != nil {
+		check.Status = "error"
+		check.Message = "Error al consultar desglose de correos: " + err.Error()
 		return check
 	}
 
-	// Calcular métricas
-	sentCount := statusCount["sent"]
-	unsentCount := statusCount["unsent"]
-	failedCount := statusCount["failed"]
-	retryingCount := statusCount["retrying"]
-
-	failedPercent := float64(0)
-	if totalToday > 0 {
-		failedPercent = (float64(failedCount) / float64(totalToday)) * 100
+	stats := windowStats[evaluationWindow]
+	label := mailWindowLabel(evaluationWindow)
+
+	// Metadata por ventana (mapas de escalares para conservarse en el historial)
+	windowList := make([]map[string]interface{}, 0, len(windows))
+	failedByWindow := make(map[string]interface{})
+	totalByWindow := make(map[string]interface{})
+	for _, minutes := range windows {
+		entry := windowStats[minutes].metadata()
+		entry["window"] = mailWindowLabel(minutes)
+		entry["minutes"] = minutes
+		windowList = append(windowList, entry)
+		failedByWindow[mailWindowLabel(minutes)] = roundMetric(windowStats[minutes].failedPercent())
+		totalByWindow[mailWindowLabel(minutes)] = windowStats[minutes].total
 	}
+	check.Metadata["windows"] = windowList
+	check.Metadata["failed_percentage_by_window"] = failedByWindow
+	check.Metadata["total_by_window"] = totalByWindow
+	check.Metadata["evaluation_window"] = label
 
 	// Tiempos desde última acción
-	var minutesSinceLastSent, minutesSinceLastCreated int64
-	if lastSentTime != nil {
-		minutesSinceLastSent = int64(time.Since(*lastSentTime).Minutes())
-		check.Metadata["last_sent_time"] = lastSentTime.Format(time.RFC3339)
+	var minutesSinceLastSent int64
+	if queue.lastSent != nil {
+		minutesSinceLastSent = int64(queue.now.Sub(*queue.lastSent).Minutes())
+		check.Metadata["last_sent_time"] = queue.lastSent.Format(time.RFC3339)
 		check.Metadata["minutes_since_last_sent"] = minutesSinceLastSent
 	}
-	if lastCreatedTime != nil {
-		minutesSinceLastCreated = int64(time.Since(*lastCreatedTime).Minutes())
-		check.Metadata["last_created_time"] = lastCreatedTime.Format(time.RFC3339)
-		check.Metadata["minutes_since_last_created"] = minutesSinceLastCreated
+	if queue.lastCreated != nil {
+		check.Metadata["last_created_time"] = queue.lastCreated.Format(time.RFC3339)
+		check.Metadata["minutes_since_last_created"] = int64(queue.now.Sub(*queue.lastCreated).Minutes())
 	}
 
-	// Metadata completa
-	check.Metadata["today_total"] = totalToday
-	check.Metadata["today_sent"] = sentCount
-	check.Metadata["today_unsent"] = unsentCount
-	check.Metadata["today_failed"] = failedCount
-	check.Metadata["today_retrying"] = retryingCount
-	check.Metadata["today_failed_percentage"] = fmt.Sprintf("%.2f", failedPercent)
-	check.Metadata["status_counts"] = statusCount
+	// Cola atascada: antigüedad del correo unsent/retrying más viejo
+	var oldestPendingMinutes int64
+	if queue.oldestPending != nil {
+		oldestPendingMinutes = int64(queue.now.Sub(*queue.oldestPending).Minutes())
+		check.Metadata["oldest_pending_time"] = queue.oldestPending.Format(time.RFC3339)
+	}
+	check.Metadata["oldest_pending_minutes"] = oldestPendingMinutes
+	check.Metadata["pending_count"] = queue.pending
+	stuckStatus := MetricThreshold{Warning: float64(config.StuckWarningMinutes), Error: float64(config.StuckErrorMinutes)}.
+		evaluate(float64(oldestPendingMinutes))
+
+	// Si no hay correos en la ventana, reportar estado especial
+	// La cola atascada se evalúa igual: correos pendientes de antes de la ventana siguen sin enviarse
+	if stats.total == 0 {
+		check.Metadata["total"] = 0
+		if stuckStatus != "ok" {
+			check.Status = stuckStatus
+			check.Message = fmt.Sprintf("No hay correos registrados en las últimas %s y hay %d pendientes sin enviar, el más antiguo hace %d minutos",
+				label, queue.pending, oldestPendingMinutes)
+			return check
+		}
+		check.Status = "warning"
+		check.Message = fmt.Sprintf("No hay correos registrados en las últimas %s", label)
+		return check
+	}
+
+	// Calcular métricas
+	failedPercent := stats.failedPercent()
+
+	// Metadata completa de la ventana de evaluación
+	check.Metadata["total"] = stats.total
+	check.Metadata["sent"] = stats.sent
+	check.Metadata["unsent"] = stats.unsent
+	check.Metadata["failed"] = stats.failed
+	check.Metadata["retrying"] = stats.retrying
+	check.Metadata["failed_percentage"] = fmt.Sprintf("%.2f", failedPercent)
+
+	// % de fallidos en cada ventana: las cortas detectan una falla reciente que en 24h queda diluida
+	// Se reporta la ventana con peor estado (ante empate, la más corta)
+	failureStatus, failureWindow := "ok", evaluationWindow
+	for _, minutes := range windows {
+		windowStatus := mailFailureStatus(windowStats[minutes], config.DailyWarningFailedPercent, config.DailyErrorFailedPercent, config.MinFailureSamples)
+		if windowStatus == "error" && failureStatus != "error" || windowStatus == "warning" && failureStatus == "ok" {
+			failureStatus, failureWindow = windowStatus, minutes
+		}
+	}
+	failing := windowStats[failureWindow]
+	failingLabel := mailWindowLabel(failureWindow)
+	check.Metadata["failure_window"] = failingLabel
 
 	// Determinar estado según umbrales
-	// Prioridad: error (% crítico) > warning (% alto) > warning (sin envíos recientes) > ok
-	if failedPercent >= float64(config.DailyErrorFailedPercent) {
+	// Prioridad: error (% crítico o cola atascada) > warning (% alto) > warning (cola lenta) > warning (sin envíos recientes) > ok
+	if failureStatus == "error" {
+		check.Status = "error"
+		check.Message = fmt.Sprintf("%.1f%% de correos fallidos en las últimas %s (%d de %d). Revisar configuración SMTP",
+			failing.failedPercent(), failingLabel, failing.failed, failing.total)
+	} else if stuckStatus == "error" {
 		check.Status = "error"
-		check.Message = fmt.Sprintf("%.1f%% de correos fallidos hoy (%d de %d). Revisar configuración SMTP",
-			failedPercent, failedCount, totalToday)
-	} else if failedPercent >= float64(config.DailyWarningFailedPercent) {
+		check.Message = fmt.Sprintf("Cola de correos atascada: %d pendientes, el más antiguo hace %d minutos",
+			queue.pending, oldestPendingMinutes)
+	} else if failureStatus == "warning" {
 		check.Status = "warning"
-		check.Message = fmt.Sprintf("%.1f%% de correos fallidos hoy (%d de %d). Último envío hace %d min",
-			failedPercent, failedCount, totalToday, minutesSinceLastSent)
-	} else if lastSentTime == nil || minutesSinceLastSent > int64(config.MaxMinutesWithoutSent) {
+		check.Message = fmt.Sprintf("%.1f%% de correos fallidos en las últimas %s (%d de %d). Último envío hace %d min",
+			failing.failedPercent(), failingLabel, failing.failed, failing.total, minutesSinceLastSent)
+	} else if stuckStatus == "warning" {
 		check.Status = "warning"
-		if lastSentTime == nil {
-			check.Message = fmt.Sprintf("No hay correos enviados hoy (%d pendientes, %d fallidos)",
-				unsentCount, failedCount)
+		check.Message = fmt.Sprintf("Correos pendientes sin enviar: %d, el más antiguo hace %d minutos",
+			queue.pending, oldestPendingMinutes)
+	} else if queue.lastSent == nil || minutesSinceLastSent > int64(config.MaxMinutesWithoutSent) {
+		check.Status = "warning"
+		if queue.lastSent == nil {
+			check.Message = fmt.Sprintf("No hay correos enviados en las últimas %s (%d pendientes, %d fallidos)",
+				label, stats.unsent, stats.failed)
 		} else {
-			check.Message = fmt.Sprintf("Sin correos enviados hace %d minutos (%d de %d enviados hoy)",
-				minutesSinceLastSent, sentCount, totalToday)
+			check.Message = fmt.Sprintf("Sin correos enviados hace %d minutos (%d de %d enviados en las últimas %s)",
+				minutesSinceLastSent, stats.sent, stats.total, label)
 		}
 	} else {
 		check.Status = "ok"
-		check.Message = fmt.Sprintf("Servicio funcionando. %d de %d correos enviados hoy (%.1f%% fallidos, último envío hace %d min)",
-			sentCount, totalToday, failedPercent, minutesSinceLastSent)
+		check.Message = fmt.Sprintf("Servicio funcionando. %d de %d correos enviados en las últimas %s (%.1f%% fallidos, último envío hace %d min)",
+			stats.sent, stats.total, label, failedPercent, minutesSinceLastSent)
+	}
+
+	byProfile, byAccount, byCategory := breakdown.metadata()
+	check.Metadata["by_profile"] = byProfile
+	check.Metadata["by_account"] = byAccount
+	if len(byCategory) > 0 {
+		check.Metadata["by_category"] = byCategory
+	}
+
+	// Perfiles o tipos de correo con fallas aunque el total esté dentro de umbrales
+	groupStatus, groupProblems := breakdown.evaluate(config, queue.now)
+	if len(groupProblems) > 0 {
+		check.Metadata["failing_groups"] = groupProblems
+		if groupStatus == "error" || check.Status == "ok" {
+			check.Status = groupStatus
+		}
+		check.Message = fmt.Sprintf("%s. Con fallas: %s", check.Message, strings.Join(groupProblems, "; "))
+	}
+
+	// Detalle de errores de Database Mail (sysmail_event_log + sysmail_faileditems)
+	if stats.failed > 0 || stats.retrying > 0 {
+		recentErrors, failedItems, err := loadMailErrorDetails(db, evaluationWindow)
+		if err != nil {
+			check.Metadata["error_details_unavailable"] = err.Error()
+		}
+		if len(recentErrors) > 0 {
+			check.Metadata["recent_errors"] = recentErrors
+			if check.Status != "ok" {
+				check.Message = fmt.Sprintf("%s. Último error: %s", check.Message,
+					truncateMessage(recentErrors[0]["description"].(string)))
+			}
+		}
+		if failedItems != nil {
+			check.Metadata["failed_items"] = failedItems
+		}
 	}
 
 	return check
@@ -0,0 +1,238 @@
+package monitors
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/saltacompra/monitor/internal/models"
+)
+
+// MailRecipientGuardConfig contiene la configuración para el check de destinatarios permitidos
+// Pensado para preproducción, donde no deben llegar correos a proveedores reales
+type MailRecipientGuardConfig struct {
+	Connection       SQLServerConnection
+	CheckID          string
+	CheckName        string
+	AllowedDomains   []string // Dominios permitidos (incluye subdominios)
+	AllowedAddresses []string // Direcciones permitidas
+	LookbackHours    int      // Horas hacia atrás en que se revisan los correos (default 24)
+	TimeoutSeconds   int
+}
+
+// maxReportedRecipients cantidad máxima de destinatarios no permitidos incluidos en metadata
+const maxReportedRecipients = 20
+
+// mailRecipientsQuery destinatarios de los correos recientes, agrupados en SQL
+// Se incluyen copias y copias ocultas porque también reciben el correo
+const mailRecipientsQuery = `
+	SELECT
+		COALESCE(recipients, ''),
+		COALESCE(copy_recipients, ''),
+		COALESCE(blind_copy_recipients, ''),
+		sent_status,
+		COUNT(*),
+		MAX(send_request_date)
+	FROM msdb.dbo.sysmail_allitems
+	WHERE send_request_date >= DATEADD(hour, -@lookback, GETDATE())
+	GROUP BY recipients, copy_recipients, blind_copy_recipients, sent_status
+`
+
+// mailOffender destinatario fuera de la lista permitida
+type mailOffender struct {
+	address     string
+	mails       int
+	sent        int
+	lastRequest time.Time
+}
+
+// mailRecipientAllowlist dominios y direcciones permitidos
+type mailRecipientAllowlist struct {
+	domains   []string
+	addresses map[string]bool
+}
+
+// newMailRecipientAllowlist normaliza los dominios y direcciones permitidos
+func newMailRecipientAllowlist(domains []string, addresses []string) mailRecipientAllowlist {
+	allowlist := mailRecipientAllowlist{addresses: make(map[string]bool)}
+	for _, domain := range domains {
+		domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "@")
+		if domain != "" {
+			allowlist.domains = append(allowlist.domains, domain)
+		}
+	}
+	for _, address := range addresses {
+		if address = strings.ToLower(strings.TrimSpace(address)); address != "" {
+			allowlist.addresses[address] = true
+		}
+	}
+	return allowlist
+}
+
+// allows indica si una dirección está permitida (dirección exacta, dominio o subdominio)
+func (a mailRecipientAllowlist) allows(address string) bool {
+	if a.addresses[address] {
+		return true
+	}
+	domain := address[strings.LastIndex(address, "@")+1:]
+	for _, allowed := range a.domains {
+		if domain == allowed || strings.HasSuffix(domain, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckMailRecipientGuard verifica que los correos recientes de Database Mail
+// solo tengan destinatarios dentro de la lista permitida
+func CheckMailRecipientGuard(config MailRecipientGuardConfig) models.Check {
+	check := models.Check{
+		ID:        config.CheckID,
+		Type:      "mssql-mail-guard",
+		Name:      config.CheckName,
+		LastCheck: time.Now(),
+		Metadata:  make(map[string]interface{}),
+	}
+
+	lookback := config.LookbackHours
+	if lookback <= 0 {
+		lookback = 24
+	}
+	allowlist := newMailRecipientAllowlist(config.AllowedDomains, config.AllowedAddresses)
+	check.Metadata["lookback_hours"] = lookback
+	check.Metadata["allowed_domains"] = allowlist.domains
+	check.Metadata["allowed_addresses"] = len(allowlist.addresses)
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(config.TimeoutSeconds))
+	defer cancel()
+
+	start := time.Now()
+	db, err := openSQLServer(ctx, config.Connection)
+	if err != nil {
+		check.ResponseTime = time.Since(start).Milliseconds()
+		check.Status = "error"
+		check.Message = err.Error()
+		check.Metadata["error_type"] = "connection_failed"
+		return check
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, mailRecipientsQuery, sql.Named("lookback", lookback))
+	if err != nil {
+		check.ResponseTime = time.Since(start).Milliseconds()
+		check.Status = "error"
+		check.Message = "Error al consultar sysmail_allitems: " + err.Error()
+		check.Metadata["error_type"] = "query_failed"
+		return check
+	}
+	defer rows.Close()
+
+	offenders := make(map[string]*mailOffender)
+	offendingDomains := make(map[string]interface{})
+	mailsChecked, mailsBlocked, mailsBlockedSent := 0, 0, 0
+
+	for rows.Next() {
+		var recipients, copyRecipients, blindCopyRecipients, status string
+		var count int
+		var lastRequest time.Time
+		if err := rows.Scan(&recipients, &copyRecipients, &blindCopyRecipients, &status, &count, &lastRequest); err != nil {
+			check.Status = "error"
+			check.Message = "Error al leer destinatarios: " + err.Error()
+			return check
+		}
+		mailsChecked += count
+		sent := status == "sent"
+
+		// Una misma dirección puede repetirse en para, copia y copia oculta
+		seen := make(map[string]bool)
+		all := strings.Join([]string{recipients, copyRecipients, blindCopyRecipients}, ";")
+		for _, address := range emailPattern.FindAllString(all, -1) {
+			address = strings.ToLower(address)
+			if seen[address] || allowlist.allows(address) {
+				continue
+			}
+			seen[address] = true
+
+			offender, exists := offenders[address]
+			if !exists {
+				offender = &mailOffender{address: address}
+				offenders[address] = offender
+			}
+			offender.mails += count
+			if sent {
+				offender.sent += count
+			}
+			if lastRequest.After(offender.lastRequest) {
+				offender.lastRequest = lastRequest
+			}
+		}
+		if len(seen) > 0 {
+			mailsBlocked += count
+			if sent {
+				mailsBlockedSent += count
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		check.Status = "error"
+		check.Message = "Error al leer destinatarios: " + err.Error()
+		return check
+	}
+	check.ResponseTime = time.Since(start).Milliseconds()
+
+	// Destinatarios con más correos primero
+	sorted := make([]*mailOffender, 0, len(offenders))
+	for _, offender := range offenders {
+		sorted = append(sorted, offender)
+		domain := offender.address[strings.LastIndex(offender.address, "@")+1:]
+		count, _ := offendingDomains[domain].(int)
+		offendingDomains[domain] = count + offender.mails
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].mails != sorted[j].mails {
+			return sorted[i].mails > sorted[j].mails
+		}
+		return sorted[i].address < sorted[j].address
+	})
+
+	check.Metadata["mails_checked"] = mailsChecked
+	check.Metadata["mails_outside_allowlist"] = mailsBlocked
+	check.Metadata["mails_outside_allowlist_sent"] = mailsBlockedSent
+	check.Metadata["offending_recipients_count"] = len(sorted)
+
+	if len(sorted) == 0 {
+		check.Status = "ok"
+		check.Message = fmt.Sprintf("Sin destinatarios fuera de la lista permitida en %d correos de las últimas %dh",
+			mailsChecked, lookback)
+		return check
+	}
+
+	reported := sorted
+	if len(reported) > maxReportedRecipients {
+		reported = reported[:maxReportedRecipients]
+	}
+	offendingRecipients := make([]map[string]interface{}, 0, len(reported))
+	var summary []string
+	for i, offender := range reported {
+		masked := maskEmail(offender.address)
+		offendingRecipients = append(offendingRecipients, map[string]interface{}{
+			"recipient":    masked,
+			"mails":        offender.mails,
+			"sent":         offender.sent,
+			"last_request": offender.lastRequest.Format(time.RFC3339),
+		})
+		if i < 5 {
+			summary = append(summary, fmt.Sprintf("%s (%d)", masked, offender.mails))
+		}
+	}
+	check.Metadata["offending_recipients"] = offendingRecipients
+	check.Metadata["offending_domains"] = offendingDomains
+
+	check.Status = "error"
+	check.Message = fmt.Sprintf("%d correos a %d destinatarios fuera de la lista permitida en las últimas %dh (%d enviados): %s",
+		mailsBlocked, len(sorted), lookback, mailsBlockedSent, strings.Join(summary, ", "))
+	return check
+}
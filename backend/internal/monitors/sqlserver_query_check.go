@@ -0,0 +1,86 @@
+package monitors
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/saltacompra/monitor/internal/models"
+)
+
+// SQLServerQueryCheckConfig contiene la configuración para un check de consulta SQL Server
+type SQLServerQueryCheckConfig struct {
+	Connection     SQLServerConnection
+	CheckID        string
+	CheckName      string
+	Query          string // Consulta de solo lectura (SELECT/WITH)
+	TimeoutSeconds int    // Timeout de la consulta
+	MaxRows        int    // Máximo de filas incluidas en metadata
+	Thresholds     QueryThresholds
+}
+
+// CheckSQLServerQuery ejecuta una consulta configurada de solo lectura y evalúa su resultado
+// La consulta corre dentro de una transacción que siempre se descarta (rollback)
+func CheckSQLServerQuery(config SQLServerQueryCheckConfig) models.Check {
+	check := models.Check{
+		ID:        config.CheckID,
+		Type:      "mssql-query",
+		Name:      config.CheckName,
+		LastCheck: time.Now(),
+		Metadata:  make(map[string]interface{}),
+	}
+
+	if err := validateReadOnlyQuery(config.Query); err != nil {
+		check.Status = "error"
+		check.Message = "Consulta rechazada: " + err.Error()
+		check.Metadata["error_type"] = "invalid_query"
+		return check
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(config.TimeoutSeconds))
+	defer cancel()
+
+	start := time.Now()
+	db, err := openSQLServer(ctx, config.Connection)
+	if err != nil {
+		check.ResponseTime = time.Since(start).Milliseconds()
+		check.Status = "error"
+		check.Message = err.Error()
+		check.Metadata["error_type"] = "connection_failed"
+		return check
+	}
+	defer db.Close()
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		check.ResponseTime = time.Since(start).Milliseconds()
+		check.Status = "error"
+		check.Message = "Error al iniciar transacción: " + err.Error()
+		return check
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, config.Query)
+	if err != nil {
+		check.ResponseTime = time.Since(start).Milliseconds()
+		check.Status = "error"
+		check.Message = "Error al ejecutar consulta: " + err.Error()
+		check.Metadata["error_type"] = "query_failed"
+		return check
+	}
+	defer rows.Close()
+
+	result, err := scanSQLRows(rows)
+	check.ResponseTime = time.Since(start).Milliseconds()
+	if err != nil {
+		check.Status = "error"
+		check.Message = "Error al leer resultado: " + err.Error()
+		check.Metadata["error_type"] = "query_failed"
+		return check
+	}
+
+	check.Metadata["database"] = config.Connection.Database
+	applyQueryResult(&check, result, config.Thresholds, config.MaxRows)
+
+	return check
+}
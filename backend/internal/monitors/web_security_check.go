@@ -0,0 +1,358 @@
+package monitors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/saltacompra/monitor/internal/models"
+)
+
+// WebSecurityCheckConfig contiene la configuración para la verificación de cabeceras de seguridad
+type WebSecurityCheckConfig struct {
+	URL                 string
+	CheckID             string
+	CheckName           string
+	SkipSSLVerification bool
+	TimeoutSeconds      int
+	WarningScore        int // Puntaje (0-100) por debajo del cual se reporta warning
+	ErrorScore          int // Puntaje (0-100) por debajo del cual se reporta error
+}
+
+// securityItem resultado de un ítem auditado
+type securityItem struct {
+	ID     string
+	Name   string
+	Weight int
+	Result string // "pass", "warning", "fail"
+	Detail string
+}
+
+// hstsMinMaxAge mínimo recomendado para max-age de HSTS (180 días)
+const hstsMinMaxAge = 15552000
+
+// versionPattern detecta números de versión en cabeceras (ej: "Microsoft-IIS/10.0")
+var versionPattern = regexp.MustCompile(`\d+(\.\d+)+`)
+
+// CheckWebSecurity audita cabeceras de seguridad, cookies y redirección HTTPS de un sitio
+func CheckWebSecurity(config WebSecurityCheckConfig) models.Check {
+	check := models.Check{
+		ID:        config.CheckID,
+		Type:      "web-security",
+		Name:      config.CheckName,
+		LastCheck: time.Now(),
+		Metadata:  make(map[string]interface{}),
+	}
+
+	timeout := config.TimeoutSeconds
+	if timeout == 0 {
+		timeout = 30 // Default 30 segundos
+	}
+	client := getHTTPClient(timeout, config.SkipSSLVerification)
+
+	// Las cookies emitidas en los saltos de redirección (ej: login → home) también se auditan
+	var cookies []*http.Cookie
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if req.Response != nil {
+			cookies = append(cookies, req.Response.Cookies()...)
+		}
+		if len(via) >= 10 {
+			return errors.New("se detuvo después de 10 redirecciones")
+		}
+		return nil
+	}
+
+	start := time.Now()
+	resp, err := client.Get(config.URL)
+	elapsed := time.Since(start).Milliseconds()
+	check.ResponseTime = elapsed
+
+	if err != nil {
+		check.Status = "error"
+		check.Message = "No se pudo conectar: " + err.Error()
+		return check
+	}
+	resp.Body.Close()
+	cookies = append(cookies, resp.Cookies()...)
+
+	items := []securityItem{
+		auditHSTS(resp),
+		auditCSP(resp.Header),
+		auditFrameOptions(resp.Header),
+		auditContentTypeOptions(resp.Header),
+		auditReferrerPolicy(resp.Header),
+		auditCookies(cookies),
+		auditHTTPSRedirect(config.URL, timeout, config.SkipSSLVerification),
+		auditServerDisclosure(resp.Header),
+	}
+
+	// Calcular puntaje ponderado: pass suma el peso completo, warning la mitad
+	totalWeight, earned := 0, 0
+	var failed, warned []string
+	itemResults := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		totalWeight += item.Weight
+		switch item.Result {
+		case "pass":
+			earned += item.Weight * 2
+		case "warning":
+			earned += item.Weight
+			warned = append(warned, item.Name)
+		default:
+			failed = append(failed, item.Name)
+		}
+		itemResults = append(itemResults, map[string]interface{}{
+			"id":     item.ID,
+			"name":   item.Name,
+			"result": item.Result,
+			"detail": item.Detail,
+		})
+	}
+	score := earned * 100 / (totalWeight * 2)
+
+	check.Metadata["score"] = score
+	check.Metadata["items"] = itemResults
+	check.Metadata["failed_items"] = failed
+	check.Metadata["warning_items"] = warned
+
+	// Determinar estado según umbrales de puntaje
+	if score < config.ErrorScore {
+		check.Status = "error"
+	} else if score < config.WarningScore || len(failed) > 0 {
+		check.Status = "warning"
+	} else {
+		check.Status = "ok"
+	}
+
+	if len(failed) == 0 && len(warned) == 0 {
+		check.Message = fmt.Sprintf("Puntaje de seguridad %d/100. Todos los controles aprobados", score)
+	} else if len(failed) > 0 {
+		check.Message = fmt.Sprintf("Puntaje de seguridad %d/100. Controles fallidos: %s", score, strings.Join(failed, ", "))
+	} else {
+		check.Message = fmt.Sprintf("Puntaje de seguridad %d/100. Controles a mejorar: %s", score, strings.Join(warned, ", "))
+	}
+
+	return check
+}
+
+// auditHSTS verifica Strict-Transport-Security (solo aplica sobre HTTPS)
+func auditHSTS(resp *http.Response) securityItem {
+	item := securityItem{ID: "hsts", Name: "HSTS", Weight: 3}
+	value := resp.Header.Get("Strict-Transport-Security")
+
+	switch {
+	case resp.TLS == nil:
+		item.Result, item.Detail = "fail", "El sitio no se sirve sobre HTTPS"
+	case value == "":
+		item.Result, item.Detail = "fail", "Cabecera Strict-Transport-Security ausente"
+	default:
+		maxAge := parseDirectiveInt(value, "max-age")
+		if maxAge < hstsMinMaxAge {
+			item.Result = "warning"
+			item.Detail = fmt.Sprintf("max-age=%d menor al recomendado (%d)", maxAge, hstsMinMaxAge)
+		} else {
+			item.Result, item.Detail = "pass", value
+		}
+	}
+	return item
+}
+
+// auditCSP verifica Content-Security-Policy
+func auditCSP(header http.Header) securityItem {
+	item := securityItem{ID: "csp", Name: "Content-Security-Policy", Weight: 2}
+	value := header.Get("Content-Security-Policy")
+
+	switch {
+	case value == "":
+		item.Result, item.Detail = "fail", "Cabecera Content-Security-Policy ausente"
+	case strings.Contains(value, "'unsafe-inline'") || strings.Contains(value, "'unsafe-eval'"):
+		item.Result, item.Detail = "warning", "La política permite 'unsafe-inline' o 'unsafe-eval'"
+	default:
+		item.Result, item.Detail = "pass", value
+	}
+	return item
+}
+
+// auditFrameOptions verifica protección contra clickjacking (X-Frame-Options o frame-ancestors)
+func auditFrameOptions(header http.Header) securityItem {
+	item := securityItem{ID: "x_frame_options", Name: "X-Frame-Options", Weight: 2}
+	value := strings.ToUpper(header.Get("X-Frame-Options"))
+
+	switch {
+	case value == "DENY" || value == "SAMEORIGIN":
+		item.Result, item.Detail = "pass", value
+	case strings.Contains(header.Get("Content-Security-Policy"), "frame-ancestors"):
+		item.Result, item.Detail = "pass", "Protegido por CSP frame-ancestors"
+	case value != "":
+		item.Result, item.Detail = "warning", "Valor no reconocido: "+value
+	default:
+		item.Result, item.Detail = "fail", "Cabecera X-Frame-Options ausente"
+	}
+	return item
+}
+
+// auditContentTypeOptions verifica X-Content-Type-Options: nosniff
+func auditContentTypeOptions(header http.Header) securityItem {
+	item := securityItem{ID: "x_content_type_options", Name: "X-Content-Type-Options", Weight: 1}
+	if strings.EqualFold(header.Get("X-Content-Type-Options"), "nosniff") {
+		item.Result, item.Detail = "pass", "nosniff"
+	} else {
+		item.Result, item.Detail = "fail", "Cabecera X-Content-Type-Options: nosniff ausente"
+	}
+	return item
+}
+
+// auditReferrerPolicy verifica Referrer-Policy
+func auditReferrerPolicy(header http.Header) securityItem {
+	item := securityItem{ID: "referrer_policy", Name: "Referrer-Policy", Weight: 1}
+	value := strings.ToLower(header.Get("Referrer-Policy"))
+
+	switch value {
+	case "":
+		item.Result, item.Detail = "fail", "Cabecera Referrer-Policy ausente"
+	case "unsafe-url", "no-referrer-when-downgrade":
+		item.Result, item.Detail = "warning", "Política permisiva: "+value
+	default:
+		item.Result, item.Detail = "pass", value
+	}
+	return item
+}
+
+// auditCookies verifica flags Secure, HttpOnly y SameSite de las cookies emitidas
+func auditCookies(cookies []*http.Cookie) securityItem {
+	item := securityItem{ID: "cookies", Name: "Flags de cookies", Weight: 2}
+	if len(cookies) == 0 {
+		item.Result, item.Detail = "pass", "El sitio no emite cookies en la página inicial ni en sus redirecciones"
+		return item
+	}
+
+	var insecure, noHTTPOnly, noSameSite []string
+	for _, cookie := range cookies {
+		if !cookie.Secure {
+			insecure = append(insecure, cookie.Name)
+		}
+		if !cookie.HttpOnly {
+			noHTTPOnly = append(noHTTPOnly, cookie.Name)
+		}
+		if cookie.SameSite == http.SameSiteDefaultMode {
+			noSameSite = append(noSameSite, cookie.Name)
+		}
+	}
+
+	var problems []string
+	if len(insecure) > 0 {
+		problems = append(problems, "sin Secure: "+strings.Join(insecure, ", "))
+	}
+	if len(noHTTPOnly) > 0 {
+		problems = append(problems, "sin HttpOnly: "+strings.Join(noHTTPOnly, ", "))
+	}
+	if len(noSameSite) > 0 {
+		problems = append(problems, "sin SameSite: "+strings.Join(noSameSite, ", "))
+	}
+
+	switch {
+	case len(insecure) > 0:
+		item.Result = "fail"
+	case len(problems) > 0:
+		item.Result = "warning"
+	default:
+		item.Result = "pass"
+	}
+	if len(problems) > 0 {
+		item.Detail = strings.Join(problems, "; ")
+	} else {
+		item.Detail = fmt.Sprintf("%d cookies con Secure, HttpOnly y SameSite", len(cookies))
+	}
+	return item
+}
+
+// auditHTTPSRedirect verifica que la versión http:// redirija a https://
+func auditHTTPSRedirect(rawURL string, timeout int, skipSSLVerify bool) securityItem {
+	item := securityItem{ID: "https_redirect", Name: "Redirección HTTP→HTTPS", Weight: 2}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		item.Result, item.Detail = "fail", "URL inválida: "+err.Error()
+		return item
+	}
+	parsed.Scheme = "http"
+
+	// No seguir redirecciones: interesa la primera respuesta
+	client := getHTTPClient(timeout, skipSSLVerify)
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	resp, err := client.Get(parsed.String())
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		// Puerto 80 cerrado: no hay riesgo de servir contenido en texto plano
+		item.Result, item.Detail = "pass", "HTTP no disponible (puerto 80 cerrado)"
+		return item
+	}
+	if err != nil {
+		// Timeout, DNS o TLS: no se pudo verificar, no equivale a tener el puerto cerrado
+		item.Result, item.Detail = "warning", "No se pudo verificar la versión HTTP: "+err.Error()
+		return item
+	}
+	resp.Body.Close()
+
+	location := resp.Header.Get("Location")
+	switch {
+	case resp.StatusCode >= 300 && resp.StatusCode < 400 && strings.HasPrefix(strings.ToLower(location), "https://"):
+		item.Result = "pass"
+		item.Detail = fmt.Sprintf("HTTP %d → %s", resp.StatusCode, location)
+	case resp.StatusCode >= 300 && resp.StatusCode < 400:
+		item.Result = "warning"
+		item.Detail = fmt.Sprintf("Redirige a %s (no HTTPS)", location)
+	default:
+		item.Result = "fail"
+		item.Detail = fmt.Sprintf("HTTP responde %d sin redirigir a HTTPS", resp.StatusCode)
+	}
+	return item
+}
+
+// auditServerDisclosure verifica que no se expongan versiones del servidor o framework
+func auditServerDisclosure(header http.Header) securityItem {
+	item := securityItem{ID: "server_disclosure", Name: "Divulgación de versión", Weight: 1}
+
+	var disclosed []string
+	for _, name := range []string{"Server", "X-Powered-By", "X-AspNet-Version", "X-AspNetMvc-Version"} {
+		value := header.Get(name)
+		if value == "" {
+			continue
+		}
+		if name != "Server" || versionPattern.MatchString(value) {
+			disclosed = append(disclosed, fmt.Sprintf("%s: %s", name, value))
+		}
+	}
+
+	switch {
+	case len(disclosed) > 1:
+		item.Result, item.Detail = "fail", strings.Join(disclosed, "; ")
+	case len(disclosed) == 1:
+		item.Result, item.Detail = "warning", disclosed[0]
+	default:
+		item.Result, item.Detail = "pass", "No se exponen versiones"
+	}
+	return item
+}
+
+// parseDirectiveInt obtiene el valor numérico de una directiva tipo "max-age=31536000"
+func parseDirectiveInt(value string, directive string) int {
+	for _, part := range strings.Split(value, ";") {
+		part = strings.TrimSpace(part)
+		if strings.HasPrefix(strings.ToLower(part), directive+"=") {
+			n, err := strconv.Atoi(strings.Trim(part[len(directive)+1:], `"`))
+			if err == nil {
+				return n
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,239 @@
+package monitors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// WHOISConfig configuración del fallback WHOIS (puerto 43) cuando RDAP no responde
+type WHOISConfig struct {
+	Enabled bool
+	Server  string // Servidor WHOIS a usar para todos los dominios (vacío = según TLD o referencia de IANA)
+}
+
+// maxWHOISResponseBytes tamaño máximo leído de una respuesta WHOIS
+const maxWHOISResponseBytes = 1 << 20
+
+// whoisServers servidores WHOIS conocidos por TLD (el resto se resuelve con whois.iana.org)
+var whoisServers = map[string]string{
+	"com": "whois.verisign-grs.com",
+	"net": "whois.verisign-grs.com",
+	"org": "whois.pir.org",
+	"ar":  "whois.nic.ar",
+	"br":  "whois.registro.br",
+}
+
+// whoisNotFoundMarkers textos con los que los registros informan que el dominio no existe
+// Solo se buscan si la respuesta no trae fecha de expiración (los avisos legales pueden contenerlos)
+var whoisNotFoundMarkers = []string{
+	"no match",
+	"not found",
+	"no entries found",
+	"no data found",
+	"no object found",
+	"domain not found",
+	"status: free",
+	"status: available",
+	"el dominio no se encuentra registrado",
+}
+
+// whoisExpirationKeys campos con la fecha de expiración según el registro
+// (gTLDs ICANN, NIC Argentina, Registro.br, Nominet, RU-CENTER y otros ccTLDs)
+var whoisExpirationKeys = []string{
+	"registry expiry date",
+	"registrar registration expiration date",
+	"expiration date",
+	"expiry date",
+	"expiration time",
+	"expire date",
+	"expires on",
+	"expires",
+	"expire",
+	"paid-till",
+	"valid until",
+	"renewal date",
+}
+
+// whoisDateLayouts formatos de fecha usados por los registros más comunes
+var whoisDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"20060102",
+	"02-Jan-2006",
+	"2-Jan-2006",
+	"02-January-2006",
+	"2006.01.02",
+	"02.01.2006",
+	"2006/01/02",
+}
+
+// whoisRecord datos extraídos de una respuesta WHOIS
+type whoisRecord struct {
+	server      string
+	expiration  time.Time
+	registrar   string
+	nameservers []string
+	statuses    []string
+}
+
+// lookupWHOIS consulta el servidor WHOIS del dominio y extrae su fecha de expiración
+// Si el servidor informa que el dominio no existe retorna errDomainNotRegistered
+func lookupWHOIS(ctx context.Context, domain string, config WHOISConfig) (whoisRecord, error) {
+	server := config.Server
+	if server == "" {
+		var err error
+		server, err = whoisServerFor(ctx, domain)
+		if err != nil {
+			return whoisRecord{}, err
+		}
+	}
+
+	response, err := queryWHOIS(ctx, server, domain)
+	if err != nil {
+		return whoisRecord{server: server}, err
+	}
+	record := parseWHOISResponse(response)
+	record.server = server
+	if record.expiration.IsZero() {
+		if whoisReportsNotFound(response) {
+			return record, fmt.Errorf("%w (según %s)", errDomainNotRegistered, server)
+		}
+		return record, fmt.Errorf("no se encontró fecha de expiración en la respuesta de %s", server)
+	}
+	return record, nil
+}
+
+// whoisReportsNotFound indica si la respuesta WHOIS informa que el dominio no existe
+func whoisReportsNotFound(response string) bool {
+	lower := strings.ToLower(response)
+	for _, marker := range whoisNotFoundMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// whoisServerFor retorna el servidor WHOIS del TLD del dominio
+// Si no es un TLD conocido, lo obtiene del campo "whois:" de la respuesta de whois.iana.org
+func whoisServerFor(ctx context.Context, domain string) (string, error) {
+	labels := strings.Split(strings.ToLower(strings.TrimSuffix(domain, ".")), ".")
+	tld := labels[len(labels)-1]
+	if server, exists := whoisServers[tld]; exists {
+		return server, nil
+	}
+
+	response, err := queryWHOIS(ctx, "whois.iana.org", tld)
+	if err != nil {
+		return "", fmt.Errorf("no se pudo consultar whois.iana.org: %w", err)
+	}
+	for _, line := range strings.Split(response, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if found && strings.EqualFold(strings.TrimSpace(key), "whois") && strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value), nil
+		}
+	}
+	return "", fmt.Errorf("IANA no informa servidor WHOIS para .%s", tld)
+}
+
+// queryWHOIS envía una consulta al puerto 43 del servidor y retorna la respuesta completa
+// La conexión y la lectura quedan acotadas por el deadline del contexto
+func queryWHOIS(ctx context.Context, server string, query string) (string, error) {
+	address := server
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		address = net.JoinHostPort(server, "43")
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write([]byte(query + "\r\n")); err != nil {
+		return "", err
+	}
+	response, err := io.ReadAll(io.LimitReader(conn, maxWHOISResponseBytes))
+	if err != nil {
+		return "", err
+	}
+	return string(response), nil
+}
+
+// parseWHOISResponse extrae expiración, registrar, nameservers y estados de una respuesta WHOIS
+// Para cada campo se usa la primera aparición (las respuestas con referencias repiten los datos del registrar)
+func parseWHOISResponse(response string) whoisRecord {
+	var record whoisRecord
+	expirationRank := len(whoisExpirationKeys)
+	seen := make(map[string]bool)
+
+	for _, line := range strings.Split(response, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		// La clave más específica gana (ej: "registry expiry date" sobre "expire")
+		for rank, expirationKey := range whoisExpirationKeys[:expirationRank] {
+			if key == expirationKey {
+				if expiration, err := parseWHOISDate(value); err == nil {
+					record.expiration, expirationRank = expiration, rank
+				}
+				break
+			}
+		}
+
+		switch key {
+		case "registrar", "sponsoring registrar":
+			if record.registrar == "" {
+				record.registrar = value
+			}
+		case "name server", "nserver", "nameserver":
+			name := strings.ToLower(strings.TrimSuffix(strings.Fields(value)[0], "."))
+			if !seen["ns:"+name] {
+				seen["ns:"+name] = true
+				record.nameservers = append(record.nameservers, name)
+			}
+		case "domain status", "status":
+			// "clientTransferProhibited https://icann.org/epp#clientTransferProhibited"
+			status := strings.Fields(value)[0]
+			if !seen["status:"+status] {
+				seen["status:"+status] = true
+				record.statuses = append(record.statuses, status)
+			}
+		}
+	}
+	return record
+}
+
+// parseWHOISDate interpreta una fecha de expiración WHOIS, completa o solo su primer campo
+// (ej: "2025-03-15 00:00:00 (UTC+3)" o "20250315 #12345")
+func parseWHOISDate(value string) (time.Time, error) {
+	candidates := []string{value}
+	if fields := strings.Fields(value); len(fields) > 1 {
+		candidates = append(candidates, strings.Join(fields[:2], " "), fields[0])
+	}
+	for _, candidate := range candidates {
+		for _, layout := range whoisDateLayouts {
+			if parsed, err := time.Parse(layout, candidate); err == nil {
+				return parsed, nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("formato de fecha no reconocido: %s", value)
+}
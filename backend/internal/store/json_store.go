@@ -0,0 +1,91 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// JSONStore es un almacén clave-valor thread-safe persistido en un archivo JSON
+// Se usa para estado que debe sobrevivir reinicios (baselines, snapshots)
+type JSONStore struct {
+	mu   sync.Mutex
+	path string
+	data map[string]json.RawMessage
+}
+
+// NewJSONStore abre (o crea) el almacén en la ruta indicada
+func NewJSONStore(path string) (*JSONStore, error) {
+	s := &JSONStore{
+		path: path,
+		data: make(map[string]json.RawMessage),
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("no se pudo crear directorio de datos: %w", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer %s: %w", path, err)
+	}
+
+	if len(content) > 0 {
+		if err := json.Unmarshal(content, &s.data); err != nil {
+			return nil, fmt.Errorf("archivo de estado inválido %s: %w", path, err)
+		}
+	}
+
+	return s, nil
+}
+
+// Get obtiene el valor de una clave y lo deserializa en v
+// Retorna false si la clave no existe
+func (s *JSONStore) Get(key string, v interface{}) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	raw, exists := s.data[key]
+	if !exists {
+		return false, nil
+	}
+
+	if err := json.Unmarshal(raw, v); err != nil {
+		return false, fmt.Errorf("valor inválido para %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// Put guarda el valor de una clave y persiste el archivo completo
+func (s *JSONStore) Put(key string, v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("no se pudo serializar %s: %w", key, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data[key] = raw
+	return s.flush()
+}
+
+// flush escribe el archivo de forma atómica (archivo temporal + rename)
+// Debe llamarse con el lock tomado
+func (s *JSONStore) flush() error {
+	content, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, content, 0o600); err != nil {
+		return fmt.Errorf("no se pudo escribir %s: %w", tmpPath, err)
+	}
+	return os.Rename(tmpPath, s.path)
+}
@@ -104,8 +104,8 @@ export function getCheckBadgeSummary(check: {
   }
 
   // Mail check: mostrar enviados/total
-  if (lowerType.includes('mail') && check.metadata?.today_sent !== undefined) {
-    return `${check.metadata.today_sent}/${check.metadata.today_total}`;
+  if (lowerType.includes('mail') && check.metadata?.sent !== undefined) {
+    return `${check.metadata.sent}/${check.metadata.total}`;
   }
 
   // VPN check: mostrar "OK" o "Down"
@@ -113,9 +113,9 @@ export function getCheckBadgeSummary(check: {
     return check.status === 'online' ? 'OK' : 'Down';
   }
 
-  // PostgreSQL check: mostrar conteo de usuarios
-  if (lowerType.includes('postgresql') && check.metadata?.user_count !== undefined) {
-    return `${check.metadata.user_count} users`;
+  // PostgreSQL check: mostrar versión del servidor (el check solo verifica conectividad)
+  if (lowerType.includes('postgresql') && check.metadata?.server_version !== undefined) {
+    return `v${String(check.metadata.server_version).split(' ')[0]}`;
   }
 
   // Domain check: mostrar días hasta expiración